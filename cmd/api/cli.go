@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/bundle"
+	"kite/internal/configs"
+	"kite/internal/database"
+	"kite/internal/database/migrations"
+	"kite/internal/services"
+	"kite/internal/tenant"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// CLI 命令行子命令依赖的服务
+type CLI struct {
+	Connection       *database.Connection
+	ImportService    services.ImportService
+	BundleService    services.BundleService
+	WorkspaceService services.WorkspaceService
+}
+
+func NewCLI(connection *database.Connection, importService services.ImportService, bundleService services.BundleService, workspaceService services.WorkspaceService) *CLI {
+	return &CLI{connection, importService, bundleService, workspaceService}
+}
+
+// runCommand 执行命令行子命令，执行完毕后关闭数据库连接
+func runCommand(cfg *configs.DatabaseConfig, args []string) error {
+	cli, err := InitializeCLI(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cli.Connection.CloseDB()
+	}()
+
+	switch args[0] {
+	case "import-openapi":
+		return cli.importSpec(args[0], cli.ImportService.ImportOpenAPI, args[1:])
+	case "import-har":
+		return cli.importSpec(args[0], cli.ImportService.ImportHAR, args[1:])
+	case "import-postman":
+		return cli.importSpec(args[0], cli.ImportService.ImportPostman, args[1:])
+	case "export":
+		return cli.exportBundle(args[1:])
+	case "import":
+		return cli.importBundle(args[1:])
+	case "migrate":
+		return cli.migrate(args[1:])
+	case "workspace":
+		return cli.workspace(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// importSpec 用法：api <import-openapi|import-har|import-postman> -uid <uid> -file <path> [-dry-run]
+func (c *CLI) importSpec(name string, importer func(ctx echo.Context, uid string, data []byte, dryRun bool) (*services.ImportResult, error), args []string) error {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	uid := flags.String("uid", "", "namespace to create the mocks in")
+	file := flags.String("file", "", "path to the file to import")
+	dryRun := flags.Bool("dry-run", false, "preview the mocks without saving them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *uid == "" || *file == "" {
+		flags.Usage()
+		return errors.New("both -uid and -file are required")
+	}
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	result, err := importer(newCommandContext(), *uid, data, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, api := range result.Created {
+		fmt.Printf("%s\t%s %s -> %d\n", api.Uuid, api.Method, api.Path, api.StatusCode)
+	}
+	for _, skip := range result.Skipped {
+		fmt.Printf("skipped\t%s %s: %s\n", skip.Method, skip.Path, skip.Reason)
+	}
+	if *dryRun {
+		fmt.Printf("Would import %d mocks into %s, %d skipped\n", len(result.Created), *uid, len(result.Skipped))
+		return nil
+	}
+	fmt.Printf("Imported %d mocks into %s, %d skipped\n", len(result.Created), *uid, len(result.Skipped))
+	return nil
+}
+
+// exportBundle 用法：api export [-uid <uid>] [-tag <tag>] [-format json|yaml] [-out <file>]，未指定 -out 时输出到标准输出
+func (c *CLI) exportBundle(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	uid := flags.String("uid", "", "export the mocks of this namespace")
+	tag := flags.String("tag", "", "export the mocks with this tag")
+	format := flags.String("format", bundle.FormatJSON, "bundle format: json or yaml")
+	out := flags.String("out", "", "file to write the bundle to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *uid == "" && *tag == "" {
+		flags.Usage()
+		return errors.New("either -uid or -tag is required")
+	}
+	b, err := c.BundleService.Export(newCommandContext(), payloads.BundleExportQuery{UserId: *uid, Tag: *tag, Format: *format})
+	if err != nil {
+		return err
+	}
+	data, err := bundle.Encode(b, *format)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d mocks to %s\n", len(b.Mocks), *out)
+	return nil
+}
+
+// importBundle 用法：api import -file <bundle> [-uid <uid>] [-strategy skip|overwrite|rename] [-dry-run]
+func (c *CLI) importBundle(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "path to a JSON or YAML bundle")
+	uid := flags.String("uid", "", "import all mocks into this namespace instead of the ones recorded in the bundle")
+	strategy := flags.String("strategy", payloads.ConflictSkip, "how to handle conflicts: skip, overwrite or rename")
+	dryRun := flags.Bool("dry-run", false, "preview the changes without saving them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		flags.Usage()
+		return errors.New("-file is required")
+	}
+	switch *strategy {
+	case payloads.ConflictSkip, payloads.ConflictOverwrite, payloads.ConflictRename:
+	default:
+		return fmt.Errorf("unknown strategy %q, expected skip, overwrite or rename", *strategy)
+	}
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	b, err := bundle.Decode(data)
+	if err != nil {
+		return err
+	}
+	items, err := c.BundleService.Import(newCommandContext(), b, payloads.BundleImportQuery{UserId: *uid, Strategy: *strategy, DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		fmt.Printf("%s\t%s\t%s %s %s\n", item.Action, item.Uuid, item.UserId, item.Method, item.Path)
+	}
+	summary := payloads.NewBundleImportResponse(items, b.SchemaVersion, *dryRun)
+	prefix := "Imported"
+	if *dryRun {
+		prefix = "Would import"
+	}
+	fmt.Printf("%s: %d created, %d overwritten, %d renamed, %d skipped\n", prefix, summary.Created, summary.Overwritten, summary.Renamed, summary.Skipped)
+	return nil
+}
+
+// migrate 用法：api migrate <up|down|status|to> [-steps <n>] [-version <n>]
+func (c *CLI) migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate <up|down|status|to> [-steps <n>] [-version <n>]")
+	}
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to roll back")
+	version := flags.Int("version", -1, "target version, 0 rolls back all migrations")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	migrator, err := migrations.New(c.Connection.GetDB())
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	var done []migrations.Migration
+	switch args[0] {
+	case "up":
+		done, err = migrator.Up(ctx)
+	case "down":
+		done, err = migrator.Down(ctx, *steps)
+	case "to":
+		if *version < 0 {
+			flags.Usage()
+			return errors.New("-version is required")
+		}
+		done, err = migrator.To(ctx, *version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			if !status.Known {
+				state += " (unknown)"
+			}
+			fmt.Printf("%04d\t%s\t%s\n", status.Version, status.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down, status or to", args[0])
+	}
+	// 出错前已经执行的迁移也需要输出
+	for _, migration := range done {
+		fmt.Printf("%s\t%04d_%s\n", args[0], migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(done) == 0 {
+		fmt.Println("Nothing to migrate")
+	}
+	return nil
+}
+
+// workspace 用法：api workspace <create|assign>
+//
+//	api workspace create -name <name> [-member <user_id,...>]
+//	api workspace assign -workspace <uuid> -uid <uid>
+func (c *CLI) workspace(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: workspace <create|assign> [flags]")
+	}
+	flags := flag.NewFlagSet("workspace "+args[0], flag.ContinueOnError)
+	switch args[0] {
+	case "create":
+		name := flags.String("name", "", "name of the workspace")
+		members := flags.String("member", "", "comma separated user ids to add as members")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *name == "" {
+			flags.Usage()
+			return errors.New("-name is required")
+		}
+		payload := payloads.WorkspacePayload{Name: *name}
+		if *members != "" {
+			payload.Members = strings.Split(*members, ",")
+		}
+		workspace, err := c.WorkspaceService.Create(newCommandContext(), payload)
+		if err != nil {
+			return err
+		}
+		// 第一个 API Key 只能通过命令行创建。Key 的角色由 auth.api_key_role 决定，
+		// 只有配置为 admin 时才能使用它通过接口管理其他 Key，否则需要 admin 的 Token、HMAC 密钥或 JWT
+		ctx := newCommandContext()
+		ctx.SetRequest(ctx.Request().WithContext(tenant.WithWorkspace(ctx.Request().Context(), workspace.Uuid)))
+		key, secret, err := c.WorkspaceService.CreateKey(ctx, payloads.ApiKeyPayload{Name: "default"})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created workspace %s (%s)\n", workspace.Name, workspace.Uuid)
+		fmt.Printf("API key %s: %s\n", key.Uuid, secret)
+		fmt.Println("The API key is shown only once, store it safely")
+		fmt.Println("With auth enabled, managing API keys through the API requires auth.api_key_role to be admin")
+		return nil
+	case "assign":
+		workspaceId := flags.String("workspace", "", "uuid of the workspace")
+		uid := flags.String("uid", "", "namespace to assign to the workspace")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *workspaceId == "" || *uid == "" {
+			flags.Usage()
+			return errors.New("both -workspace and -uid are required")
+		}
+		assigned, err := c.WorkspaceService.Assign(newCommandContext(), *workspaceId, *uid)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Assigned namespace %s and %d mocks to workspace %s\n", *uid, assigned, *workspaceId)
+		return nil
+	default:
+		return fmt.Errorf("unknown workspace command %q, expected create or assign", args[0])
+	}
+}
+
+// newCommandContext 服务层以 echo.Context 作为参数，命令行下构造一个不对应真实请求的上下文
+func newCommandContext() echo.Context {
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", nil)
+	return echo.New().NewContext(req, nil)
+}
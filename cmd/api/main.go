@@ -8,11 +8,23 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
 	"kite/internal/api/handlers"
+	"kite/internal/api/handlers/auth"
+	"kite/internal/api/handlers/bundle"
+	"kite/internal/api/handlers/importer"
+	"kite/internal/api/handlers/journal"
 	"kite/internal/api/handlers/mock"
+	"kite/internal/api/handlers/namespace"
+	"kite/internal/api/handlers/routing"
+	"kite/internal/api/handlers/scenario"
+	"kite/internal/api/handlers/workspace"
 	"kite/internal/api/routes"
 	"kite/internal/api/validators"
+	kiteAuth "kite/internal/auth"
 	"kite/internal/configs"
 	"kite/internal/database"
+	"kite/internal/database/migrations"
+	journals "kite/internal/journal"
+	kiteRouting "kite/internal/routing"
 	KiteLogger "kite/pkg/logger"
 	"log"
 	"net/http"
@@ -23,17 +35,37 @@ import (
 )
 
 type Server struct {
-	Echo            *echo.Echo
-	MySQLConnection *database.MySQLConnection
-	MockHandler     *mock.ApiHandler
+	Echo             *echo.Echo
+	Connection       *database.Connection
+	MockHandler      *mock.ApiHandler
+	NamespaceHandler *namespace.NamespaceHandler
+	ScenarioHandler  *scenario.ScenarioHandler
+	ImportHandler    *importer.ImportHandler
+	BundleHandler    *bundle.BundleHandler
+	JournalHandler   *journal.JournalHandler
+	RoutingHandler   *routing.RoutingHandler
+	WorkspaceHandler *workspace.WorkspaceHandler
+	AuthHandler      *auth.AuthHandler
+	JournalSink      journals.Sink
+	Routes           *kiteRouting.Table
 }
 
 func NewServer(
 	echo *echo.Echo,
-	mysqlConnection *database.MySQLConnection,
+	connection *database.Connection,
 	mockHandler *mock.ApiHandler,
+	namespaceHandler *namespace.NamespaceHandler,
+	scenarioHandler *scenario.ScenarioHandler,
+	importHandler *importer.ImportHandler,
+	bundleHandler *bundle.BundleHandler,
+	journalHandler *journal.JournalHandler,
+	routingHandler *routing.RoutingHandler,
+	workspaceHandler *workspace.WorkspaceHandler,
+	authHandler *auth.AuthHandler,
+	journalSink journals.Sink,
+	routes *kiteRouting.Table,
 ) *Server {
-	return &Server{echo, mysqlConnection, mockHandler}
+	return &Server{echo, connection, mockHandler, namespaceHandler, scenarioHandler, importHandler, bundleHandler, journalHandler, routingHandler, workspaceHandler, authHandler, journalSink, routes}
 }
 
 func main() {
@@ -56,11 +88,26 @@ func main() {
 		}
 	}()
 
-	server, err := InitializeApp(&cfg.Database, echo.New())
-	if err != nil {
+	// 带参数启动时执行命令行子命令，例如 import-openapi
+	if len(os.Args) > 1 {
+		if err := runCommand(&cfg.Database, os.Args[1:]); err != nil {
+			log.Fatalf("Command failed: %v", err)
+		}
 		return
 	}
 
+	server, err := InitializeApp(&cfg.Database, &cfg.Journal, &cfg.Routing, &cfg.Proxy, &cfg.Workspace, &cfg.Auth, echo.New())
+	if err != nil {
+		log.Fatalf("Failed to initialize application: %v", err)
+	}
+
+	// 按配置在启动时执行数据库迁移
+	if cfg.Server.AutoMigrate && server.Connection.GetDB() != nil {
+		if err := migrate(server.Connection); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+
 	// 注册全局中间件
 	registerGlobalMiddlewares(server.Echo)
 	// 注册自定义错误处理器
@@ -69,7 +116,21 @@ func main() {
 	server.Echo.Validator = validators.NewCustomValidator()
 
 	// 注册路由
-	routes.RegisterRoutes(server.Echo, server.MockHandler)
+	routes.RegisterRoutes(
+		server.Echo,
+		server.MockHandler,
+		server.NamespaceHandler,
+		server.ScenarioHandler,
+		server.ImportHandler,
+		server.BundleHandler,
+		server.JournalHandler,
+		server.RoutingHandler,
+		server.WorkspaceHandler,
+		server.AuthHandler,
+	)
+
+	// 定期检查存储是否被其他实例修改
+	server.Routes.Start()
 
 	// 创建通道接受关机信号
 	quit := make(chan os.Signal, 1)
@@ -105,8 +166,16 @@ func main() {
 		KiteLogger.Error("Server shutdown failed:", zap.Error(err))
 	}
 
+	// 停止路由表的后台检查
+	server.Routes.Close()
+
+	// 关闭请求日志的存储，文件存储需要释放文件句柄
+	if err := server.JournalSink.Close(); err != nil {
+		KiteLogger.Error("Failed to close journal", zap.Error(err))
+	}
+
 	// 关闭数据库连接
-	err = server.MySQLConnection.CloseDB()
+	err = server.Connection.CloseDB()
 	if err != nil {
 		KiteLogger.Error("Failed to close database", zap.Error(err))
 		os.Exit(1)
@@ -114,6 +183,19 @@ func main() {
 	KiteLogger.Info("Server gracefully stopped")
 }
 
+// migrate 执行全部未执行的数据库迁移
+func migrate(connection *database.Connection) error {
+	migrator, err := migrations.New(connection.GetDB())
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		KiteLogger.Info("Applied migration", zap.Int("version", migration.Version), zap.String("name", migration.Name))
+	}
+	return err
+}
+
 func registerGlobalMiddlewares(e *echo.Echo) {
 	// 生成请求ID
 	e.Use(middleware.RequestID())
@@ -125,7 +207,12 @@ func registerGlobalMiddlewares(e *echo.Echo) {
 		LogStatus: true,
 		LogURI:    true,
 		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
-			KiteLogger.InfoC(c, "request", zap.String("URI", v.URI), zap.Int("status", v.Status))
+			fields := []zap.Field{zap.String("URI", v.URI), zap.Int("status", v.Status)}
+			// 管理接口认证通过后记录调用方，便于审计
+			if principal, ok := kiteAuth.FromContext(c.Request().Context()); ok {
+				fields = append(fields, zap.String("subject", principal.Subject), zap.String("role", string(principal.Role)))
+			}
+			KiteLogger.InfoC(c, "request", fields...)
 			return nil
 		},
 	}))
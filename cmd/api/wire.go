@@ -6,28 +6,63 @@ package main
 import (
 	"github.com/google/wire"
 	"github.com/labstack/echo/v4"
+	"kite/internal/api/handlers/auth"
+	"kite/internal/api/handlers/bundle"
+	"kite/internal/api/handlers/importer"
+	"kite/internal/api/handlers/journal"
 	"kite/internal/api/handlers/mock"
+	"kite/internal/api/handlers/namespace"
+	"kite/internal/api/handlers/routing"
+	"kite/internal/api/handlers/scenario"
+	"kite/internal/api/handlers/workspace"
 	"kite/internal/configs"
 	"kite/internal/database"
+	"kite/internal/proxy"
 	"kite/internal/repositories"
+	kiteRouting "kite/internal/routing"
 	"kite/internal/services"
+	"kite/internal/state"
 )
 
+// RepositorySet 服务使用的 mock 存储由路由表包装，写操作会使路由表失效；命名空间配置随路由表一起缓存
 var RepositorySet = wire.NewSet(
-	repositories.NewApiRepository,
+	kiteRouting.NewRepository,
+	wire.Bind(new(repositories.ApiRepository), new(*kiteRouting.Table)),
+	kiteRouting.NewNamespaceRepository,
+	repositories.NewWorkspaceRepository,
+	repositories.NewJournalSink,
 )
 
 var ServiceSet = wire.NewSet(
 	services.NewApiService,
+	services.NewNamespaceService,
+	services.NewScenarioService,
+	services.NewProxyService,
+	services.NewImportService,
+	services.NewBundleService,
+	services.NewJournalService,
+	services.NewRoutingService,
+	services.NewWorkspaceService,
+	services.NewAuthService,
 )
 
 var HandlerSet = wire.NewSet(
 	mock.NewApiHandler,
+	namespace.NewNamespaceHandler,
+	scenario.NewScenarioHandler,
+	importer.NewImportHandler,
+	bundle.NewBundleHandler,
+	journal.NewJournalHandler,
+	routing.NewRoutingHandler,
+	workspace.NewWorkspaceHandler,
+	auth.NewAuthHandler,
 )
 
-func InitializeApp(cfg *configs.MySQLConfig, echo *echo.Echo) (*Server, error) {
+func InitializeApp(cfg *configs.DatabaseConfig, journalCfg *configs.JournalConfig, routingCfg *configs.RoutingConfig, proxyCfg *configs.ProxyConfig, workspaceCfg *configs.WorkspaceConfig, authCfg *configs.AuthConfig, echo *echo.Echo) (*Server, error) {
 	wire.Build(
-		database.NewMySQLConnection,
+		database.NewConnection,
+		state.NewMemoryStore,
+		proxy.NewForwarder,
 		HandlerSet,
 		ServiceSet,
 		RepositorySet,
@@ -35,3 +70,17 @@ func InitializeApp(cfg *configs.MySQLConfig, echo *echo.Echo) (*Server, error) {
 	)
 	return nil, nil
 }
+
+func InitializeCLI(cfg *configs.DatabaseConfig) (*CLI, error) {
+	wire.Build(
+		database.NewConnection,
+		repositories.NewApiRepository,
+		repositories.NewNamespaceRepository,
+		repositories.NewWorkspaceRepository,
+		services.NewImportService,
+		services.NewBundleService,
+		services.NewWorkspaceService,
+		NewCLI,
+	)
+	return nil, nil
+}
@@ -9,28 +9,104 @@ package main
 import (
 	"github.com/google/wire"
 	"github.com/labstack/echo/v4"
+	"kite/internal/api/handlers/auth"
+	"kite/internal/api/handlers/bundle"
+	"kite/internal/api/handlers/importer"
+	"kite/internal/api/handlers/journal"
 	"kite/internal/api/handlers/mock"
+	"kite/internal/api/handlers/namespace"
+	routing2 "kite/internal/api/handlers/routing"
+	"kite/internal/api/handlers/scenario"
+	"kite/internal/api/handlers/workspace"
 	"kite/internal/configs"
 	"kite/internal/database"
+	"kite/internal/proxy"
 	"kite/internal/repositories"
+	"kite/internal/routing"
 	"kite/internal/services"
+	"kite/internal/state"
 )
 
 // Injectors from wire.go:
 
-func InitializeApp(cfg *configs.MySQLConfig, echo2 *echo.Echo) (*Server, error) {
-	mySQLConnection := database.NewMySQLConnection(cfg)
-	apiRepository := repositories.NewApiRepository(mySQLConnection)
-	apiService := services.NewApiService(apiRepository)
-	apiHandler := mock.NewApiHandler(apiService)
-	server := NewServer(echo2, mySQLConnection, apiHandler)
+func InitializeApp(cfg *configs.DatabaseConfig, journalCfg *configs.JournalConfig, routingCfg *configs.RoutingConfig, proxyCfg *configs.ProxyConfig, workspaceCfg *configs.WorkspaceConfig, authCfg *configs.AuthConfig, echo2 *echo.Echo) (*Server, error) {
+	connection, err := database.NewConnection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	table, err := routing.NewRepository(cfg, connection, routingCfg)
+	if err != nil {
+		return nil, err
+	}
+	namespaceRepository, err := routing.NewNamespaceRepository(cfg, connection, table)
+	if err != nil {
+		return nil, err
+	}
+	namespaceService := services.NewNamespaceService(namespaceRepository)
+	workspaceRepository, err := repositories.NewWorkspaceRepository(cfg, connection)
+	if err != nil {
+		return nil, err
+	}
+	workspaceService := services.NewWorkspaceService(workspaceRepository, table, namespaceRepository)
+	store := state.NewMemoryStore()
+	apiService := services.NewApiService(table, table, namespaceService, workspaceService, store)
+	forwarder := proxy.NewForwarder(proxyCfg)
+	proxyService := services.NewProxyService(table, forwarder)
+	apiHandler := mock.NewApiHandler(apiService, namespaceService, proxyService)
+	namespaceHandler := namespace.NewNamespaceHandler(namespaceService)
+	scenarioService := services.NewScenarioService(table, store)
+	scenarioHandler := scenario.NewScenarioHandler(scenarioService)
+	importService := services.NewImportService(table)
+	importHandler := importer.NewImportHandler(importService)
+	bundleService := services.NewBundleService(table, workspaceService)
+	bundleHandler := bundle.NewBundleHandler(bundleService)
+	sink, err := repositories.NewJournalSink(journalCfg, connection)
+	if err != nil {
+		return nil, err
+	}
+	journalService := services.NewJournalService(sink, journalCfg)
+	journalHandler := journal.NewJournalHandler(journalService)
+	routingService := services.NewRoutingService(table)
+	routingHandler := routing2.NewRoutingHandler(routingService)
+	workspaceHandler := workspace.NewWorkspaceHandler(workspaceService, workspaceCfg)
+	authService, err := services.NewAuthService(authCfg, workspaceService)
+	if err != nil {
+		return nil, err
+	}
+	authHandler := auth.NewAuthHandler(authService, authCfg)
+	server := NewServer(echo2, connection, apiHandler, namespaceHandler, scenarioHandler, importHandler, bundleHandler, journalHandler, routingHandler, workspaceHandler, authHandler, sink, table)
 	return server, nil
 }
 
+func InitializeCLI(cfg *configs.DatabaseConfig) (*CLI, error) {
+	connection, err := database.NewConnection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	apiRepository, err := repositories.NewApiRepository(cfg, connection)
+	if err != nil {
+		return nil, err
+	}
+	importService := services.NewImportService(apiRepository)
+	workspaceRepository, err := repositories.NewWorkspaceRepository(cfg, connection)
+	if err != nil {
+		return nil, err
+	}
+	namespaceRepository, err := repositories.NewNamespaceRepository(cfg, connection)
+	if err != nil {
+		return nil, err
+	}
+	workspaceService := services.NewWorkspaceService(workspaceRepository, apiRepository, namespaceRepository)
+	bundleService := services.NewBundleService(apiRepository, workspaceService)
+	cli := NewCLI(connection, importService, bundleService, workspaceService)
+	return cli, nil
+}
+
 // wire.go:
 
-var RepositorySet = wire.NewSet(repositories.NewApiRepository)
+// RepositorySet 服务使用的 mock 存储由路由表包装，写操作会使路由表失效；命名空间配置随路由表一起缓存
+var RepositorySet = wire.NewSet(routing.NewRepository, wire.Bind(new(repositories.ApiRepository), new(*routing.Table)), routing.NewNamespaceRepository, repositories.NewWorkspaceRepository, repositories.NewJournalSink)
 
-var ServiceSet = wire.NewSet(services.NewApiService)
+var ServiceSet = wire.NewSet(services.NewApiService, services.NewNamespaceService, services.NewScenarioService, services.NewProxyService, services.NewImportService, services.NewBundleService, services.NewJournalService, services.NewRoutingService, services.NewWorkspaceService, services.NewAuthService)
 
-var HandlerSet = wire.NewSet(mock.NewApiHandler)
+var HandlerSet = wire.NewSet(mock.NewApiHandler, namespace.NewNamespaceHandler, scenario.NewScenarioHandler, importer.NewImportHandler, bundle.NewBundleHandler, journal.NewJournalHandler, routing2.NewRoutingHandler, workspace.NewWorkspaceHandler, auth.NewAuthHandler)
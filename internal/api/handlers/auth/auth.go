@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/handlers/workspace"
+	"kite/internal/api/payloads"
+	"kite/internal/auth"
+	"kite/internal/configs"
+	KiteError "kite/internal/errors"
+	"kite/internal/services"
+	"kite/internal/tenant"
+	"kite/pkg/response"
+)
+
+type AuthHandler struct {
+	srv services.AuthService
+	cfg *configs.AuthConfig
+}
+
+func NewAuthHandler(srv services.AuthService, cfg *configs.AuthConfig) *AuthHandler {
+	return &AuthHandler{srv, cfg}
+}
+
+// Me 返回当前调用方的身份与角色，未开启认证时所有调用方都拥有 admin 权限
+func (h *AuthHandler) Me(ctx echo.Context) error {
+	principal, ok := auth.FromContext(ctx.Request().Context())
+	if !ok {
+		principal = &auth.Principal{Subject: "anonymous", Role: auth.RoleAdmin, Method: auth.MethodNone}
+	}
+	return response.Success(ctx, payloads.NewPrincipalResponse(principal))
+}
+
+// Require 要求调用方至少拥有 role 角色，凭证无效时返回 401，角色不足时返回 403。
+// 认证结果保存在 context 中，同一个请求经过多个 Require 时只认证一次；
+// 使用工作区 API Key 认证时请求同时限定在 Key 所属的工作区内。未开启认证时不做检查
+func (h *AuthHandler) Require(role auth.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !h.cfg.Enabled {
+				return next(ctx)
+			}
+			principal, ok := auth.FromContext(ctx.Request().Context())
+			if !ok {
+				var err error
+				if principal, err = h.authenticate(ctx); err != nil {
+					return err
+				}
+				req := ctx.Request()
+				reqCtx := auth.WithPrincipal(req.Context(), principal)
+				if principal.WorkspaceId != "" {
+					reqCtx = tenant.WithWorkspace(reqCtx, principal.WorkspaceId)
+				}
+				ctx.SetRequest(req.WithContext(reqCtx))
+			}
+			if !principal.Role.Allows(role) {
+				return KiteError.New(KiteError.ForbiddenError, nil).WithDetail(fmt.Sprintf("%s role is required", role))
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// authenticate 未携带 Authorization 时也接受 X-Api-Key 请求头中的工作区 API Key
+func (h *AuthHandler) authenticate(ctx echo.Context) (*auth.Principal, error) {
+	req := ctx.Request()
+	if req.Header.Get(echo.HeaderAuthorization) == "" {
+		if key := req.Header.Get(workspace.ApiKeyHeader); key != "" {
+			return h.srv.AuthenticateApiKey(ctx, key)
+		}
+	}
+	return h.srv.Authenticate(ctx)
+}
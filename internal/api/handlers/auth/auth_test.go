@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"io"
+	"kite/internal/api/handlers"
+	"kite/internal/api/handlers/workspace"
+	"kite/internal/api/payloads"
+	"kite/internal/api/validators"
+	"kite/internal/auth"
+	"kite/internal/configs"
+	KiteError "kite/internal/errors"
+	"kite/internal/repositories"
+	"kite/internal/services"
+	"kite/internal/tenant"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hs256 签发 HS256 令牌
+func hs256(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestRequire(t *testing.T) {
+	cfg := &configs.AuthConfig{
+		Enabled: true,
+		Tokens: []configs.TokenConfig{
+			{Name: "reader", Token: "viewer-token", Role: "viewer"},
+			{Name: "ci", Token: "editor-token", Role: "editor", Workspace: "ws-token"},
+			{Name: "ops", Token: "admin-token", Role: "admin"},
+		},
+		HMACKeys: []configs.HMACKeyConfig{{KeyId: "deploy", Secret: "hmac-secret", Role: "editor", Workspace: "ws-hmac"}},
+		JWT:      configs.JWTConfig{Secret: "hs-secret"},
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	// 同一个签名请求在两个用例中发送，第二次是重放
+	replayed := httptest.NewRequest(http.MethodGet, "/api/v1/apis", nil)
+	if err := auth.SignRequest(replayed, "deploy", "hmac-secret", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		role          auth.Role
+		authorization string
+		request       *http.Request
+		wantCode      KiteError.ErrorCode
+		wantWorkspace string
+	}{
+		{name: "missing credentials", role: auth.RoleViewer, wantCode: KiteError.UnauthorizedError},
+		{name: "unknown token", role: auth.RoleViewer, authorization: "Bearer guessed", wantCode: KiteError.UnauthorizedError},
+		{name: "unsupported scheme", role: auth.RoleViewer, authorization: "Basic dXNlcjpwYXNz", wantCode: KiteError.UnauthorizedError},
+		{name: "viewer reads", role: auth.RoleViewer, authorization: "Bearer viewer-token"},
+		{name: "viewer lacks the editor role", role: auth.RoleEditor, authorization: "Bearer viewer-token", wantCode: KiteError.ForbiddenError},
+		{name: "editor lacks the admin role", role: auth.RoleAdmin, authorization: "Bearer editor-token", wantCode: KiteError.ForbiddenError},
+		{name: "token mapped to a workspace", role: auth.RoleEditor, authorization: "Bearer editor-token", wantWorkspace: "ws-token"},
+		{name: "admin token stays unscoped", role: auth.RoleAdmin, authorization: "Bearer admin-token"},
+		{name: "signed request mapped to a workspace", role: auth.RoleEditor, request: replayed, wantWorkspace: "ws-hmac"},
+		{name: "replayed signed request", role: auth.RoleEditor, request: replayed, wantCode: KiteError.UnauthorizedError},
+		{
+			name:          "jwt workspace claim",
+			role:          auth.RoleViewer,
+			authorization: "Bearer " + hs256(t, "hs-secret", map[string]any{"sub": "alice", "role": "viewer", "workspace": "ws-jwt", "exp": exp}),
+			wantWorkspace: "ws-jwt",
+		},
+		{
+			name:          "jwt role below the required role",
+			role:          auth.RoleEditor,
+			authorization: "Bearer " + hs256(t, "hs-secret", map[string]any{"sub": "alice", "role": "viewer", "exp": exp}),
+			wantCode:      KiteError.ForbiddenError,
+		},
+		{
+			name:          "jwt without a known role",
+			role:          auth.RoleViewer,
+			authorization: "Bearer " + hs256(t, "hs-secret", map[string]any{"sub": "alice", "role": "owner", "exp": exp}),
+			wantCode:      KiteError.ForbiddenError,
+		},
+		{
+			name:          "expired jwt",
+			role:          auth.RoleViewer,
+			authorization: "Bearer " + hs256(t, "hs-secret", map[string]any{"sub": "alice", "role": "admin", "exp": time.Now().Add(-time.Hour).Unix()}),
+			wantCode:      KiteError.UnauthorizedError,
+		},
+		{
+			name:          "jwt without exp",
+			role:          auth.RoleViewer,
+			authorization: "Bearer " + hs256(t, "hs-secret", map[string]any{"sub": "alice", "role": "admin"}),
+			wantCode:      KiteError.UnauthorizedError,
+		},
+		{
+			name:          "jwt with a forged signature",
+			role:          auth.RoleViewer,
+			authorization: "Bearer " + hs256(t, "guessed", map[string]any{"sub": "alice", "role": "admin", "exp": exp}),
+			wantCode:      KiteError.UnauthorizedError,
+		},
+	}
+	workspaces := services.NewWorkspaceService(repositories.NewMemoryWorkspaceRepository(), repositories.NewMemoryApiRepository(), repositories.NewMemoryNamespaceRepository())
+	srv, err := services.NewAuthService(cfg, workspaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewAuthHandler(srv, cfg)
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.request
+			if req == nil {
+				req = httptest.NewRequest(http.MethodGet, "/api/v1/apis", nil)
+			}
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			ctx := e.NewContext(req, httptest.NewRecorder())
+			called := false
+			var scopedTo string
+			err := h.Require(tt.role)(func(ctx echo.Context) error {
+				called = true
+				scopedTo, _ = tenant.Workspace(ctx.Request().Context())
+				return nil
+			})(ctx)
+
+			if tt.wantCode != 0 {
+				appErr, ok := KiteError.IsAppError(err)
+				if !ok || appErr.Code != tt.wantCode || called {
+					t.Fatalf("err = %v (next called %v), want code %v", err, called, tt.wantCode)
+				}
+				return
+			}
+			if err != nil || !called {
+				t.Fatalf("err = %v, next called = %v", err, called)
+			}
+			if scopedTo != tt.wantWorkspace {
+				t.Fatalf("request scoped to %q, want %q", scopedTo, tt.wantWorkspace)
+			}
+		})
+	}
+}
+
+func TestRequireDisabled(t *testing.T) {
+	h := NewAuthHandler(nil, &configs.AuthConfig{})
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodDelete, "/api/v1/workspaces/x", strings.NewReader("")), httptest.NewRecorder())
+	called := false
+	if err := h.Require(auth.RoleAdmin)(func(echo.Context) error { called = true; return nil })(ctx); err != nil || !called {
+		t.Fatalf("err = %v, next called = %v", err, called)
+	}
+}
+
+func TestRequireLimitsSignedBody(t *testing.T) {
+	cfg := &configs.AuthConfig{
+		Enabled:          true,
+		HMACKeys:         []configs.HMACKeyConfig{{KeyId: "deploy", Secret: "hmac-secret", Role: "editor"}},
+		HMACMaxBodyBytes: 16,
+	}
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "empty body"},
+		{name: "body at the limit", body: strings.Repeat("x", 16)},
+		{name: "body over the limit", body: strings.Repeat("x", 17), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	workspaces := services.NewWorkspaceService(repositories.NewMemoryWorkspaceRepository(), repositories.NewMemoryApiRepository(), repositories.NewMemoryNamespaceRepository())
+	srv, err := services.NewAuthService(cfg, workspaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewAuthHandler(srv, cfg)
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/apis", strings.NewReader(tt.body))
+			if err := auth.SignRequest(req, "deploy", "hmac-secret", time.Now()); err != nil {
+				t.Fatal(err)
+			}
+			var received string
+			err := h.Require(auth.RoleEditor)(func(ctx echo.Context) error {
+				body, err := io.ReadAll(ctx.Request().Body)
+				received = string(body)
+				return err
+			})(e.NewContext(req, httptest.NewRecorder()))
+
+			if tt.wantStatus != 0 {
+				appErr, ok := KiteError.IsAppError(err)
+				if !ok || appErr.HTTPStatus != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil || received != tt.body {
+				t.Fatalf("err = %v, handler read %q, want the signed body %q", err, received, tt.body)
+			}
+		})
+	}
+}
+
+// TestBootstrapKeyManagesKeys 与 workspace create 命令一样创建工作区和第一个 Key，再用它访问 /keys
+func TestBootstrapKeyManagesKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        configs.AuthConfig
+		wantStatus int
+	}{
+		{name: "auth disabled", wantStatus: http.StatusOK},
+		{name: "default api key role", cfg: configs.AuthConfig{Enabled: true}, wantStatus: http.StatusForbidden},
+		{name: "editor api key role", cfg: configs.AuthConfig{Enabled: true, ApiKeyRole: "editor"}, wantStatus: http.StatusForbidden},
+		{name: "admin api key role", cfg: configs.AuthConfig{Enabled: true, ApiKeyRole: "admin"}, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaces := services.NewWorkspaceService(repositories.NewMemoryWorkspaceRepository(), repositories.NewMemoryApiRepository(), repositories.NewMemoryNamespaceRepository())
+			e := echo.New()
+			e.HTTPErrorHandler = handlers.CustomHTTPErrorHandler
+			e.Validator = validators.NewCustomValidator()
+			setup := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+			created, err := workspaces.Create(setup, payloads.WorkspacePayload{Name: "team"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			setup.SetRequest(setup.Request().WithContext(tenant.WithWorkspace(setup.Request().Context(), created.Uuid)))
+			_, bootstrap, err := workspaces.CreateKey(setup, payloads.ApiKeyPayload{Name: "default"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := tt.cfg
+			srv, err := services.NewAuthService(&cfg, workspaces)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := NewAuthHandler(srv, &cfg)
+			w := workspace.NewWorkspaceHandler(workspaces, &configs.WorkspaceConfig{})
+			// 与 routes.RegisterRoutes 中的工作区路由一致
+			group := e.Group("/api/v1/workspace", h.Require(auth.RoleViewer), w.Middleware)
+			group.GET("/keys", w.ListKeys, h.Require(auth.RoleAdmin))
+			group.POST("/keys", w.CreateKey, h.Require(auth.RoleAdmin))
+
+			for _, req := range []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/api/v1/workspace/keys", nil),
+				httptest.NewRequest(http.MethodPost, "/api/v1/workspace/keys", strings.NewReader(`{"name":"ci"}`)),
+			} {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				req.Header.Set(workspace.ApiKeyHeader, bootstrap)
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				if rec.Code != tt.wantStatus {
+					t.Fatalf("%s %s = %d %s, want %d", req.Method, req.URL.Path, rec.Code, rec.Body, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package bundle
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"io"
+	"kite/internal/api/payloads"
+	"kite/internal/api/validators"
+	bundles "kite/internal/bundle"
+	KiteError "kite/internal/errors"
+	"kite/internal/services"
+	"kite/pkg/response"
+	"net/http"
+)
+
+type BundleHandler struct {
+	srv services.BundleService
+}
+
+func NewBundleHandler(srv services.BundleService) *BundleHandler {
+	return &BundleHandler{srv}
+}
+
+// Export 以附件形式返回 JSON（默认）或 YAML 格式的 bundle
+func (h *BundleHandler) Export(ctx echo.Context) error {
+	var query payloads.BundleExportQuery
+	if err := validators.BindAndValidate(ctx, &query); err != nil {
+		return err
+	}
+	b, err := h.srv.Export(ctx, query)
+	if err != nil {
+		return err
+	}
+	format := query.Format
+	if format == "" {
+		format = bundles.FormatJSON
+	}
+	data, err := bundles.Encode(b, format)
+	if err != nil {
+		return KiteError.New(KiteError.MarshalError, err)
+	}
+	contentType := echo.MIMEApplicationJSON
+	if format == bundles.FormatYAML {
+		contentType = "application/yaml"
+	}
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "mocks."+format))
+	return ctx.Blob(http.StatusOK, contentType, data)
+}
+
+// Import 请求体为 JSON 或 YAML 格式的 bundle，冲突处理方式和预览等选项通过查询参数指定
+func (h *BundleHandler) Import(ctx echo.Context) error {
+	var query payloads.BundleImportQuery
+	if err := validators.BindQueryAndValidate(ctx, &query); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return KiteError.NewWithMessage(KiteError.BadRequestError, "Failed to read request body", err)
+	}
+	b, err := bundles.Decode(data)
+	if err != nil {
+		return KiteError.NewWithMessage(KiteError.ImportError, err.Error(), err)
+	}
+	items, err := h.srv.Import(ctx, b, query)
+	if err != nil {
+		return err
+	}
+	return response.Success(ctx, payloads.NewBundleImportResponse(items, b.SchemaVersion, query.DryRun))
+}
@@ -10,9 +10,10 @@ import (
 )
 
 type ErrorResponse struct {
-	Code      int    `json:"code"`
-	Message   string `json:"message"`
-	RequestId string `json:"requestId"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	RequestId string      `json:"requestId"`
+	Details   interface{} `json:"details,omitempty"`
 }
 
 func CustomHTTPErrorHandler(err error, c echo.Context) {
@@ -20,6 +21,7 @@ func CustomHTTPErrorHandler(err error, c echo.Context) {
 		code    = http.StatusInternalServerError
 		message = "Internal Server Error"
 		errCode = int(kiteError.InternalServerError)
+		details interface{}
 	)
 	appErr, ok := kiteError.IsAppError(err)
 	println(ok)
@@ -27,6 +29,7 @@ func CustomHTTPErrorHandler(err error, c echo.Context) {
 		code = appErr.HTTPStatus
 		message = appErr.Message
 		errCode = int(appErr.Code)
+		details = appErr.Data
 	}
 	var e *echo.HTTPError
 	if errors.As(err, &e) {
@@ -49,6 +52,7 @@ func CustomHTTPErrorHandler(err error, c echo.Context) {
 		Code:      errCode,
 		Message:   message,
 		RequestId: c.Response().Header().Get(echo.HeaderXRequestID),
+		Details:   details,
 	}); err != nil {
 		log.Printf("failed to send error response: %v", err)
 	}
@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"github.com/labstack/echo/v4"
+	"io"
+	"kite/internal/api/payloads"
+	KiteError "kite/internal/errors"
+	"kite/internal/services"
+	"kite/pkg/response"
+)
+
+type ImportHandler struct {
+	srv services.ImportService
+}
+
+func NewImportHandler(srv services.ImportService) *ImportHandler {
+	return &ImportHandler{srv}
+}
+
+// importFunc ImportService 中各种格式的导入方法
+type importFunc func(ctx echo.Context, uid string, data []byte, dryRun bool) (*services.ImportResult, error)
+
+// ImportOpenAPI 请求体为 YAML 或 JSON 格式的 OpenAPI 3 / Swagger 2 规范
+func (h *ImportHandler) ImportOpenAPI(ctx echo.Context) error {
+	return h.handle(ctx, h.srv.ImportOpenAPI)
+}
+
+// ImportHAR 请求体为浏览器开发者工具导出的 HAR 文件
+func (h *ImportHandler) ImportHAR(ctx echo.Context) error {
+	return h.handle(ctx, h.srv.ImportHAR)
+}
+
+// ImportPostman 请求体为 Postman v2 / v2.1 集合
+func (h *ImportHandler) ImportPostman(ctx echo.Context) error {
+	return h.handle(ctx, h.srv.ImportPostman)
+}
+
+// handle 读取原始请求体并导入，查询参数 dry_run=true 时只返回预览结果而不写入
+func (h *ImportHandler) handle(ctx echo.Context, importer importFunc) error {
+	var dryRun bool
+	if err := echo.QueryParamsBinder(ctx).Bool("dry_run", &dryRun).BindError(); err != nil {
+		return KiteError.NewWithMessage(KiteError.BadRequestError, "Invalid dry_run parameter", err)
+	}
+	data, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return KiteError.NewWithMessage(KiteError.BadRequestError, "Failed to read request body", err)
+	}
+	result, err := importer(ctx, ctx.Param("uid"), data, dryRun)
+	if err != nil {
+		return err
+	}
+	return response.Success(ctx, payloads.NewImportResponse(result.Created, result.Skipped, dryRun))
+}
@@ -0,0 +1,127 @@
+package journal
+
+import (
+	"bytes"
+	uuid2 "github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"io"
+	"kite/internal/api/payloads"
+	"kite/internal/api/validators"
+	"kite/internal/journal"
+	"kite/internal/services"
+	"kite/pkg/response"
+	"strings"
+	"time"
+)
+
+type JournalHandler struct {
+	srv services.JournalService
+}
+
+func NewJournalHandler(srv services.JournalService) *JournalHandler {
+	return &JournalHandler{srv}
+}
+
+// Search 分页查询命名空间下的请求日志，最新的记录在前
+func (h *JournalHandler) Search(ctx echo.Context) error {
+	var query payloads.JournalQuery
+	if err := validators.BindAndValidate(ctx, &query); err != nil {
+		return err
+	}
+	entries, total, err := h.srv.Search(ctx, ctx.Param("uid"), query)
+	if err != nil {
+		return err
+	}
+	query.Normalize()
+	return response.Success(ctx, payloads.JournalListResponse{
+		Items:    entries,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	})
+}
+
+// Clear 清空命名空间下的请求日志
+func (h *JournalHandler) Clear(ctx echo.Context) error {
+	if err := h.srv.Clear(ctx, ctx.Param("uid")); err != nil {
+		return err
+	}
+	return response.SuccessWithoutData(ctx)
+}
+
+// Verify 校验请求日志中满足条件的请求次数，校验结果在 passed 中返回
+func (h *JournalHandler) Verify(ctx echo.Context) error {
+	var payload payloads.VerificationPayload
+	if err := validators.BindAndValidate(ctx, &payload); err != nil {
+		return err
+	}
+	result, err := h.srv.Verify(ctx, ctx.Param("uid"), payload)
+	if err != nil {
+		return err
+	}
+	return response.Success(ctx, result)
+}
+
+// Middleware 记录经过 mock 入口的每个请求。只读取记录所需长度的请求体并放回，不影响处理器读取。
+// 记录在写出响应头之前完成，客户端收到响应时记录已经可以查询；
+// 处理器返回的错误在这里交给错误处理器写出，以便记录最终的状态码
+func (h *JournalHandler) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		start := time.Now()
+		req := ctx.Request()
+		var body []byte
+		if req.Body != nil {
+			// 最多多读一个字节用于判断是否截断，已读取的部分放回请求体之前，处理器仍然读到完整的请求体
+			body, _ = io.ReadAll(io.LimitReader(req.Body, int64(h.srv.MaxBodyBytes())+1))
+			req.Body = replayBody{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+		}
+		recorded := false
+		record := func() {
+			if recorded {
+				return
+			}
+			recorded = true
+			h.srv.Record(ctx, newEntry(ctx, body, start))
+		}
+		ctx.Response().Before(record)
+		if err := next(ctx); err != nil {
+			ctx.Error(err)
+		}
+		// 故障注入可能直接断开连接而不写出响应头
+		record()
+		return nil
+	}
+}
+
+func newEntry(ctx echo.Context, body []byte, start time.Time) *journal.Entry {
+	req := ctx.Request()
+	matched, _ := ctx.Get(journal.ContextKeyMatchedUuid).(string)
+	headers := make(map[string]string, len(req.Header))
+	for key, values := range req.Header {
+		if journal.IsSensitiveHeader(key) {
+			headers[key] = journal.RedactedValue
+			continue
+		}
+		headers[key] = strings.Join(values, ", ")
+	}
+	return &journal.Entry{
+		Id:          uuid2.NewString(),
+		Uid:         ctx.Param("uid"),
+		Method:      req.Method,
+		Path:        "/" + ctx.Param("*"),
+		Query:       req.URL.RawQuery,
+		Headers:     headers,
+		Body:        string(body),
+		MatchedUuid: matched,
+		StatusCode:  ctx.Response().Status,
+		LatencyMs:   float64(time.Since(start).Microseconds()) / 1000,
+		RequestId:   ctx.Response().Header().Get(echo.HeaderXRequestID),
+		CreatedAt:   start,
+	}
+}
+
+// replayBody 先返回已读取的部分再继续读取原请求体，关闭时关闭原请求体
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
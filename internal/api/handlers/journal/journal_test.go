@@ -0,0 +1,77 @@
+package journal
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"io"
+	"kite/internal/configs"
+	"kite/internal/journal"
+	"kite/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareRecordsBoundedRedactedRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		want      string
+		truncated bool
+	}{
+		{name: "empty body", body: "", want: ""},
+		{name: "body within the limit", body: "0123456789", want: "0123456789"},
+		{name: "body over the limit", body: strings.Repeat("x", 32), want: strings.Repeat("x", 16), truncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := journal.NewMemorySink(10)
+			h := NewJournalHandler(services.NewJournalService(sink, &configs.JournalConfig{MaxBodyBytes: 16}))
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/mock/ns/items", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "KITE-HMAC-SHA256 Credential=deploy, Signature=abc")
+			req.Header.Set("X-Api-Key", "secret")
+			req.Header.Add("Cookie", "a=1")
+			req.Header.Add("Cookie", "b=2")
+			req.Header.Set("X-Tier", "vip")
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.SetParamNames("uid", "*")
+			ctx.SetParamValues("ns", "items")
+
+			var seen string
+			err := h.Middleware(func(ctx echo.Context) error {
+				data, err := io.ReadAll(ctx.Request().Body)
+				seen = string(data)
+				if err != nil {
+					return err
+				}
+				return ctx.NoContent(http.StatusNoContent)
+			})(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seen != tt.body {
+				t.Fatalf("handler read %q, want the full body %q", seen, tt.body)
+			}
+
+			entries, _, err := sink.Search(context.Background(), journal.Filter{Uid: "ns"})
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("Search = %v, %v, want one entry", entries, err)
+			}
+			entry := entries[0]
+			if entry.Body != tt.want || entry.BodyTruncated != tt.truncated {
+				t.Fatalf("recorded body %q truncated=%v, want %q truncated=%v", entry.Body, entry.BodyTruncated, tt.want, tt.truncated)
+			}
+			for _, key := range []string{"Authorization", "X-Api-Key", "Cookie"} {
+				if entry.Headers[key] != journal.RedactedValue {
+					t.Fatalf("header %s recorded as %q, want it redacted", key, entry.Headers[key])
+				}
+			}
+			if entry.Headers["X-Tier"] != "vip" {
+				t.Fatalf("header X-Tier recorded as %q, want vip", entry.Headers["X-Tier"])
+			}
+		})
+	}
+}
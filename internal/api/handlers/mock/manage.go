@@ -0,0 +1,120 @@
+package mock
+
+import (
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/api/validators"
+	KiteError "kite/internal/errors"
+	"kite/internal/models"
+	"kite/pkg/response"
+)
+
+// ListApis 分页查询 mock 定义
+func (h *ApiHandler) ListApis(ctx echo.Context) error {
+	var query payloads.MockApiQuery
+	if err := validators.BindAndValidate(ctx, &query); err != nil {
+		return err
+	}
+	apis, total, err := h.srv.List(ctx, query)
+	if err != nil {
+		return err
+	}
+	query.Normalize()
+	items := make([]*payloads.MockApiResponse, 0, len(apis))
+	for _, api := range apis {
+		item, err := payloads.NewMockApiResponse(api)
+		if err != nil {
+			return KiteError.New(KiteError.UnmarshalError, err)
+		}
+		items = append(items, item)
+	}
+
+	return response.Success(ctx, payloads.MockApiListResponse{
+		Items:    items,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	})
+}
+
+// GetApi 根据 uuid 查询 mock 定义
+func (h *ApiHandler) GetApi(ctx echo.Context) error {
+	api, err := h.srv.Get(ctx, ctx.Param("uuid"))
+	if err != nil {
+		return err
+	}
+	return successWithApi(ctx, api)
+}
+
+// UpdateApi 整体更新 mock 定义
+func (h *ApiHandler) UpdateApi(ctx echo.Context) error {
+	var payload payloads.MockApiPayload
+	if err := validators.BindAndValidate(ctx, &payload); err != nil {
+		return err
+	}
+	api, err := h.srv.Update(ctx, ctx.Param("uuid"), payload)
+	if err != nil {
+		return err
+	}
+	return successWithApi(ctx, api)
+}
+
+// PatchApi 局部更新 mock 定义
+func (h *ApiHandler) PatchApi(ctx echo.Context) error {
+	var payload payloads.MockApiPatchPayload
+	if err := validators.BindAndValidate(ctx, &payload); err != nil {
+		return err
+	}
+	api, err := h.srv.Patch(ctx, ctx.Param("uuid"), payload)
+	if err != nil {
+		return err
+	}
+	return successWithApi(ctx, api)
+}
+
+// DeleteApi 删除 mock 定义
+func (h *ApiHandler) DeleteApi(ctx echo.Context) error {
+	if err := h.srv.Delete(ctx, ctx.Param("uuid")); err != nil {
+		return err
+	}
+	return response.SuccessWithoutData(ctx)
+}
+
+// CloneApi 复制 mock 定义，可选覆盖 user_id、path 和 method
+func (h *ApiHandler) CloneApi(ctx echo.Context) error {
+	var payload payloads.MockApiClonePayload
+	if err := validators.BindAndValidate(ctx, &payload); err != nil {
+		return err
+	}
+	api, err := h.srv.Clone(ctx, ctx.Param("uuid"), payload)
+	if err != nil {
+		return err
+	}
+	return successWithApi(ctx, api)
+}
+
+// GetApiCalls 查询 mock 被调用的次数
+func (h *ApiHandler) GetApiCalls(ctx echo.Context) error {
+	uuid := ctx.Param("uuid")
+	calls, err := h.srv.Calls(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	return response.Success(ctx, payloads.MockApiCallsResponse{Uuid: uuid, Calls: calls})
+}
+
+// ResetApiCalls 清零 mock 的调用次数
+func (h *ApiHandler) ResetApiCalls(ctx echo.Context) error {
+	if err := h.srv.ResetCalls(ctx, ctx.Param("uuid")); err != nil {
+		return err
+	}
+	return response.SuccessWithoutData(ctx)
+}
+
+func successWithApi(ctx echo.Context, api *models.Api) error {
+	data, err := payloads.NewMockApiResponse(api)
+	if err != nil {
+		return KiteError.New(KiteError.UnmarshalError, err)
+	}
+	return response.Success(ctx, data)
+}
@@ -3,19 +3,33 @@ package mock
 import (
 	"fmt"
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
 	"kite/internal/api/payloads"
 	"kite/internal/api/validators"
 	KiteError "kite/internal/errors"
+	"kite/internal/journal"
+	"kite/internal/models"
+	"kite/internal/proxy"
 	"kite/internal/services"
+	"kite/internal/simulation"
+	KiteLogger "kite/pkg/logger"
 	"kite/pkg/response"
+	"net/http"
+	"strconv"
+	"strings"
 )
 
+// corsMaxAge 默认 CORS 预检响应的缓存时间（秒）
+const corsMaxAge = 86400
+
 type ApiHandler struct {
-	srv services.ApiService
+	srv        services.ApiService
+	namespaces services.NamespaceService
+	proxy      services.ProxyService
 }
 
-func NewApiHandler(srv services.ApiService) *ApiHandler {
-	return &ApiHandler{srv}
+func NewApiHandler(srv services.ApiService, namespaces services.NamespaceService, proxy services.ProxyService) *ApiHandler {
+	return &ApiHandler{srv, namespaces, proxy}
 }
 
 func (h *ApiHandler) Create(ctx echo.Context) error {
@@ -24,66 +38,138 @@ func (h *ApiHandler) Create(ctx echo.Context) error {
 	if err != nil {
 		return err
 	}
-	err = h.srv.Create(ctx, payload)
+	uuid, err := h.srv.Create(ctx, payload)
 	if err != nil {
 		return err
 	}
 
-	return response.SuccessWithoutData(ctx)
+	return response.Success(ctx, map[string]string{"uuid": uuid})
 }
 
-func (h *ApiHandler) Get(ctx echo.Context) error {
+// Serve 所有方法（包括自定义方法）的统一入口：查找匹配的 mock 并原样回放其状态码、响应头和响应体。
+// HEAD 未单独配置时使用 GET 的 mock，OPTIONS 未配置时返回默认的 Allow/CORS 预检响应。
+// 命名空间处于录制模式时请求转发到上游，并录制为新的 mock；配置了回退上游时未命中的请求透传到上游
+func (h *ApiHandler) Serve(ctx echo.Context) error {
 	uid := ctx.Param("uid")
 	path := fmt.Sprintf("/%s", ctx.Param("*"))
-	contentType, responseBody, err := h.srv.Request(ctx, uid, path, "GET")
+	method := strings.ToUpper(ctx.Request().Method)
+	namespace, err := h.namespaces.Get(ctx, uid)
 	if err != nil {
-		return KiteError.New(KiteError.InternalServerError, err)
+		return err
 	}
-	if *contentType == "application/json" {
-		return ctx.JSONBlob(200, []byte(*responseBody))
+	if namespace.Mode == proxy.ModeRecord {
+		return h.record(ctx, namespace, path)
+	}
+	match, err := h.srv.Request(ctx, uid, path, method)
+	if err != nil {
+		if method == http.MethodOptions && isNotMatched(err) {
+			err = h.defaultOptions(ctx, uid, path, err)
+		}
+		if isNotMatched(err) && namespace.FallbackUrl != "" {
+			return h.passthrough(ctx, namespace, path)
+		}
+		return err
 	}
 
-	return response.Success(ctx, responseBody)
+	return h.writeMockResponse(ctx, match)
 }
 
-func (h *ApiHandler) Post(ctx echo.Context) error {
-	uid := ctx.Param("uid")
-	path := fmt.Sprintf("/%s", ctx.Param("*"))
-	contentType, responseBody, err := h.srv.Request(ctx, uid, path, "POST")
+// writeMockResponse 渲染命中的 mock 并写出响应，命中的 uuid 记录到请求日志中
+func (h *ApiHandler) writeMockResponse(ctx echo.Context, match *services.MockMatch) error {
+	ctx.Set(journal.ContextKeyMatchedUuid, match.Api.Uuid)
+	resp, err := h.srv.Render(ctx, match)
 	if err != nil {
-		return KiteError.New(KiteError.InternalServerError, err)
-	}
-	if *contentType == "application/json" {
-		return ctx.JSONBlob(200, []byte(*responseBody))
+		return err
 	}
+	return writeResponse(ctx, resp)
+}
 
-	return response.Success(ctx, responseBody)
+// record 转发请求并录制，上游的响应原样返回给客户端
+func (h *ApiHandler) record(ctx echo.Context, namespace *models.Namespace, path string) error {
+	resp, api, err := h.proxy.Record(ctx, namespace, path)
+	if err != nil {
+		return err
+	}
+	if api != nil {
+		KiteLogger.InfoC(ctx, "Recorded mock", zap.String("uuid", api.Uuid), zap.String("method", api.Method), zap.String("path", api.Path))
+	}
+	return writeResponse(ctx, resp)
 }
 
-func (h *ApiHandler) Put(ctx echo.Context) error {
-	uid := ctx.Param("uid")
-	path := fmt.Sprintf("/%s", ctx.Param("*"))
-	contentType, responseBody, err := h.srv.Request(ctx, uid, path, "PUT")
+// passthrough 将未命中的请求透传到回退上游
+func (h *ApiHandler) passthrough(ctx echo.Context, namespace *models.Namespace, path string) error {
+	resp, err := h.proxy.Passthrough(ctx, namespace, path)
 	if err != nil {
-		return KiteError.New(KiteError.InternalServerError, err)
+		return err
+	}
+	return writeResponse(ctx, resp)
+}
+
+// writeResponse 写出响应，响应体不做任何包装。
+// 配置了延迟时先等待，触发故障时由故障接管响应，配置了限速时按速率分批写出响应体
+func writeResponse(ctx echo.Context, resp *services.MockResponse) error {
+	if err := simulation.Sleep(ctx.Request().Context(), resp.Delay); err != nil {
+		// 客户端已经断开，无需再写出响应
+		return nil
+	}
+	header := ctx.Response().Header()
+	for key, values := range resp.Headers {
+		header.Del(key)
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	if resp.Fault != nil {
+		KiteLogger.InfoC(ctx, "Injecting fault", zap.String("type", resp.Fault.Type))
+		return resp.Fault.Inject(ctx.Request().Context(), ctx.Response(), resp.StatusCode, resp.ContentType, resp.Body)
+	}
+	// HEAD 请求不写出响应体，只声明 GET 时响应体的长度
+	if ctx.Request().Method == http.MethodHead || resp.ThrottleBps > 0 {
+		header.Set(echo.HeaderContentLength, strconv.Itoa(len(resp.Body)))
+	}
+	if ctx.Request().Method == http.MethodHead {
+		header.Set(echo.HeaderContentType, resp.ContentType)
+		return ctx.NoContent(resp.StatusCode)
 	}
-	if *contentType == "application/json" {
-		return ctx.JSONBlob(200, []byte(*responseBody))
+	if resp.ThrottleBps <= 0 {
+		return ctx.Blob(resp.StatusCode, resp.ContentType, resp.Body)
 	}
 
-	return response.Success(ctx, responseBody)
+	header.Set(echo.HeaderContentType, resp.ContentType)
+	ctx.Response().WriteHeader(resp.StatusCode)
+	return simulation.WriteThrottled(ctx.Request().Context(), ctx.Response(), resp.Body, resp.ThrottleBps)
 }
 
-func (h *ApiHandler) Delete(ctx echo.Context) error {
-	uid := ctx.Param("uid")
-	path := fmt.Sprintf("/%s", ctx.Param("*"))
-	contentType, responseBody, err := h.srv.Request(ctx, uid, path, "DELETE")
+// defaultOptions 根据该路径上已配置的方法生成 Allow 响应，CORS 预检请求额外返回 Access-Control-* 响应头
+func (h *ApiHandler) defaultOptions(ctx echo.Context, uid string, path string, notMatched error) error {
+	methods, err := h.srv.AllowedMethods(ctx, uid, path)
 	if err != nil {
-		return KiteError.New(KiteError.InternalServerError, err)
+		return err
 	}
-	if *contentType == "application/json" {
-		return ctx.JSONBlob(200, []byte(*responseBody))
+	if len(methods) == 0 {
+		return notMatched
 	}
+	allow := strings.Join(methods, ", ")
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderAllow, allow)
+
+	req := ctx.Request()
+	origin := req.Header.Get(echo.HeaderOrigin)
+	if origin != "" && req.Header.Get(echo.HeaderAccessControlRequestMethod) != "" {
+		header.Add(echo.HeaderVary, echo.HeaderOrigin)
+		header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+		header.Set(echo.HeaderAccessControlAllowMethods, allow)
+		header.Set(echo.HeaderAccessControlAllowCredentials, "true")
+		header.Set(echo.HeaderAccessControlMaxAge, strconv.Itoa(corsMaxAge))
+		if requestHeaders := req.Header.Get(echo.HeaderAccessControlRequestHeaders); requestHeaders != "" {
+			header.Set(echo.HeaderAccessControlAllowHeaders, requestHeaders)
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
 
-	return response.Success(ctx, responseBody)
+func isNotMatched(err error) bool {
+	appErr, ok := KiteError.IsAppError(err)
+	return ok && appErr.Code == KiteError.MockNotMatchedError
 }
@@ -0,0 +1,200 @@
+package mock
+
+import (
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/handlers"
+	"kite/internal/api/payloads"
+	"kite/internal/configs"
+	"kite/internal/proxy"
+	"kite/internal/repositories"
+	"kite/internal/routing"
+	"kite/internal/services"
+	"kite/internal/state"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type seed struct {
+	method string
+	path   string
+	status int16
+	body   string
+}
+
+// newMockServer 使用内存存储创建命名空间 ns 下的 mock 入口，并创建 mocks 中的 mock
+func newMockServer(t *testing.T, mocks ...seed) *echo.Echo {
+	t.Helper()
+	apis := repositories.NewMemoryApiRepository()
+	namespaceRepo := repositories.NewMemoryNamespaceRepository()
+	routes := routing.NewTable(apis, &configs.RoutingConfig{Enabled: true})
+	namespaces := services.NewNamespaceService(namespaceRepo)
+	workspaces := services.NewWorkspaceService(repositories.NewMemoryWorkspaceRepository(), routes, namespaceRepo)
+	srv := services.NewApiService(routes, routes, namespaces, workspaces, state.NewMemoryStore())
+	h := NewApiHandler(srv, namespaces, services.NewProxyService(routes, proxy.NewForwarder(&configs.ProxyConfig{})))
+
+	e := echo.New()
+	e.HTTPErrorHandler = handlers.CustomHTTPErrorHandler
+	for _, mock := range mocks {
+		payload := payloads.MockApiPayload{
+			UserId: "ns", Method: mock.method, Path: mock.path, StatusCode: mock.status, ContentType: "text/plain", Charset: "utf-8",
+			ResponseHeaders: []payloads.Headers{{Key: "X-Mock", Value: mock.method}}, ResponseBody: mock.body,
+		}
+		ctx := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+		if _, err := srv.Create(ctx, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e.Any("/mock/:uid/*", h.Serve)
+	e.RouteNotFound("/mock/:uid/*", h.Serve)
+	return e
+}
+
+func TestServeMethods(t *testing.T) {
+	e := newMockServer(t,
+		seed{"GET", "/items", 200, "hello"},
+		seed{"POST", "/items", 200, "created"},
+		seed{"PURGE", "/items", 200, "purged"},
+		seed{"GET", "/explicit", 200, "get body"},
+		seed{"HEAD", "/explicit", 202, "ignored"},
+		seed{"OPTIONS", "/custom", 200, "custom options"},
+	)
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		headers     map[string]string
+		wantStatus  int
+		wantBody    string
+		wantHeaders map[string]string
+		noHeaders   []string
+	}{
+		{
+			name: "get", method: http.MethodGet, path: "/items", wantStatus: http.StatusOK, wantBody: "hello",
+			wantHeaders: map[string]string{"X-Mock": "GET"},
+		},
+		{
+			name: "head falls back to get with an empty body and the get length", method: http.MethodHead, path: "/items",
+			wantStatus: http.StatusOK, wantBody: "",
+			wantHeaders: map[string]string{"X-Mock": "GET", "Content-Length": "5", "Content-Type": "text/plain; charset=utf-8"},
+		},
+		{
+			name: "configured head wins over get", method: http.MethodHead, path: "/explicit",
+			wantStatus: http.StatusAccepted, wantBody: "", wantHeaders: map[string]string{"X-Mock": "HEAD", "Content-Length": "7"},
+		},
+		{name: "custom method", method: "PURGE", path: "/items", wantStatus: http.StatusOK, wantBody: "purged"},
+		{name: "unconfigured method", method: http.MethodDelete, path: "/items", wantStatus: http.StatusNotFound},
+		{
+			name: "default options lists the allowed methods", method: http.MethodOptions, path: "/items",
+			wantStatus: http.StatusNoContent, wantHeaders: map[string]string{"Allow": "GET, HEAD, OPTIONS, POST, PURGE"},
+			noHeaders: []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods"},
+		},
+		{
+			name: "default options answers a cors preflight", method: http.MethodOptions, path: "/items",
+			headers: map[string]string{
+				"Origin":                         "https://app.example",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "X-Token, Content-Type",
+			},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Allow":                            "GET, HEAD, OPTIONS, POST, PURGE",
+				"Access-Control-Allow-Origin":      "https://app.example",
+				"Access-Control-Allow-Methods":     "GET, HEAD, OPTIONS, POST, PURGE",
+				"Access-Control-Allow-Headers":     "X-Token, Content-Type",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "86400",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name: "origin without a preflight method is not cors", method: http.MethodOptions, path: "/items",
+			headers: map[string]string{"Origin": "https://app.example"}, wantStatus: http.StatusNoContent,
+			noHeaders: []string{"Access-Control-Allow-Origin"},
+		},
+		{name: "configured options wins", method: http.MethodOptions, path: "/custom", wantStatus: http.StatusOK, wantBody: "custom options"},
+		{name: "options on an unknown path", method: http.MethodOptions, path: "/missing", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/mock/ns"+tt.path, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusNotFound && rec.Body.String() != tt.wantBody {
+				t.Fatalf("body = %q, want %q", rec.Body, tt.wantBody)
+			}
+			for key, want := range tt.wantHeaders {
+				if got := rec.Header().Get(key); got != want {
+					t.Fatalf("header %s = %q, want %q", key, got, want)
+				}
+			}
+			for _, key := range tt.noHeaders {
+				if got := rec.Header().Get(key); got != "" {
+					t.Fatalf("header %s = %q, want none", key, got)
+				}
+			}
+		})
+	}
+}
+
+func TestServeNotMatchedBody(t *testing.T) {
+	e := newMockServer(t, seed{"GET", "/items", 200, "hello"})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/mock/ns/items", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), `"reason":"method_mismatch"`) {
+		t.Fatalf("miss response = %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestWriteResponseHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     http.Header
+		existing    http.Header
+		wantHeaders http.Header
+	}{
+		{
+			name:        "every set-cookie value is written",
+			headers:     http.Header{"Set-Cookie": {"session=abc; Path=/", "theme=dark; Path=/"}},
+			wantHeaders: http.Header{"Set-Cookie": {"session=abc; Path=/", "theme=dark; Path=/"}},
+		},
+		{
+			name:        "response headers replace headers set earlier",
+			headers:     http.Header{"Vary": {"Accept"}},
+			existing:    http.Header{"Vary": {"Origin"}},
+			wantHeaders: http.Header{"Vary": {"Accept"}},
+		},
+		{
+			name:        "headers without a value are not written",
+			headers:     http.Header{"X-Empty": {}},
+			wantHeaders: http.Header{"X-Empty": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			for key, values := range tt.existing {
+				rec.Header()[key] = values
+			}
+			ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/mock/ns/login", nil), rec)
+			resp := &services.MockResponse{StatusCode: http.StatusOK, ContentType: "text/plain", Headers: tt.headers, Body: []byte("ok")}
+			if err := writeResponse(ctx, resp); err != nil {
+				t.Fatalf("writeResponse: %v", err)
+			}
+			for key, want := range tt.wantHeaders {
+				if got := rec.Header().Values(key); !reflect.DeepEqual(got, want) {
+					t.Fatalf("header %s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
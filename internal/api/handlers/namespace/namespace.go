@@ -0,0 +1,91 @@
+package namespace
+
+import (
+	"github.com/labstack/echo/v4"
+	"io"
+	"kite/internal/api/payloads"
+	"kite/internal/api/validators"
+	KiteError "kite/internal/errors"
+	"kite/internal/models"
+	"kite/internal/services"
+	"kite/pkg/response"
+	"net/http"
+	"strings"
+)
+
+type NamespaceHandler struct {
+	srv services.NamespaceService
+}
+
+func NewNamespaceHandler(srv services.NamespaceService) *NamespaceHandler {
+	return &NamespaceHandler{srv}
+}
+
+// Get 查询命名空间配置
+func (h *NamespaceHandler) Get(ctx echo.Context) error {
+	namespace, err := h.srv.Get(ctx, ctx.Param("uid"))
+	if err != nil {
+		return err
+	}
+	return successWithNamespace(ctx, namespace)
+}
+
+// Update 修改命名空间配置
+func (h *NamespaceHandler) Update(ctx echo.Context) error {
+	var payload payloads.NamespacePayload
+	if err := validators.BindAndValidate(ctx, &payload); err != nil {
+		return err
+	}
+	namespace, err := h.srv.Update(ctx, ctx.Param("uid"), payload)
+	if err != nil {
+		return err
+	}
+	return successWithNamespace(ctx, namespace)
+}
+
+// BindOpenApi 请求体为 YAML 或 JSON 格式的 OpenAPI 3 / Swagger 2 文档
+func (h *NamespaceHandler) BindOpenApi(ctx echo.Context) error {
+	data, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return KiteError.NewWithMessage(KiteError.BadRequestError, "Failed to read request body", err)
+	}
+	uid := ctx.Param("uid")
+	spec, err := h.srv.BindOpenApi(ctx, uid, data)
+	if err != nil {
+		return err
+	}
+	return response.Success(ctx, payloads.NewOpenApiBindingResponse(uid, spec))
+}
+
+// GetOpenApi 原样返回绑定的 OpenAPI 文档
+func (h *NamespaceHandler) GetOpenApi(ctx echo.Context) error {
+	uid := ctx.Param("uid")
+	namespace, err := h.srv.Get(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if namespace.OpenApiSpec == "" {
+		return KiteError.New(KiteError.OpenApiNotBoundError, nil).WithDetail(uid)
+	}
+	contentType := "application/yaml"
+	if strings.HasPrefix(strings.TrimSpace(namespace.OpenApiSpec), "{") {
+		contentType = echo.MIMEApplicationJSON
+	}
+	return ctx.Blob(http.StatusOK, contentType, []byte(namespace.OpenApiSpec))
+}
+
+// UnbindOpenApi 解除绑定，之后不再校验请求
+func (h *NamespaceHandler) UnbindOpenApi(ctx echo.Context) error {
+	if err := h.srv.UnbindOpenApi(ctx, ctx.Param("uid")); err != nil {
+		return err
+	}
+	return response.SuccessWithoutData(ctx)
+}
+
+func successWithNamespace(ctx echo.Context, namespace *models.Namespace) error {
+	data, err := payloads.NewNamespaceResponse(namespace)
+	if err != nil {
+		return KiteError.New(KiteError.UnmarshalError, err)
+	}
+	return response.Success(ctx, data)
+}
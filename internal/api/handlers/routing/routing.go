@@ -0,0 +1,26 @@
+package routing
+
+import (
+	"github.com/labstack/echo/v4"
+	"kite/internal/services"
+	"kite/pkg/response"
+)
+
+type RoutingHandler struct {
+	srv services.RoutingService
+}
+
+func NewRoutingHandler(srv services.RoutingService) *RoutingHandler {
+	return &RoutingHandler{srv}
+}
+
+// Metrics 返回路由表的命中统计与缓存情况
+func (h *RoutingHandler) Metrics(ctx echo.Context) error {
+	return response.Success(ctx, h.srv.Metrics(ctx))
+}
+
+// Refresh 使整个路由表失效，用于直接修改了存储而没有经过接口的场景
+func (h *RoutingHandler) Refresh(ctx echo.Context) error {
+	h.srv.Refresh(ctx)
+	return response.SuccessWithoutData(ctx)
+}
@@ -0,0 +1,65 @@
+package scenario
+
+import (
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/api/validators"
+	"kite/internal/services"
+	"kite/pkg/response"
+)
+
+type ScenarioHandler struct {
+	srv services.ScenarioService
+}
+
+func NewScenarioHandler(srv services.ScenarioService) *ScenarioHandler {
+	return &ScenarioHandler{srv}
+}
+
+// List 查询命名空间下全部场景的当前状态
+func (h *ScenarioHandler) List(ctx echo.Context) error {
+	scenarios, err := h.srv.List(ctx, ctx.Param("uid"))
+	if err != nil {
+		return err
+	}
+	return response.Success(ctx, payloads.NewScenarioListResponse(scenarios))
+}
+
+// Get 查询单个场景的当前状态
+func (h *ScenarioHandler) Get(ctx echo.Context) error {
+	name := ctx.Param("name")
+	current, err := h.srv.Get(ctx, ctx.Param("uid"), name)
+	if err != nil {
+		return err
+	}
+	return response.Success(ctx, payloads.ScenarioResponse{Name: name, State: current})
+}
+
+// Set 手动设置场景的当前状态
+func (h *ScenarioHandler) Set(ctx echo.Context) error {
+	var payload payloads.ScenarioStatePayload
+	if err := validators.BindAndValidate(ctx, &payload); err != nil {
+		return err
+	}
+	name := ctx.Param("name")
+	if err := h.srv.Set(ctx, ctx.Param("uid"), name, payload.State); err != nil {
+		return err
+	}
+	return response.Success(ctx, payloads.ScenarioResponse{Name: name, State: payload.State})
+}
+
+// Reset 将单个场景恢复到初始状态
+func (h *ScenarioHandler) Reset(ctx echo.Context) error {
+	if err := h.srv.Reset(ctx, ctx.Param("uid"), ctx.Param("name")); err != nil {
+		return err
+	}
+	return response.SuccessWithoutData(ctx)
+}
+
+// ResetAll 将命名空间下的全部场景恢复到初始状态
+func (h *ScenarioHandler) ResetAll(ctx echo.Context) error {
+	if err := h.srv.ResetAll(ctx, ctx.Param("uid")); err != nil {
+		return err
+	}
+	return response.SuccessWithoutData(ctx)
+}
@@ -0,0 +1,130 @@
+package workspace
+
+import (
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/api/validators"
+	"kite/internal/auth"
+	"kite/internal/configs"
+	KiteError "kite/internal/errors"
+	"kite/internal/services"
+	"kite/internal/tenant"
+	"kite/pkg/response"
+)
+
+// ApiKeyHeader 管理接口携带 API Key 的请求头
+const ApiKeyHeader = "X-Api-Key"
+
+type WorkspaceHandler struct {
+	srv services.WorkspaceService
+	cfg *configs.WorkspaceConfig
+}
+
+func NewWorkspaceHandler(srv services.WorkspaceService, cfg *configs.WorkspaceConfig) *WorkspaceHandler {
+	return &WorkspaceHandler{srv, cfg}
+}
+
+// Get 返回请求所属的工作区及其成员
+func (h *WorkspaceHandler) Get(ctx echo.Context) error {
+	workspace, members, err := h.srv.Current(ctx)
+	if err != nil {
+		return err
+	}
+	return response.Success(ctx, payloads.NewWorkspaceResponse(workspace, members))
+}
+
+// AddMember 添加工作区成员
+func (h *WorkspaceHandler) AddMember(ctx echo.Context) error {
+	var payload payloads.WorkspaceMemberPayload
+	if err := validators.BindAndValidate(ctx, &payload); err != nil {
+		return err
+	}
+	member, err := h.srv.AddMember(ctx, payload)
+	if err != nil {
+		return err
+	}
+	return response.Success(ctx, payloads.NewWorkspaceMemberResponse(member))
+}
+
+// RemoveMember 移除工作区成员
+func (h *WorkspaceHandler) RemoveMember(ctx echo.Context) error {
+	if err := h.srv.RemoveMember(ctx, ctx.Param("user_id")); err != nil {
+		return err
+	}
+	return response.SuccessWithoutData(ctx)
+}
+
+// ListKeys 列出工作区的 API Key，只返回前缀
+func (h *WorkspaceHandler) ListKeys(ctx echo.Context) error {
+	keys, err := h.srv.ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+	items := make([]payloads.ApiKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, payloads.NewApiKeyResponse(key))
+	}
+	return response.Success(ctx, items)
+}
+
+// CreateKey 创建 API Key，明文只在本次响应中返回
+func (h *WorkspaceHandler) CreateKey(ctx echo.Context) error {
+	var payload payloads.ApiKeyPayload
+	if err := validators.BindAndValidate(ctx, &payload); err != nil {
+		return err
+	}
+	key, secret, err := h.srv.CreateKey(ctx, payload)
+	if err != nil {
+		return err
+	}
+	item := payloads.NewApiKeyResponse(key)
+	item.Key = secret
+	return response.Success(ctx, item)
+}
+
+// DeleteKey 吊销 API Key
+func (h *WorkspaceHandler) DeleteKey(ctx echo.Context) error {
+	if err := h.srv.DeleteKey(ctx, ctx.Param("uuid")); err != nil {
+		return err
+	}
+	return response.SuccessWithoutData(ctx)
+}
+
+// Middleware 保护管理接口：携带 API Key（或认证中间件已按 API Key、Token、HMAC 密钥、JWT 声明确定工作区）时将请求限定在该工作区内，
+// 之后的仓储读写都只涉及该工作区；不属于任何工作区的调用方只有 admin 可以访问全部工作区的数据，
+// 未要求 API Key 时尚未创建任何工作区也不做限制。路由中带有 uid 参数时还会检查命名空间是否属于该工作区
+func (h *WorkspaceHandler) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		_, scoped := tenant.Workspace(ctx.Request().Context())
+		key := ctx.Request().Header.Get(ApiKeyHeader)
+		switch {
+		case scoped:
+			// 认证中间件已经通过 API Key 确定了工作区
+		case key != "":
+			workspace, err := h.srv.Authenticate(ctx, key)
+			if err != nil {
+				return err
+			}
+			req := ctx.Request()
+			ctx.SetRequest(req.WithContext(tenant.WithWorkspace(req.Context(), workspace.Uuid)))
+		case h.cfg.RequireApiKey:
+			principal, ok := auth.FromContext(ctx.Request().Context())
+			if !ok {
+				return KiteError.New(KiteError.UnauthorizedError, nil).WithDetail("missing " + ApiKeyHeader + " header")
+			}
+			if !principal.Role.Allows(auth.RoleAdmin) {
+				return KiteError.New(KiteError.ForbiddenError, nil).WithDetail("callers outside a workspace must be admins")
+			}
+		default:
+			if err := h.srv.AuthorizeUnscoped(ctx); err != nil {
+				return err
+			}
+		}
+		if uid := ctx.Param("uid"); uid != "" {
+			if err := h.srv.AuthorizeNamespace(ctx, uid); err != nil {
+				return err
+			}
+		}
+		return next(ctx)
+	}
+}
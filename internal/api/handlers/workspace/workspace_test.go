@@ -0,0 +1,100 @@
+package workspace
+
+import (
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/auth"
+	"kite/internal/configs"
+	KiteError "kite/internal/errors"
+	"kite/internal/repositories"
+	"kite/internal/services"
+	"kite/internal/tenant"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareScope(t *testing.T) {
+	tests := []struct {
+		name           string
+		withWorkspace  bool
+		requireApiKey  bool
+		role           auth.Role
+		principalScope bool
+		sendKey        bool
+		wantCode       KiteError.ErrorCode
+		wantWorkspace  bool
+		wantNextCalled bool
+	}{
+		{name: "no workspaces yet", wantNextCalled: true},
+		{name: "anonymous caller once workspaces exist", withWorkspace: true, wantCode: KiteError.ForbiddenError},
+		{name: "viewer without a workspace", withWorkspace: true, role: auth.RoleViewer, wantCode: KiteError.ForbiddenError},
+		{name: "editor without a workspace", withWorkspace: true, role: auth.RoleEditor, wantCode: KiteError.ForbiddenError},
+		{name: "admin stays unscoped", withWorkspace: true, role: auth.RoleAdmin, wantNextCalled: true},
+		{name: "api key scopes the request", withWorkspace: true, sendKey: true, wantWorkspace: true, wantNextCalled: true},
+		{name: "required api key rejects anonymous callers", withWorkspace: true, requireApiKey: true, wantCode: KiteError.UnauthorizedError},
+		{name: "required api key rejects editors without a workspace", withWorkspace: true, requireApiKey: true, role: auth.RoleEditor, wantCode: KiteError.ForbiddenError},
+		{name: "required api key keeps admins unscoped", withWorkspace: true, requireApiKey: true, role: auth.RoleAdmin, wantNextCalled: true},
+		{name: "required api key accepts a principal mapped to a workspace", withWorkspace: true, requireApiKey: true, role: auth.RoleEditor, principalScope: true, wantWorkspace: true, wantNextCalled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			srv := services.NewWorkspaceService(repositories.NewMemoryWorkspaceRepository(), repositories.NewMemoryApiRepository(), repositories.NewMemoryNamespaceRepository())
+			var key, workspaceId string
+			if tt.withWorkspace {
+				setup := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+				workspace, err := srv.Create(setup, payloads.WorkspacePayload{Name: "team"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				workspaceId = workspace.Uuid
+				setup.SetRequest(setup.Request().WithContext(tenant.WithWorkspace(setup.Request().Context(), workspaceId)))
+				if _, key, err = srv.CreateKey(setup, payloads.ApiKeyPayload{Name: "ci"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			h := NewWorkspaceHandler(srv, &configs.WorkspaceConfig{RequireApiKey: tt.requireApiKey})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/apis", nil)
+			if tt.role != "" {
+				principal := &auth.Principal{Subject: "someone", Role: tt.role, Method: auth.MethodToken}
+				reqCtx := req.Context()
+				// 与认证中间件一致，调用方属于工作区时请求同时限定在该工作区内
+				if tt.principalScope {
+					principal.WorkspaceId = workspaceId
+					reqCtx = tenant.WithWorkspace(reqCtx, workspaceId)
+				}
+				req = req.WithContext(auth.WithPrincipal(reqCtx, principal))
+			}
+			if tt.sendKey {
+				req.Header.Set(ApiKeyHeader, key)
+			}
+			ctx := e.NewContext(req, httptest.NewRecorder())
+			called := false
+			var scopedTo string
+			err := h.Middleware(func(ctx echo.Context) error {
+				called = true
+				scopedTo, _ = tenant.Workspace(ctx.Request().Context())
+				return nil
+			})(ctx)
+
+			if called != tt.wantNextCalled {
+				t.Fatalf("next called = %v, want %v (err %v)", called, tt.wantNextCalled, err)
+			}
+			if tt.wantCode != 0 {
+				appErr, ok := KiteError.IsAppError(err)
+				if !ok || appErr.Code != tt.wantCode {
+					t.Fatalf("err = %v, want code %v", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantWorkspace && scopedTo != workspaceId || !tt.wantWorkspace && scopedTo != "" {
+				t.Fatalf("request scoped to %q, want workspace=%v", scopedTo, tt.wantWorkspace)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package payloads
+
+import "kite/internal/auth"
+
+// PrincipalResponse 当前调用方的身份
+type PrincipalResponse struct {
+	Subject     string `json:"subject"`
+	Role        string `json:"role"`
+	Method      string `json:"method"`
+	WorkspaceId string `json:"workspace_id,omitempty"`
+}
+
+func NewPrincipalResponse(principal *auth.Principal) *PrincipalResponse {
+	return &PrincipalResponse{
+		Subject:     principal.Subject,
+		Role:        string(principal.Role),
+		Method:      principal.Method,
+		WorkspaceId: principal.WorkspaceId,
+	}
+}
@@ -0,0 +1,81 @@
+package payloads
+
+// BundleExportQuery 导出条件，命名空间和标签至少指定一个
+type BundleExportQuery struct {
+	UserId string `json:"user_id" query:"user_id"`
+	Tag    string `json:"tag" query:"tag"`
+	Format string `json:"format" query:"format" validate:"omitempty,oneof=json yaml"`
+}
+
+// 导入 bundle 时与已有 mock 冲突的处理方式
+const (
+	// ConflictSkip 保留已有的 mock，跳过 bundle 中的条目
+	ConflictSkip = "skip"
+	// ConflictOverwrite 用 bundle 中的条目覆盖已有的 mock，uuid 保持不变
+	ConflictOverwrite = "overwrite"
+	// ConflictRename 以新的 uuid 创建 mock，与已有的 mock 共存
+	ConflictRename = "rename"
+)
+
+// BundleImportQuery 导入选项，指定 user_id 时所有 mock 导入到该命名空间，否则使用 bundle 中记录的命名空间
+type BundleImportQuery struct {
+	UserId   string `json:"user_id" query:"user_id"`
+	Strategy string `json:"strategy" query:"strategy" validate:"omitempty,oneof=skip overwrite rename"`
+	DryRun   bool   `json:"dry_run" query:"dry_run"`
+}
+
+// Normalize 默认跳过冲突的条目
+func (q *BundleImportQuery) Normalize() {
+	if q.Strategy == "" {
+		q.Strategy = ConflictSkip
+	}
+}
+
+// BundleImportItem bundle 中一个条目的导入结果
+type BundleImportItem struct {
+	Uuid   string `json:"uuid"`
+	UserId string `json:"user_id"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Action 为 created、overwritten、renamed 或 skipped
+	Action string `json:"action"`
+	// ConflictUuid 发生冲突的已有 mock
+	ConflictUuid string `json:"conflict_uuid,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+type BundleImportResponse struct {
+	DryRun        bool               `json:"dry_run"`
+	SchemaVersion int                `json:"schema_version"`
+	Created       int                `json:"created"`
+	Overwritten   int                `json:"overwritten"`
+	Renamed       int                `json:"renamed"`
+	Skipped       int                `json:"skipped"`
+	Items         []BundleImportItem `json:"items"`
+}
+
+// 导入结果中的 action
+const (
+	BundleActionCreated     = "created"
+	BundleActionOverwritten = "overwritten"
+	BundleActionRenamed     = "renamed"
+	BundleActionSkipped     = "skipped"
+)
+
+// NewBundleImportResponse 汇总各条目的导入结果
+func NewBundleImportResponse(items []BundleImportItem, schemaVersion int, dryRun bool) *BundleImportResponse {
+	resp := &BundleImportResponse{DryRun: dryRun, SchemaVersion: schemaVersion, Items: items}
+	for _, item := range items {
+		switch item.Action {
+		case BundleActionCreated:
+			resp.Created++
+		case BundleActionOverwritten:
+			resp.Overwritten++
+		case BundleActionRenamed:
+			resp.Renamed++
+		case BundleActionSkipped:
+			resp.Skipped++
+		}
+	}
+	return resp
+}
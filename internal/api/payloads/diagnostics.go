@@ -0,0 +1,19 @@
+package payloads
+
+// MockCandidate 未命中时与请求最接近的 mock
+type MockCandidate struct {
+	Uuid     string `json:"uuid"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Reason   string `json:"reason"`
+	Detail   string `json:"detail,omitempty"`
+	Distance int    `json:"distance"`
+}
+
+// MockMissDetails 未命中任何 mock 时返回的诊断信息
+type MockMissDetails struct {
+	Uid        string          `json:"uid"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	Candidates []MockCandidate `json:"candidates"`
+}
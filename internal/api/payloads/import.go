@@ -0,0 +1,46 @@
+package payloads
+
+import (
+	"kite/internal/importers"
+	"kite/internal/models"
+)
+
+// ImportedMock 导入生成的一个 mock 的摘要
+type ImportedMock struct {
+	Uuid       string `json:"uuid"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	StatusCode int16  `json:"status_code"`
+}
+
+// SkippedImport 导入时被跳过的条目
+type SkippedImport struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type ImportResponse struct {
+	DryRun  bool            `json:"dry_run"`
+	Created int             `json:"created"`
+	Items   []ImportedMock  `json:"items"`
+	Skipped []SkippedImport `json:"skipped"`
+}
+
+// NewImportResponse 将导入生成的 mock 转换为接口输出格式，预览时 created 为将要创建的数量
+func NewImportResponse(apis []*models.Api, skipped []importers.Skipped, dryRun bool) *ImportResponse {
+	items := make([]ImportedMock, 0, len(apis))
+	for _, api := range apis {
+		items = append(items, ImportedMock{
+			Uuid:       api.Uuid,
+			Method:     api.Method,
+			Path:       api.Path,
+			StatusCode: api.StatusCode,
+		})
+	}
+	skips := make([]SkippedImport, 0, len(skipped))
+	for _, skip := range skipped {
+		skips = append(skips, SkippedImport{Method: skip.Method, Path: skip.Path, Reason: skip.Reason})
+	}
+	return &ImportResponse{DryRun: dryRun, Created: len(items), Items: items, Skipped: skips}
+}
@@ -0,0 +1,56 @@
+package payloads
+
+import (
+	"kite/internal/journal"
+	"strings"
+	"time"
+)
+
+// JournalQuery 请求日志查询参数，matched 为 true/false 时只返回命中/未命中 mock 的请求
+type JournalQuery struct {
+	Method      string    `json:"method" query:"method"`
+	PathPrefix  string    `json:"path_prefix" query:"path_prefix"`
+	StatusCode  int       `json:"status_code" query:"status_code" validate:"omitempty,gte=100,lte=599"`
+	MatchedUuid string    `json:"matched_uuid" query:"matched_uuid"`
+	Matched     *bool     `json:"matched" query:"matched"`
+	RequestId   string    `json:"request_id" query:"request_id"`
+	Since       time.Time `json:"since" query:"since"`
+	Until       time.Time `json:"until" query:"until"`
+	Page        int       `json:"page" query:"page" validate:"gte=0"`
+	PageSize    int       `json:"page_size" query:"page_size" validate:"gte=0,lte=100"`
+}
+
+// Normalize 填充分页默认值
+func (q *JournalQuery) Normalize() {
+	if q.Page <= 0 {
+		q.Page = DefaultPage
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = DefaultPageSize
+	}
+	q.Method = strings.ToUpper(q.Method)
+}
+
+// Filter 转换为存储层的查询条件
+func (q *JournalQuery) Filter(uid string) journal.Filter {
+	return journal.Filter{
+		Uid:         uid,
+		Method:      q.Method,
+		PathPrefix:  q.PathPrefix,
+		StatusCode:  q.StatusCode,
+		MatchedUuid: q.MatchedUuid,
+		Matched:     q.Matched,
+		RequestId:   q.RequestId,
+		Since:       q.Since,
+		Until:       q.Until,
+		Offset:      (q.Page - 1) * q.PageSize,
+		Limit:       q.PageSize,
+	}
+}
+
+type JournalListResponse struct {
+	Items    []*journal.Entry `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
@@ -2,6 +2,13 @@ package payloads
 
 import (
 	"encoding/json"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/sequence"
+	"kite/internal/simulation"
+	"sort"
+	"strings"
+	"time"
 )
 
 type Headers struct {
@@ -10,22 +17,385 @@ type Headers struct {
 }
 
 type MockApiPayload struct {
-	UserId          string    `json:"user_id" validate:"required"`
-	Path            string    `json:"path" validate:"required"`
-	Method          string    `json:"method" validate:"required"`
-	StatusCode      int16     `json:"status_code" validate:"required"`
-	ContentType     string    `json:"content_type" validate:"required"`
-	Charset         string    `json:"charset" validate:"required"`
-	ResponseHeaders []Headers `json:"headers" validate:"required"`
-	ResponseBody    string    `json:"response_body" validate:"required"`
+	UserId          string                    `json:"user_id" validate:"required"`
+	Path            string                    `json:"path" validate:"required"`
+	Method          string                    `json:"method" validate:"required"`
+	StatusCode      int16                     `json:"status_code" validate:"required,gte=100,lte=599"`
+	ContentType     string                    `json:"content_type" validate:"required"`
+	Charset         string                    `json:"charset" validate:"required"`
+	ResponseHeaders []Headers                 `json:"headers" validate:"required"`
+	ResponseBody    string                    `json:"response_body" validate:"required"`
+	RequestMatchers *matching.RequestMatchers `json:"matchers" validate:"omitempty"`
+	Priority        int                       `json:"priority"`
+	Template        bool                      `json:"template"`
+	Delay           *simulation.Delay         `json:"delay" validate:"omitempty"`
+	ThrottleBps     int                       `json:"throttle_bps" validate:"gte=0"`
+	Fault           *simulation.Fault         `json:"fault" validate:"omitempty"`
+	Responses       []sequence.Step           `json:"responses" validate:"omitempty,dive"`
+	SequenceMode    string                    `json:"sequence_mode" validate:"omitempty,oneof=cycle stick fail"`
+	ScenarioName    string                    `json:"scenario_name"`
+	RequiredState   string                    `json:"required_state"`
+	NewState        string                    `json:"new_state"`
+	Tags            []string                  `json:"tags" validate:"omitempty,dive,min=1,max=64"`
 }
 
 // GetHeadersJSON 将 ResponseHeaders 转换为指定格式的 JSON
 func (m *MockApiPayload) GetHeadersJSON() (json.RawMessage, error) {
+	return headersToJSON(m.ResponseHeaders)
+}
+
+// ApplyTo 将请求数据写入模型，用于创建和整体更新
+func (m *MockApiPayload) ApplyTo(api *models.Api) error {
+	headers, err := m.GetHeadersJSON()
+	if err != nil {
+		return err
+	}
+	api.UserId = m.UserId
+	api.Path = m.Path
+	api.Method = strings.ToUpper(m.Method)
+	api.StatusCode = m.StatusCode
+	api.ContentType = m.ContentType
+	api.Charset = m.Charset
+	api.Headers = headers
+	api.ResponseBody = m.ResponseBody
+	matchers, err := matchersToJSON(m.RequestMatchers)
+	if err != nil {
+		return err
+	}
+	api.RequestMatchers = matchers
+	api.Priority = m.Priority
+	api.Template = m.Template
+	delay, err := delayToJSON(m.Delay)
+	if err != nil {
+		return err
+	}
+	api.Delay = delay
+	api.ThrottleBps = m.ThrottleBps
+	fault, err := faultToJSON(m.Fault)
+	if err != nil {
+		return err
+	}
+	api.Fault = fault
+	responses, err := stepsToJSON(m.Responses)
+	if err != nil {
+		return err
+	}
+	api.Responses = responses
+	api.SequenceMode = m.SequenceMode
+	api.ScenarioName = m.ScenarioName
+	api.RequiredState = m.RequiredState
+	api.NewState = m.NewState
+	tags, err := tagsToJSON(m.Tags)
+	if err != nil {
+		return err
+	}
+	api.Tags = tags
+	return nil
+}
+
+// MockApiPatchPayload 局部更新，只修改请求中出现的字段
+type MockApiPatchPayload struct {
+	UserId          *string                    `json:"user_id" validate:"omitempty,min=1"`
+	Path            *string                    `json:"path" validate:"omitempty,min=1"`
+	Method          *string                    `json:"method" validate:"omitempty,min=1"`
+	StatusCode      *int16                     `json:"status_code" validate:"omitempty,gte=100,lte=599"`
+	ContentType     *string                    `json:"content_type" validate:"omitempty,min=1"`
+	Charset         *string                    `json:"charset"`
+	ResponseHeaders *[]Headers                 `json:"headers"`
+	ResponseBody    *string                    `json:"response_body"`
+	RequestMatchers *matching.RequestMatchers  `json:"matchers" validate:"omitempty"`
+	Priority        *int                       `json:"priority"`
+	Template        *bool                      `json:"template"`
+	Delay           Nullable[simulation.Delay] `json:"delay"`
+	ThrottleBps     *int                       `json:"throttle_bps" validate:"omitempty,gte=0"`
+	Fault           Nullable[simulation.Fault] `json:"fault"`
+	Responses       *[]sequence.Step           `json:"responses" validate:"omitempty,dive"`
+	SequenceMode    *string                    `json:"sequence_mode" validate:"omitempty,oneof=cycle stick fail"`
+	ScenarioName    *string                    `json:"scenario_name"`
+	RequiredState   *string                    `json:"required_state"`
+	NewState        *string                    `json:"new_state"`
+	Tags            *[]string                  `json:"tags" validate:"omitempty,dive,min=1,max=64"`
+}
+
+// ApplyTo 将出现的字段写入模型
+func (m *MockApiPatchPayload) ApplyTo(api *models.Api) error {
+	if m.UserId != nil {
+		api.UserId = *m.UserId
+	}
+	if m.Path != nil {
+		api.Path = *m.Path
+	}
+	if m.Method != nil {
+		api.Method = strings.ToUpper(*m.Method)
+	}
+	if m.StatusCode != nil {
+		api.StatusCode = *m.StatusCode
+	}
+	if m.ContentType != nil {
+		api.ContentType = *m.ContentType
+	}
+	if m.Charset != nil {
+		api.Charset = *m.Charset
+	}
+	if m.ResponseHeaders != nil {
+		headers, err := headersToJSON(*m.ResponseHeaders)
+		if err != nil {
+			return err
+		}
+		api.Headers = headers
+	}
+	if m.ResponseBody != nil {
+		api.ResponseBody = *m.ResponseBody
+	}
+	if m.RequestMatchers != nil {
+		matchers, err := matchersToJSON(m.RequestMatchers)
+		if err != nil {
+			return err
+		}
+		api.RequestMatchers = matchers
+	}
+	if m.Priority != nil {
+		api.Priority = *m.Priority
+	}
+	if m.Template != nil {
+		api.Template = *m.Template
+	}
+	if m.Delay.Set {
+		delay, err := delayToJSON(m.Delay.Value)
+		if err != nil {
+			return err
+		}
+		api.Delay = delay
+	}
+	if m.ThrottleBps != nil {
+		api.ThrottleBps = *m.ThrottleBps
+	}
+	if m.Fault.Set {
+		fault, err := faultToJSON(m.Fault.Value)
+		if err != nil {
+			return err
+		}
+		api.Fault = fault
+	}
+	if m.Responses != nil {
+		responses, err := stepsToJSON(*m.Responses)
+		if err != nil {
+			return err
+		}
+		api.Responses = responses
+	}
+	if m.SequenceMode != nil {
+		api.SequenceMode = *m.SequenceMode
+	}
+	if m.ScenarioName != nil {
+		api.ScenarioName = *m.ScenarioName
+	}
+	if m.RequiredState != nil {
+		api.RequiredState = *m.RequiredState
+	}
+	if m.NewState != nil {
+		api.NewState = *m.NewState
+	}
+	if m.Tags != nil {
+		tags, err := tagsToJSON(*m.Tags)
+		if err != nil {
+			return err
+		}
+		api.Tags = tags
+	}
+	return nil
+}
+
+// MockApiClonePayload 复制 mock 时可选覆盖的字段
+type MockApiClonePayload struct {
+	UserId *string `json:"user_id" validate:"omitempty,min=1"`
+	Path   *string `json:"path" validate:"omitempty,min=1"`
+	Method *string `json:"method" validate:"omitempty,min=1"`
+}
+
+// MockApiQuery 列表查询参数
+type MockApiQuery struct {
+	UserId     string `query:"user_id"`
+	Method     string `query:"method"`
+	PathPrefix string `query:"path_prefix"`
+	Tag        string `query:"tag"`
+	Page       int    `query:"page" validate:"gte=0"`
+	PageSize   int    `query:"page_size" validate:"gte=0,lte=100"`
+}
+
+const (
+	DefaultPage     = 1
+	DefaultPageSize = 20
+)
+
+// Normalize 填充分页默认值
+func (q *MockApiQuery) Normalize() {
+	if q.Page <= 0 {
+		q.Page = DefaultPage
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = DefaultPageSize
+	}
+	q.Method = strings.ToUpper(q.Method)
+}
+
+// Offset 返回分页偏移量
+func (q *MockApiQuery) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+type MockApiResponse struct {
+	Uuid            string                    `json:"uuid"`
+	UserId          string                    `json:"user_id"`
+	Path            string                    `json:"path"`
+	Method          string                    `json:"method"`
+	StatusCode      int16                     `json:"status_code"`
+	ContentType     string                    `json:"content_type"`
+	Charset         string                    `json:"charset"`
+	ResponseHeaders []Headers                 `json:"headers"`
+	ResponseBody    string                    `json:"response_body"`
+	RequestMatchers *matching.RequestMatchers `json:"matchers,omitempty"`
+	Priority        int                       `json:"priority"`
+	Template        bool                      `json:"template"`
+	Delay           *simulation.Delay         `json:"delay,omitempty"`
+	ThrottleBps     int                       `json:"throttle_bps"`
+	Fault           *simulation.Fault         `json:"fault,omitempty"`
+	Responses       []sequence.Step           `json:"responses,omitempty"`
+	SequenceMode    string                    `json:"sequence_mode,omitempty"`
+	ScenarioName    string                    `json:"scenario_name,omitempty"`
+	RequiredState   string                    `json:"required_state,omitempty"`
+	NewState        string                    `json:"new_state,omitempty"`
+	Tags            []string                  `json:"tags"`
+	CreatedAt       time.Time                 `json:"created_at"`
+	UpdatedAt       time.Time                 `json:"updated_at"`
+}
+
+// NewMockApiResponse 将模型转换为接口输出格式
+func NewMockApiResponse(api *models.Api) (*MockApiResponse, error) {
+	headersMap, err := api.GetHeaders()
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]Headers, 0, len(headersMap))
+	for key, value := range headersMap {
+		headers = append(headers, Headers{Key: key, Value: value})
+	}
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Key < headers[j].Key
+	})
+	matchers, err := matching.DecodeRequestMatchers(api.RequestMatchers)
+	if err != nil {
+		return nil, err
+	}
+	delay, err := simulation.DecodeDelay(api.Delay)
+	if err != nil {
+		return nil, err
+	}
+	fault, err := simulation.DecodeFault(api.Fault)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := sequence.DecodeSteps(api.Responses)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := api.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	return &MockApiResponse{
+		Uuid:            api.Uuid,
+		UserId:          api.UserId,
+		Path:            api.Path,
+		Method:          api.Method,
+		StatusCode:      api.StatusCode,
+		ContentType:     api.ContentType,
+		Charset:         api.Charset,
+		ResponseHeaders: headers,
+		ResponseBody:    api.ResponseBody,
+		RequestMatchers: matchers,
+		Priority:        api.Priority,
+		Template:        api.Template,
+		Delay:           delay,
+		ThrottleBps:     api.ThrottleBps,
+		Fault:           fault,
+		Responses:       responses,
+		SequenceMode:    api.SequenceMode,
+		ScenarioName:    api.ScenarioName,
+		RequiredState:   api.RequiredState,
+		NewState:        api.NewState,
+		Tags:            tags,
+		CreatedAt:       api.CreatedAt,
+		UpdatedAt:       api.UpdatedAt,
+	}, nil
+}
+
+type MockApiCallsResponse struct {
+	Uuid  string `json:"uuid"`
+	Calls int64  `json:"calls"`
+}
+
+type MockApiListResponse struct {
+	Items    []*MockApiResponse `json:"items"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
+
+func headersToJSON(headers []Headers) (json.RawMessage, error) {
 	headersMap := make(map[string]string)
 
-	for _, header := range m.ResponseHeaders {
+	for _, header := range headers {
 		headersMap[header.Key] = header.Value
 	}
 	return json.Marshal(headersMap)
 }
+
+// matchersToJSON 未配置匹配条件时存储为空
+func matchersToJSON(matchers *matching.RequestMatchers) (json.RawMessage, error) {
+	if matchers == nil || matchers.Count() == 0 {
+		return nil, nil
+	}
+	return json.Marshal(matchers)
+}
+
+// delayToJSON 未配置延迟时存储为空，传入 null 或空对象可以清除延迟
+func delayToJSON(delay *simulation.Delay) (json.RawMessage, error) {
+	if delay.IsZero() {
+		return nil, nil
+	}
+	return json.Marshal(delay)
+}
+
+// faultToJSON 未配置故障时存储为空，传入 null 或空对象可以清除故障
+func faultToJSON(fault *simulation.Fault) (json.RawMessage, error) {
+	if fault.IsZero() {
+		return nil, nil
+	}
+	return json.Marshal(fault)
+}
+
+// stepsToJSON 未配置响应序列时存储为空
+func stepsToJSON(steps []sequence.Step) (json.RawMessage, error) {
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(steps)
+}
+
+// tagsToJSON 去掉重复的标签，未配置标签时存储为空
+func tagsToJSON(tags []string) (json.RawMessage, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(tags))
+	unique := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	return json.Marshal(unique)
+}
@@ -0,0 +1,93 @@
+package payloads
+
+import (
+	"encoding/json"
+	"kite/internal/api/validators"
+	"kite/internal/models"
+	"testing"
+)
+
+func TestMockApiPatchPayloadDelay(t *testing.T) {
+	stored := json.RawMessage(`{"distribution":"fixed","fixed_ms":100}`)
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		invalid bool
+	}{
+		{name: "absent keeps the delay", body: `{}`, want: string(stored)},
+		{name: "null clears the delay", body: `{"delay":null}`, want: ""},
+		{name: "empty object clears the delay", body: `{"delay":{}}`, want: ""},
+		{name: "new delay replaces the old one", body: `{"delay":{"distribution":"uniform","min_ms":1,"max_ms":5}}`, want: `{"distribution":"uniform","min_ms":1,"max_ms":5}`},
+		{name: "unknown distribution is rejected", body: `{"delay":{"distribution":"slow"}}`, invalid: true},
+		{name: "negative value is rejected", body: `{"delay":{"distribution":"fixed","fixed_ms":-1}}`, invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload MockApiPatchPayload
+			if err := json.Unmarshal([]byte(tt.body), &payload); err != nil {
+				t.Fatal(err)
+			}
+			err := validators.NewCustomValidator().Validate(&payload)
+			if tt.invalid {
+				if err == nil {
+					t.Fatalf("Validate should reject %s", tt.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			api := &models.Api{Delay: stored}
+			if err := payload.ApplyTo(api); err != nil {
+				t.Fatal(err)
+			}
+			if string(api.Delay) != tt.want {
+				t.Fatalf("Delay = %s, want %s", api.Delay, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockApiPatchPayloadFault(t *testing.T) {
+	stored := json.RawMessage(`{"type":"hang"}`)
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		invalid bool
+	}{
+		{name: "absent keeps the fault", body: `{}`, want: string(stored)},
+		{name: "null clears the fault", body: `{"fault":null}`, want: ""},
+		{name: "empty object clears the fault", body: `{"fault":{}}`, want: ""},
+		{name: "empty type clears the fault", body: `{"fault":{"type":""}}`, want: ""},
+		{name: "new fault replaces the old one", body: `{"fault":{"type":"error_status","percentage":50,"status_code":503}}`, want: `{"type":"error_status","percentage":50,"status_code":503}`},
+		{name: "unknown type is rejected", body: `{"fault":{"type":"explode"}}`, invalid: true},
+		{name: "percentage above 100 is rejected", body: `{"fault":{"type":"hang","percentage":150}}`, invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload MockApiPatchPayload
+			if err := json.Unmarshal([]byte(tt.body), &payload); err != nil {
+				t.Fatal(err)
+			}
+			err := validators.NewCustomValidator().Validate(&payload)
+			if tt.invalid {
+				if err == nil {
+					t.Fatalf("Validate should reject %s", tt.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			api := &models.Api{Fault: stored}
+			if err := payload.ApplyTo(api); err != nil {
+				t.Fatal(err)
+			}
+			if string(api.Fault) != tt.want {
+				t.Fatalf("Fault = %s, want %s", api.Fault, tt.want)
+			}
+		})
+	}
+}
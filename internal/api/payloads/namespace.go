@@ -0,0 +1,114 @@
+package payloads
+
+import (
+	"encoding/json"
+	"kite/internal/models"
+	"kite/internal/proxy"
+	"kite/internal/simulation"
+	"net/http"
+	"time"
+)
+
+// NamespacePayload 整体替换命名空间配置，未提供的字段恢复为默认值
+type NamespacePayload struct {
+	NotFoundStatus int                 `json:"not_found_status" validate:"omitempty,gte=100,lte=599"`
+	Delay          *simulation.Delay   `json:"delay" validate:"omitempty"`
+	ThrottleBps    int                 `json:"throttle_bps" validate:"gte=0"`
+	Mode           string              `json:"mode" validate:"omitempty,oneof=mock record"`
+	UpstreamUrl    string              `json:"upstream_url" validate:"required_if=Mode record,omitempty,url"`
+	Record         *proxy.RecordConfig `json:"record" validate:"omitempty"`
+	FallbackUrl    string              `json:"fallback_url" validate:"omitempty,url"`
+	Rewrites       []proxy.RewriteRule `json:"rewrites" validate:"dive"`
+}
+
+// ApplyTo 将请求数据写入模型
+func (n *NamespacePayload) ApplyTo(namespace *models.Namespace) error {
+	namespace.NotFoundStatus = n.NotFoundStatus
+	if namespace.NotFoundStatus == 0 {
+		namespace.NotFoundStatus = http.StatusNotFound
+	}
+	delay, err := delayToJSON(n.Delay)
+	if err != nil {
+		return err
+	}
+	namespace.Delay = delay
+	namespace.ThrottleBps = n.ThrottleBps
+	namespace.Mode = n.Mode
+	if namespace.Mode == "" {
+		namespace.Mode = proxy.ModeMock
+	}
+	namespace.UpstreamUrl = n.UpstreamUrl
+	record, err := recordConfigToJSON(n.Record)
+	if err != nil {
+		return err
+	}
+	namespace.RecordConfig = record
+	namespace.FallbackUrl = n.FallbackUrl
+	rewrites, err := rewritesToJSON(n.Rewrites)
+	if err != nil {
+		return err
+	}
+	namespace.RewriteRules = rewrites
+	return nil
+}
+
+type NamespaceResponse struct {
+	Uid            string              `json:"uid"`
+	NotFoundStatus int                 `json:"not_found_status"`
+	Delay          *simulation.Delay   `json:"delay,omitempty"`
+	ThrottleBps    int                 `json:"throttle_bps"`
+	Mode           string              `json:"mode"`
+	UpstreamUrl    string              `json:"upstream_url,omitempty"`
+	Record         *proxy.RecordConfig `json:"record,omitempty"`
+	FallbackUrl    string              `json:"fallback_url,omitempty"`
+	Rewrites       []proxy.RewriteRule `json:"rewrites,omitempty"`
+	OpenApiBound   bool                `json:"openapi_bound"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// NewNamespaceResponse 将模型转换为接口输出格式
+func NewNamespaceResponse(namespace *models.Namespace) (*NamespaceResponse, error) {
+	delay, err := simulation.DecodeDelay(namespace.Delay)
+	if err != nil {
+		return nil, err
+	}
+	record, err := proxy.DecodeRecordConfig(namespace.RecordConfig)
+	if err != nil {
+		return nil, err
+	}
+	rewrites, err := proxy.DecodeRewriteRules(namespace.RewriteRules)
+	if err != nil {
+		return nil, err
+	}
+	return &NamespaceResponse{
+		Uid:            namespace.Uid,
+		NotFoundStatus: namespace.NotFoundStatus,
+		Delay:          delay,
+		ThrottleBps:    namespace.ThrottleBps,
+		Mode:           namespace.Mode,
+		UpstreamUrl:    namespace.UpstreamUrl,
+		Record:         record,
+		FallbackUrl:    namespace.FallbackUrl,
+		Rewrites:       rewrites,
+		OpenApiBound:   namespace.OpenApiSpec != "",
+		CreatedAt:      namespace.CreatedAt,
+		UpdatedAt:      namespace.UpdatedAt,
+	}, nil
+}
+
+// recordConfigToJSON 未配置录制规则时存储为空
+func recordConfigToJSON(config *proxy.RecordConfig) (json.RawMessage, error) {
+	if config == nil {
+		return nil, nil
+	}
+	return json.Marshal(config)
+}
+
+// rewritesToJSON 没有改写规则时存储为空
+func rewritesToJSON(rules []proxy.RewriteRule) (json.RawMessage, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(rules)
+}
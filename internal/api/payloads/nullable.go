@@ -0,0 +1,25 @@
+package payloads
+
+import "encoding/json"
+
+// Nullable 局部更新中可以显式置空的字段。
+// 字段缺省时 Set 为 false；传入 null 时 Set 为 true、Value 为 nil，表示清除
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// UnmarshalJSON 记录字段出现过，null 解析为空值
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	n.Value = &value
+	return nil
+}
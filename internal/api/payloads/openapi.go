@@ -0,0 +1,34 @@
+package payloads
+
+import "kite/internal/openapi"
+
+// OpenApiBindingResponse 绑定到命名空间的 OpenAPI 文档摘要
+type OpenApiBindingResponse struct {
+	Uid        string `json:"uid"`
+	Title      string `json:"title"`
+	Version    string `json:"version"`
+	Operations int    `json:"operations"`
+}
+
+// NewOpenApiBindingResponse 将解析后的规范转换为接口输出格式
+func NewOpenApiBindingResponse(uid string, spec *openapi.Spec) *OpenApiBindingResponse {
+	return &OpenApiBindingResponse{
+		Uid:        uid,
+		Title:      spec.Title,
+		Version:    spec.Version,
+		Operations: len(spec.Operations),
+	}
+}
+
+// RequestViolation 请求中不符合 OpenAPI 文档的一处
+type RequestViolation struct {
+	Location string `json:"location"`
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+}
+
+// RequestValidationDetails 请求校验失败时 ErrorResponse 的 details
+type RequestValidationDetails struct {
+	Operation  string             `json:"operation,omitempty"`
+	Violations []RequestViolation `json:"violations"`
+}
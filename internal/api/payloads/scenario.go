@@ -0,0 +1,25 @@
+package payloads
+
+import "sort"
+
+// ScenarioStatePayload 手动设置场景的当前状态
+type ScenarioStatePayload struct {
+	State string `json:"state" validate:"required"`
+}
+
+type ScenarioResponse struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// NewScenarioListResponse 将场景状态转换为按名称排序的列表
+func NewScenarioListResponse(scenarios map[string]string) []ScenarioResponse {
+	list := make([]ScenarioResponse, 0, len(scenarios))
+	for name, state := range scenarios {
+		list = append(list, ScenarioResponse{Name: name, State: state})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
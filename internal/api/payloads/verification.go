@@ -0,0 +1,82 @@
+package payloads
+
+import (
+	"fmt"
+	"kite/internal/journal"
+	"kite/internal/matching"
+	"strings"
+	"time"
+)
+
+// 调用次数约束
+const (
+	CountExactly = "exactly"
+	CountAtLeast = "at_least"
+	CountAtMost  = "at_most"
+	CountNever   = "never"
+)
+
+// CountConstraint 期望的调用次数，never 不需要 value
+type CountConstraint struct {
+	Type  string `json:"type" validate:"required,oneof=exactly at_least at_most never"`
+	Value int    `json:"value" validate:"gte=0"`
+}
+
+// Check 判断实际次数是否满足约束
+func (c *CountConstraint) Check(actual int) bool {
+	switch c.Type {
+	case CountExactly:
+		return actual == c.Value
+	case CountAtLeast:
+		return actual >= c.Value
+	case CountAtMost:
+		return actual <= c.Value
+	case CountNever:
+		return actual == 0
+	}
+	return false
+}
+
+// String 用于响应中描述期望，例如 exactly 2
+func (c *CountConstraint) String() string {
+	if c.Type == CountNever {
+		return CountNever
+	}
+	return fmt.Sprintf("%s %d", strings.ReplaceAll(c.Type, "_", " "), c.Value)
+}
+
+// VerificationPayload 校验请求日志中满足条件的请求次数。method 为空时匹配任意方法，
+// path 使用与 mock 相同的路径模式，未指定 count 时要求至少调用一次
+type VerificationPayload struct {
+	Method   string                    `json:"method"`
+	Path     string                    `json:"path" validate:"required"`
+	Matchers *matching.RequestMatchers `json:"matchers" validate:"omitempty"`
+	Count    *CountConstraint          `json:"count" validate:"omitempty"`
+	Since    time.Time                 `json:"since"`
+	Until    time.Time                 `json:"until"`
+}
+
+// Normalize 填充默认的次数约束
+func (p *VerificationPayload) Normalize() {
+	p.Method = strings.ToUpper(p.Method)
+	if p.Count == nil {
+		p.Count = &CountConstraint{Type: CountAtLeast, Value: 1}
+	}
+}
+
+// NearMiss 校验失败时与条件最接近但没有匹配上的请求
+type NearMiss struct {
+	Request  *journal.Entry `json:"request"`
+	Reason   string         `json:"reason"`
+	Detail   string         `json:"detail,omitempty"`
+	Distance int            `json:"distance"`
+}
+
+type VerificationResponse struct {
+	Passed   bool   `json:"passed"`
+	Expected string `json:"expected"`
+	Actual   int    `json:"actual"`
+	// Matched 满足条件的请求，最新的在前
+	Matched    []*journal.Entry `json:"matched"`
+	NearMisses []NearMiss       `json:"near_misses"`
+}
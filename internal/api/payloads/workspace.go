@@ -0,0 +1,66 @@
+package payloads
+
+import (
+	"kite/internal/models"
+	"time"
+)
+
+// WorkspacePayload 创建工作区，Members 为初始成员
+type WorkspacePayload struct {
+	Name    string   `json:"name" validate:"required,max=255"`
+	Members []string `json:"members" validate:"dive,required,max=255"`
+}
+
+// WorkspaceMemberPayload 添加工作区成员
+type WorkspaceMemberPayload struct {
+	UserId string `json:"user_id" validate:"required,max=255"`
+}
+
+// ApiKeyPayload 创建 API Key，Name 用于辨认用途
+type ApiKeyPayload struct {
+	Name string `json:"name" validate:"max=255"`
+}
+
+type WorkspaceResponse struct {
+	Uuid      string                    `json:"uuid"`
+	Name      string                    `json:"name"`
+	Members   []WorkspaceMemberResponse `json:"members"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+type WorkspaceMemberResponse struct {
+	UserId    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ApiKeyResponse API Key 的信息，Key 只在创建时返回
+type ApiKeyResponse struct {
+	Uuid      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	Key       string    `json:"key,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewWorkspaceResponse 将工作区与成员转换为接口输出格式
+func NewWorkspaceResponse(workspace *models.Workspace, members []*models.WorkspaceMember) *WorkspaceResponse {
+	response := &WorkspaceResponse{
+		Uuid:      workspace.Uuid,
+		Name:      workspace.Name,
+		Members:   make([]WorkspaceMemberResponse, 0, len(members)),
+		CreatedAt: workspace.CreatedAt,
+	}
+	for _, member := range members {
+		response.Members = append(response.Members, NewWorkspaceMemberResponse(member))
+	}
+	return response
+}
+
+func NewWorkspaceMemberResponse(member *models.WorkspaceMember) WorkspaceMemberResponse {
+	return WorkspaceMemberResponse{UserId: member.UserId, CreatedAt: member.CreatedAt}
+}
+
+// NewApiKeyResponse 转换为接口输出格式，不包含摘要
+func NewApiKeyResponse(key *models.ApiKey) ApiKeyResponse {
+	return ApiKeyResponse{Uuid: key.Uuid, Name: key.Name, Prefix: key.Prefix, CreatedAt: key.CreatedAt}
+}
@@ -3,18 +3,89 @@ package routes
 import (
 	"github.com/labstack/echo/v4"
 	"kite/internal/api/handlers"
+	"kite/internal/api/handlers/auth"
+	"kite/internal/api/handlers/bundle"
+	"kite/internal/api/handlers/importer"
+	"kite/internal/api/handlers/journal"
 	"kite/internal/api/handlers/mock"
+	"kite/internal/api/handlers/namespace"
+	"kite/internal/api/handlers/routing"
+	"kite/internal/api/handlers/scenario"
+	"kite/internal/api/handlers/workspace"
+	kiteAuth "kite/internal/auth"
 )
 
-func RegisterRoutes(e *echo.Echo, mockHandler *mock.ApiHandler) {
+func RegisterRoutes(
+	e *echo.Echo,
+	mockHandler *mock.ApiHandler,
+	namespaceHandler *namespace.NamespaceHandler,
+	scenarioHandler *scenario.ScenarioHandler,
+	importHandler *importer.ImportHandler,
+	bundleHandler *bundle.BundleHandler,
+	journalHandler *journal.JournalHandler,
+	routingHandler *routing.RoutingHandler,
+	workspaceHandler *workspace.WorkspaceHandler,
+	authHandler *auth.AuthHandler,
+) {
 	e.GET("/health", handlers.HealthCheck)
 
 	v1 := e.Group("/api/v1")
 
+	// 管理接口先认证再进入工作区中间件：读取需要 viewer，修改需要 editor，管理工作区与路由表需要 admin
+	viewer := authHandler.Require(kiteAuth.RoleViewer)
+	editor := authHandler.Require(kiteAuth.RoleEditor)
+	admin := authHandler.Require(kiteAuth.RoleAdmin)
+
+	v1.GET("/auth/me", authHandler.Me, viewer)
+
 	mockRoutes := v1.Group("/mock")
-	mockRoutes.POST("/create", mockHandler.Create)
-	mockRoutes.GET("/:uid/*", mockHandler.Get)
-	mockRoutes.POST("/:uid/*", mockHandler.Post)
-	mockRoutes.PUT("/:uid/*", mockHandler.Put)
-	mockRoutes.DELETE("/:uid/*", mockHandler.Delete)
+	// mock 入口按 URL 中的命名空间公开访问
+	mockRoutes.POST("/create", mockHandler.Create, editor, workspaceHandler.Middleware)
+	// 标准方法走 Any，自定义方法（如 PURGE、LINK）在路由层没有处理器，由 RouteNotFound 兜底到同一个入口
+	mockRoutes.Any("/:uid/*", mockHandler.Serve, journalHandler.Middleware)
+	mockRoutes.RouteNotFound("/:uid/*", mockHandler.Serve, journalHandler.Middleware)
+
+	apiRoutes := v1.Group("/apis", viewer, workspaceHandler.Middleware)
+	apiRoutes.GET("", mockHandler.ListApis)
+	apiRoutes.POST("", mockHandler.Create, editor)
+	apiRoutes.GET("/export", bundleHandler.Export)
+	apiRoutes.POST("/import", bundleHandler.Import, editor)
+	apiRoutes.GET("/:uuid", mockHandler.GetApi)
+	apiRoutes.PUT("/:uuid", mockHandler.UpdateApi, editor)
+	apiRoutes.PATCH("/:uuid", mockHandler.PatchApi, editor)
+	apiRoutes.DELETE("/:uuid", mockHandler.DeleteApi, editor)
+	apiRoutes.POST("/:uuid/clone", mockHandler.CloneApi, editor)
+	apiRoutes.GET("/:uuid/calls", mockHandler.GetApiCalls)
+	apiRoutes.DELETE("/:uuid/calls", mockHandler.ResetApiCalls, editor)
+
+	workspaceRoutes := v1.Group("/workspace", viewer, workspaceHandler.Middleware)
+	workspaceRoutes.GET("", workspaceHandler.Get)
+	workspaceRoutes.POST("/members", workspaceHandler.AddMember, admin)
+	workspaceRoutes.DELETE("/members/:user_id", workspaceHandler.RemoveMember, admin)
+	workspaceRoutes.GET("/keys", workspaceHandler.ListKeys, admin)
+	workspaceRoutes.POST("/keys", workspaceHandler.CreateKey, admin)
+	workspaceRoutes.DELETE("/keys/:uuid", workspaceHandler.DeleteKey, admin)
+
+	routingRoutes := v1.Group("/routing", viewer, workspaceHandler.Middleware)
+	routingRoutes.GET("/metrics", routingHandler.Metrics)
+	routingRoutes.POST("/refresh", routingHandler.Refresh, admin)
+
+	namespaceRoutes := v1.Group("/namespaces", viewer, workspaceHandler.Middleware)
+	namespaceRoutes.GET("/:uid", namespaceHandler.Get)
+	namespaceRoutes.PUT("/:uid", namespaceHandler.Update, editor)
+	namespaceRoutes.PUT("/:uid/openapi", namespaceHandler.BindOpenApi, editor)
+	namespaceRoutes.GET("/:uid/openapi", namespaceHandler.GetOpenApi)
+	namespaceRoutes.DELETE("/:uid/openapi", namespaceHandler.UnbindOpenApi, editor)
+	namespaceRoutes.GET("/:uid/scenarios", scenarioHandler.List)
+	namespaceRoutes.DELETE("/:uid/scenarios", scenarioHandler.ResetAll, editor)
+	namespaceRoutes.GET("/:uid/scenarios/:name", scenarioHandler.Get)
+	namespaceRoutes.PUT("/:uid/scenarios/:name", scenarioHandler.Set, editor)
+	namespaceRoutes.DELETE("/:uid/scenarios/:name", scenarioHandler.Reset, editor)
+	namespaceRoutes.POST("/:uid/import/openapi", importHandler.ImportOpenAPI, editor)
+	namespaceRoutes.POST("/:uid/import/har", importHandler.ImportHAR, editor)
+	namespaceRoutes.POST("/:uid/import/postman", importHandler.ImportPostman, editor)
+	namespaceRoutes.GET("/:uid/journal", journalHandler.Search)
+	namespaceRoutes.DELETE("/:uid/journal", journalHandler.Clear, editor)
+	// 校验请求日志不修改数据，viewer 即可调用
+	namespaceRoutes.POST("/:uid/journal/verify", journalHandler.Verify)
 }
@@ -50,20 +50,24 @@ func (cv *CustomValidator) translateError(err error) error {
 	for _, e := range validationErrors {
 		errorMessages = append(errorMessages, cv.buildErrorMessage(e))
 	}
-	// 返回单个错误
-	if len(errorMessages) == 1 {
-		return KiteError.NewWithMessage(KiteError.ValidationError, errorMessages[0], err)
-	}
-	// 返回多个错误
-	message := fmt.Sprintf("validation failed: %s", strings.Join(errorMessages, "; "))
-	return KiteError.NewWithMessage(KiteError.ValidationError, message, err)
+	return KiteError.NewWithMessage(KiteError.ValidationError, JoinErrorMessages(errorMessages), err)
 }
 
 func (cv *CustomValidator) buildErrorMessage(e validator.FieldError) string {
-	field := e.Field()
-	tag := e.Tag()
-	param := e.Param()
+	return FieldErrorMessage(e.Field(), e.Tag(), e.Param())
+}
 
+// JoinErrorMessages 单个错误直接返回，多个错误合并为一条消息
+func JoinErrorMessages(messages []string) string {
+	if len(messages) == 1 {
+		return messages[0]
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(messages, "; "))
+}
+
+// FieldErrorMessage 根据字段名、校验标签和参数生成错误消息，
+// 除 validator 的标签外，还包含 OpenAPI 请求校验使用的 type、pattern、format 等标签
+func FieldErrorMessage(field string, tag string, param string) string {
 	switch tag {
 	case "required":
 		return fmt.Sprintf("%s is required", field)
@@ -89,6 +93,28 @@ func (cv *CustomValidator) buildErrorMessage(e validator.FieldError) string {
 		return fmt.Sprintf("%s must be greater than or equal to %s", field, param)
 	case "lte":
 		return fmt.Sprintf("%s must be less than or equal to %s", field, param)
+	case "uuid":
+		return fmt.Sprintf("%s must be a valid UUID", field)
+	case "type":
+		return fmt.Sprintf("%s must be of type %s", field, param)
+	case "pattern":
+		return fmt.Sprintf("%s must match pattern %s", field, param)
+	case "format":
+		return fmt.Sprintf("%s must be a valid %s", field, param)
+	case "min_items":
+		return fmt.Sprintf("%s must contain at least %s items", field, param)
+	case "max_items":
+		return fmt.Sprintf("%s must contain at most %s items", field, param)
+	case "unknown":
+		return fmt.Sprintf("%s is not allowed", field)
+	case "schema":
+		return fmt.Sprintf("%s does not match any of the allowed schemas", field)
+	case "one_schema":
+		return fmt.Sprintf("%s must match exactly one of the allowed schemas, but matches %s", field, param)
+	case "json":
+		return fmt.Sprintf("%s must be valid JSON", field)
+	case "operation":
+		return fmt.Sprintf("%s does not match any operation in the OpenAPI document", field)
 	default:
 		return fmt.Sprintf("%s failed validation: %s", field, tag)
 	}
@@ -106,3 +132,14 @@ func BindAndValidate(c echo.Context, i interface{}) error {
 	}
 	return nil
 }
+
+// BindQueryAndValidate 只绑定查询参数并验证，用于请求体另有用途（如上传文件）的 POST 请求
+func BindQueryAndValidate(c echo.Context, i interface{}) error {
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, i); err != nil {
+		return KiteError.NewWithMessage(KiteError.BadRequestError, "Invalid request format", err)
+	}
+	if err := c.Validate(i); err != nil {
+		return err
+	}
+	return nil
+}
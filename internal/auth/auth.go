@@ -0,0 +1,62 @@
+// Package auth 管理接口的认证与授权：角色、请求签名与 JWT 校验。
+// 认证通过后的身份以 Principal 保存在 context 中
+package auth
+
+import "context"
+
+// Role 管理接口的角色，权限依次递增，高级角色拥有低级角色的全部权限
+type Role string
+
+const (
+	// RoleViewer 只能读取 mock、命名空间与请求日志
+	RoleViewer Role = "viewer"
+	// RoleEditor 可以创建、修改、删除 mock 与命名空间配置
+	RoleEditor Role = "editor"
+	// RoleAdmin 可以管理工作区成员、API Key 与路由表
+	RoleAdmin Role = "admin"
+)
+
+var roleLevels = map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// ParseRole 解析配置或令牌中的角色
+func ParseRole(value string) (Role, bool) {
+	role := Role(value)
+	_, ok := roleLevels[role]
+	return role, ok
+}
+
+// Allows 角色是否拥有 required 的权限
+func (r Role) Allows(required Role) bool {
+	level, ok := roleLevels[r]
+	return ok && level >= roleLevels[required]
+}
+
+// 认证方式，MethodNone 表示未开启认证
+const (
+	MethodNone   = "none"
+	MethodToken  = "token"
+	MethodHMAC   = "hmac"
+	MethodJWT    = "jwt"
+	MethodApiKey = "api_key"
+)
+
+// Principal 认证通过的调用方。WorkspaceId 不为空时请求限定在该工作区内
+type Principal struct {
+	Subject     string
+	Role        Role
+	Method      string
+	WorkspaceId string
+}
+
+type contextKey struct{}
+
+// WithPrincipal 返回携带调用方身份的 context
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext 返回 context 中的调用方身份
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,36 @@
+package auth
+
+import "testing"
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{RoleViewer, RoleViewer, true},
+		{RoleViewer, RoleEditor, false},
+		{RoleViewer, RoleAdmin, false},
+		{RoleEditor, RoleViewer, true},
+		{RoleEditor, RoleEditor, true},
+		{RoleEditor, RoleAdmin, false},
+		{RoleAdmin, RoleAdmin, true},
+		{"", RoleViewer, false},
+		{"owner", RoleViewer, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+string(tt.required), func(t *testing.T) {
+			if got := tt.role.Allows(tt.required); got != tt.want {
+				t.Fatalf("%q.Allows(%q) = %v, want %v", tt.role, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for value, want := range map[string]bool{"viewer": true, "editor": true, "admin": true, "Admin": false, "": false, "root": false} {
+		if _, ok := ParseRole(value); ok != want {
+			t.Fatalf("ParseRole(%q) ok = %v, want %v", value, ok, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HMACScheme 签名请求在 Authorization 请求头中使用的认证方式：
+	// Authorization: HMAC-SHA256 Credential=<key_id>, Signature=<hex>
+	HMACScheme = "HMAC-SHA256"
+	// TimestampHeader 签名时间，Unix 秒
+	TimestampHeader = "X-Kite-Timestamp"
+)
+
+// StringToSign 待签名的字符串：方法、路径与查询参数、签名时间、请求体 SHA-256 的十六进制，以换行分隔
+func StringToSign(method string, uri string, timestamp string, body []byte) string {
+	digest := sha256.Sum256(body)
+	return strings.Join([]string{strings.ToUpper(method), uri, timestamp, hex.EncodeToString(digest[:])}, "\n")
+}
+
+// Sign 计算请求签名，返回十六进制
+func Sign(secret string, method string, uri string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(StringToSign(method, uri, timestamp, body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature 以常量时间比较签名
+func VerifySignature(secret string, signature string, method string, uri string, timestamp string, body []byte) bool {
+	expected, _ := hex.DecodeString(Sign(secret, method, uri, timestamp, body))
+	actual, err := hex.DecodeString(signature)
+	return err == nil && hmac.Equal(expected, actual)
+}
+
+// ParseHMACCredentials 解析 Authorization 请求头中认证方式之后的部分
+func ParseHMACCredentials(value string) (keyId string, signature string, err error) {
+	for _, part := range strings.Split(value, ",") {
+		name, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "Credential":
+			keyId = v
+		case "Signature":
+			signature = v
+		}
+	}
+	if keyId == "" || signature == "" {
+		return "", "", errors.New("hmac authorization requires Credential and Signature")
+	}
+	return keyId, signature, nil
+}
+
+// CheckTimestamp 签名时间与 now 的偏差不能超过 maxSkew，用于限制签名被重放的时间窗口
+func CheckTimestamp(timestamp string, now time.Time, maxSkew time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header %q", TimestampHeader, timestamp)
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("request timestamp is outside the allowed skew of %s", maxSkew)
+	}
+	return nil
+}
+
+// replaySweepInterval 清理过期签名记录的最小间隔
+const replaySweepInterval = time.Minute
+
+// ReplayGuard 记录签名时间窗口内已经使用过的签名，同一个签名只能使用一次。
+// 记录只保存在当前实例中，多个实例部署时无法发现在其他实例上重放的请求
+type ReplayGuard struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	nextSweep time.Time
+}
+
+func NewReplayGuard() *ReplayGuard {
+	return &ReplayGuard{seen: make(map[string]time.Time)}
+}
+
+// Use 记录 key 下的签名直到 expires，签名已经使用过时返回错误。只应记录校验通过的签名
+func (g *ReplayGuard) Use(keyId string, signature string, expires time.Time, now time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now.After(g.nextSweep) {
+		for id, until := range g.seen {
+			if now.After(until) {
+				delete(g.seen, id)
+			}
+		}
+		g.nextSweep = now.Add(replaySweepInterval)
+	}
+	id := keyId + ":" + strings.ToLower(signature)
+	if until, ok := g.seen[id]; ok && !now.After(until) {
+		return errors.New("request signature has already been used")
+	}
+	g.seen[id] = expires
+	return nil
+}
+
+// SignRequest 为请求签名并设置 Authorization 与签名时间请求头，请求体会被读取后重新放回
+func SignRequest(req *http.Request, keyId string, secret string, now time.Time) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign(secret, req.Method, req.URL.RequestURI(), timestamp, body)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s, Signature=%s", HMACScheme, keyId, signature))
+	return nil
+}
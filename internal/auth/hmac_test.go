@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	const secret = "hmac-secret"
+	timestamp := strconv.FormatInt(testNow.Unix(), 10)
+	body := []byte(`{"path":"/users"}`)
+	signature := Sign(secret, http.MethodPost, "/api/v1/apis?uid=a", timestamp, body)
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		method    string
+		uri       string
+		timestamp string
+		body      []byte
+		want      bool
+	}{
+		{name: "valid", secret: secret, signature: signature, method: http.MethodPost, uri: "/api/v1/apis?uid=a", timestamp: timestamp, body: body, want: true},
+		{name: "uppercase hex", secret: secret, signature: strings.ToUpper(signature), method: http.MethodPost, uri: "/api/v1/apis?uid=a", timestamp: timestamp, body: body, want: true},
+		{name: "wrong secret", secret: "guessed", signature: signature, method: http.MethodPost, uri: "/api/v1/apis?uid=a", timestamp: timestamp, body: body},
+		{name: "tampered body", secret: secret, signature: signature, method: http.MethodPost, uri: "/api/v1/apis?uid=a", timestamp: timestamp, body: []byte(`{"path":"/admin"}`)},
+		{name: "different method", secret: secret, signature: signature, method: http.MethodDelete, uri: "/api/v1/apis?uid=a", timestamp: timestamp, body: body},
+		{name: "different query", secret: secret, signature: signature, method: http.MethodPost, uri: "/api/v1/apis?uid=b", timestamp: timestamp, body: body},
+		{name: "different timestamp", secret: secret, signature: signature, method: http.MethodPost, uri: "/api/v1/apis?uid=a", timestamp: timestamp + "0", body: body},
+		{name: "not hex", secret: secret, signature: "zz" + signature[2:], method: http.MethodPost, uri: "/api/v1/apis?uid=a", timestamp: timestamp, body: body},
+		{name: "truncated", secret: secret, signature: signature[:32], method: http.MethodPost, uri: "/api/v1/apis?uid=a", timestamp: timestamp, body: body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(tt.secret, tt.signature, tt.method, tt.uri, tt.timestamp, tt.body); got != tt.want {
+				t.Fatalf("VerifySignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTimestamp(t *testing.T) {
+	const maxSkew = 300 * time.Second
+	tests := []struct {
+		name      string
+		timestamp string
+		valid     bool
+	}{
+		{name: "now", timestamp: strconv.FormatInt(testNow.Unix(), 10), valid: true},
+		{name: "at the past edge", timestamp: strconv.FormatInt(testNow.Add(-maxSkew).Unix(), 10), valid: true},
+		{name: "at the future edge", timestamp: strconv.FormatInt(testNow.Add(maxSkew).Unix(), 10), valid: true},
+		{name: "too old", timestamp: strconv.FormatInt(testNow.Add(-maxSkew-time.Second).Unix(), 10)},
+		{name: "too far in the future", timestamp: strconv.FormatInt(testNow.Add(maxSkew+time.Second).Unix(), 10)},
+		{name: "missing", timestamp: ""},
+		{name: "not a number", timestamp: "yesterday"},
+		{name: "milliseconds", timestamp: strconv.FormatInt(testNow.UnixMilli(), 10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckTimestamp(tt.timestamp, testNow, maxSkew); (err == nil) != tt.valid {
+				t.Fatalf("CheckTimestamp = %v, want valid=%v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	guard := NewReplayGuard()
+	expires := testNow.Add(time.Minute)
+	steps := []struct {
+		name      string
+		keyId     string
+		signature string
+		now       time.Time
+		valid     bool
+	}{
+		{name: "first use", keyId: "deploy", signature: "abcd", now: testNow, valid: true},
+		{name: "replay", keyId: "deploy", signature: "abcd", now: testNow.Add(time.Second)},
+		{name: "replay with different hex case", keyId: "deploy", signature: "ABCD", now: testNow.Add(time.Second)},
+		{name: "same signature under another key", keyId: "ci", signature: "abcd", now: testNow.Add(time.Second), valid: true},
+		{name: "another signature", keyId: "deploy", signature: "ef01", now: testNow.Add(time.Second), valid: true},
+		{name: "replay at expiry", keyId: "deploy", signature: "abcd", now: expires},
+		{name: "after expiry", keyId: "deploy", signature: "abcd", now: expires.Add(time.Second), valid: true},
+	}
+	for _, step := range steps {
+		err := guard.Use(step.keyId, step.signature, expires, step.now)
+		if (err == nil) != step.valid {
+			t.Fatalf("%s: Use = %v, want valid=%v", step.name, err, step.valid)
+		}
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/namespaces/a?force=1", strings.NewReader(`{"mode":"record"}`))
+	if err := SignRequest(req, "deploy", "hmac-secret", testNow); err != nil {
+		t.Fatal(err)
+	}
+	keyId, signature, err := ParseHMACCredentials(strings.TrimPrefix(req.Header.Get("Authorization"), HMACScheme+" "))
+	if err != nil || keyId != "deploy" {
+		t.Fatalf("ParseHMACCredentials = %q, %v", keyId, err)
+	}
+	timestamp := req.Header.Get(TimestampHeader)
+	if !VerifySignature("hmac-secret", signature, req.Method, req.URL.RequestURI(), timestamp, []byte(`{"mode":"record"}`)) {
+		t.Fatalf("signature of a signed request does not verify")
+	}
+}
+
+func TestParseHMACCredentials(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{value: "Credential=deploy, Signature=abcd", valid: true},
+		{value: "Signature=abcd,Credential=deploy", valid: true},
+		{value: "Credential=deploy"},
+		{value: "Signature=abcd"},
+		{value: "deploy:abcd"},
+		{value: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if _, _, err := ParseHMACCredentials(tt.value); (err == nil) != tt.valid {
+				t.Fatalf("ParseHMACCredentials = %v, want valid=%v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kite/internal/configs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 未配置时保存角色与工作区的声明
+const (
+	defaultRoleClaim      = "role"
+	defaultWorkspaceClaim = "workspace"
+)
+
+// Claims JWT 的声明
+type Claims map[string]any
+
+// Subject 返回 sub 声明
+func (c Claims) Subject() string {
+	subject, _ := c["sub"].(string)
+	return subject
+}
+
+// Strings 返回字符串或字符串数组类型的声明
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// JWTVerifier 校验 JWT 的签名与有效期，HS 系列算法使用共享密钥，RS 系列算法使用 JWKS 文件中的公钥
+type JWTVerifier struct {
+	secret          []byte
+	keys            map[string]*rsa.PublicKey
+	issuer          string
+	audience        string
+	roleClaim       string
+	workspaceClaim  string
+	leeway          time.Duration
+	allowMissingExp bool
+	now             func() time.Time
+}
+
+func NewJWTVerifier(cfg *configs.JWTConfig) (*JWTVerifier, error) {
+	verifier := &JWTVerifier{
+		secret:          []byte(cfg.Secret),
+		issuer:          cfg.Issuer,
+		audience:        cfg.Audience,
+		roleClaim:       cfg.RoleClaim,
+		workspaceClaim:  cfg.WorkspaceClaim,
+		leeway:          time.Duration(cfg.Leeway) * time.Second,
+		allowMissingExp: cfg.AllowMissingExp,
+		now:             time.Now,
+	}
+	if verifier.roleClaim == "" {
+		verifier.roleClaim = defaultRoleClaim
+	}
+	if verifier.workspaceClaim == "" {
+		verifier.workspaceClaim = defaultWorkspaceClaim
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = keys
+	}
+	return verifier, nil
+}
+
+// Configured 是否配置了任何校验密钥
+func (v *JWTVerifier) Configured() bool {
+	return len(v.secret) > 0 || len(v.keys) > 0
+}
+
+// Role 返回令牌中权限最高的角色，没有有效角色时返回 false
+func (v *JWTVerifier) Role(claims Claims) (Role, bool) {
+	var best Role
+	for _, value := range claims.Strings(v.roleClaim) {
+		if role, ok := ParseRole(value); ok && role.Allows(best) {
+			best = role
+		}
+	}
+	return best, best != ""
+}
+
+// Workspace 返回令牌所属工作区的 UUID，没有该声明时返回空字符串
+func (v *JWTVerifier) Workspace(claims Claims) string {
+	workspace, _ := claims[v.workspaceClaim].(string)
+	return workspace
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+var hashes = map[string]crypto.Hash{
+	"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+}
+
+// Verify 校验签名与 exp、nbf、iss、aud 声明，返回令牌的声明。没有 exp 的令牌只有在配置允许时才接受
+func (v *JWTVerifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed jwt")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed jwt header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed jwt signature: %w", err)
+	}
+	if err := v.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed jwt claims: %w", err)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verifySignature 算法由令牌声明，但只接受与已配置密钥类型一致的算法，不接受 none
+func (v *JWTVerifier) verifySignature(header jwtHeader, signed string, signature []byte) error {
+	hash, ok := hashes[header.Alg]
+	if !ok {
+		return fmt.Errorf("unsupported jwt algorithm %q", header.Alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	if strings.HasPrefix(header.Alg, "HS") {
+		if len(v.secret) == 0 {
+			return fmt.Errorf("jwt algorithm %s is not configured", header.Alg)
+		}
+		mac := hmac.New(hash.New, v.secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid jwt signature")
+		}
+		return nil
+	}
+	key, err := v.key(header.Kid)
+	if err != nil {
+		return err
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+		return errors.New("invalid jwt signature")
+	}
+	return nil
+}
+
+// key 按 kid 查找公钥，令牌未指定 kid 时只有 JWKS 中仅有一个公钥才能确定
+func (v *JWTVerifier) key(kid string) (*rsa.PublicKey, error) {
+	if len(v.keys) == 0 {
+		return nil, errors.New("no jwks is configured for rsa algorithms")
+	}
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown jwt key id %q", kid)
+}
+
+func (v *JWTVerifier) validateClaims(claims Claims) error {
+	now := v.now()
+	exp, ok := claims["exp"].(float64)
+	switch {
+	case !ok && !v.allowMissingExp:
+		return errors.New("jwt has no exp claim")
+	case ok && now.After(time.Unix(int64(exp), 0).Add(v.leeway)):
+		return errors.New("jwt is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0).Add(-v.leeway)) {
+		return errors.New("jwt is not valid yet")
+	}
+	if v.issuer != "" {
+		if issuer, _ := claims["iss"].(string); issuer != v.issuer {
+			return fmt.Errorf("unexpected jwt issuer %q", issuer)
+		}
+	}
+	if v.audience != "" && !slices.Contains(claims.Strings("aud"), v.audience) {
+		return errors.New("jwt audience does not match")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,277 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"kite/internal/configs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "hs-secret"
+
+var testNow = time.Unix(1_700_000_000, 0)
+
+// signJWT 按 header 中的 alg 签名，HS 系列使用 secret，RS 系列使用 key，alg 为 none 时不签名
+func signJWT(t *testing.T, header map[string]any, claims map[string]any, secret []byte, key *rsa.PrivateKey) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(header) + "." + encode(claims)
+	alg, _ := header["alg"].(string)
+	var signature []byte
+	switch {
+	case strings.HasPrefix(alg, "HS"):
+		mac := hmac.New(hashes[alg].New, secret)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case strings.HasPrefix(alg, "RS"):
+		h := hashes[alg].New()
+		h.Write([]byte(signed))
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, hashes[alg], h.Sum(nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeJWKS 将公钥写入临时的 JWKS 文件
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	t.Helper()
+	set := jwks{Keys: []jwk{{
+		Kty: "RSA",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJWTVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksFile := writeJWKS(t, "k1", &key.PublicKey)
+	valid := func() map[string]any {
+		return map[string]any{
+			"sub": "alice",
+			"iss": "kite-test",
+			"aud": "kite",
+			"exp": testNow.Add(time.Hour).Unix(),
+			"nbf": testNow.Add(-time.Minute).Unix(),
+		}
+	}
+	with := func(name string, value any) map[string]any {
+		claims := valid()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	hs256 := map[string]any{"alg": "HS256", "typ": "JWT"}
+	rs256 := map[string]any{"alg": "RS256", "kid": "k1"}
+	full := configs.JWTConfig{Secret: testSecret, JWKSFile: jwksFile, Issuer: "kite-test", Audience: "kite", Leeway: 60}
+	rsaOnly := configs.JWTConfig{JWKSFile: jwksFile}
+	secretOnly := configs.JWTConfig{Secret: testSecret}
+
+	tests := []struct {
+		name    string
+		cfg     configs.JWTConfig
+		token   func() string
+		wantErr string
+	}{
+		{
+			name:  "valid hs256",
+			cfg:   full,
+			token: func() string { return signJWT(t, hs256, valid(), []byte(testSecret), nil) },
+		},
+		{
+			name:  "valid rs256",
+			cfg:   full,
+			token: func() string { return signJWT(t, rs256, valid(), nil, key) },
+		},
+		{
+			name: "expired token",
+			cfg:  full,
+			token: func() string {
+				return signJWT(t, hs256, with("exp", testNow.Add(-2*time.Minute).Unix()), []byte(testSecret), nil)
+			},
+			wantErr: "jwt is expired",
+		},
+		{
+			name: "expired within leeway",
+			cfg:  full,
+			token: func() string {
+				return signJWT(t, hs256, with("exp", testNow.Add(-30*time.Second).Unix()), []byte(testSecret), nil)
+			},
+		},
+		{
+			name:    "missing exp",
+			cfg:     full,
+			token:   func() string { return signJWT(t, hs256, with("exp", nil), []byte(testSecret), nil) },
+			wantErr: "jwt has no exp claim",
+		},
+		{
+			name:  "missing exp explicitly allowed",
+			cfg:   configs.JWTConfig{Secret: testSecret, AllowMissingExp: true},
+			token: func() string { return signJWT(t, hs256, with("exp", nil), []byte(testSecret), nil) },
+		},
+		{
+			name: "not valid yet",
+			cfg:  full,
+			token: func() string {
+				return signJWT(t, hs256, with("nbf", testNow.Add(time.Hour).Unix()), []byte(testSecret), nil)
+			},
+			wantErr: "jwt is not valid yet",
+		},
+		{
+			name:    "hs256 signed with the wrong secret",
+			cfg:     full,
+			token:   func() string { return signJWT(t, hs256, valid(), []byte("guessed"), nil) },
+			wantErr: "invalid jwt signature",
+		},
+		{
+			name:    "rs256 signed with an unknown key",
+			cfg:     full,
+			token:   func() string { return signJWT(t, rs256, valid(), nil, other) },
+			wantErr: "invalid jwt signature",
+		},
+		{
+			name: "tampered claims",
+			cfg:  full,
+			token: func() string {
+				parts := strings.Split(signJWT(t, hs256, valid(), []byte(testSecret), nil), ".")
+				forged := strings.Split(signJWT(t, hs256, with("role", "admin"), []byte("guessed"), nil), ".")
+				return parts[0] + "." + forged[1] + "." + parts[2]
+			},
+			wantErr: "invalid jwt signature",
+		},
+		{
+			// 用公开的 RSA 公钥作为 HMAC 密钥伪造令牌
+			name:    "alg swap from rs256 to hs256",
+			cfg:     rsaOnly,
+			token:   func() string { return signJWT(t, hs256, valid(), key.PublicKey.N.Bytes(), nil) },
+			wantErr: "jwt algorithm HS256 is not configured",
+		},
+		{
+			name:    "alg swap to hs256 with both key types configured",
+			cfg:     full,
+			token:   func() string { return signJWT(t, hs256, valid(), key.PublicKey.N.Bytes(), nil) },
+			wantErr: "invalid jwt signature",
+		},
+		{
+			name:    "rs256 without a jwks",
+			cfg:     secretOnly,
+			token:   func() string { return signJWT(t, rs256, valid(), nil, key) },
+			wantErr: "no jwks is configured for rsa algorithms",
+		},
+		{
+			name:    "alg none",
+			cfg:     full,
+			token:   func() string { return signJWT(t, map[string]any{"alg": "none"}, valid(), nil, nil) },
+			wantErr: `unsupported jwt algorithm "none"`,
+		},
+		{
+			name:    "wrong issuer",
+			cfg:     full,
+			token:   func() string { return signJWT(t, hs256, with("iss", "someone-else"), []byte(testSecret), nil) },
+			wantErr: `unexpected jwt issuer "someone-else"`,
+		},
+		{
+			name:    "wrong audience",
+			cfg:     full,
+			token:   func() string { return signJWT(t, hs256, with("aud", []string{"other"}), []byte(testSecret), nil) },
+			wantErr: "jwt audience does not match",
+		},
+		{
+			name:    "malformed token",
+			cfg:     full,
+			token:   func() string { return "not-a-jwt" },
+			wantErr: "malformed jwt",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			verifier, err := NewJWTVerifier(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifier.now = func() time.Time { return testNow }
+			_, err = verifier.Verify(tt.token())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Verify = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWTRoleAndWorkspace(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           configs.JWTConfig
+		claims        Claims
+		wantRole      Role
+		wantOK        bool
+		wantWorkspace string
+	}{
+		{name: "single role", claims: Claims{"role": "editor"}, wantRole: RoleEditor, wantOK: true},
+		{name: "highest of several roles", claims: Claims{"role": []any{"viewer", "admin", "editor"}}, wantRole: RoleAdmin, wantOK: true},
+		{name: "unknown role", claims: Claims{"role": "owner"}},
+		{name: "no role"},
+		{name: "custom role claim", cfg: configs.JWTConfig{RoleClaim: "groups"}, claims: Claims{"groups": []any{"viewer"}}, wantRole: RoleViewer, wantOK: true},
+		{name: "default workspace claim", claims: Claims{"role": "editor", "workspace": "ws-1"}, wantRole: RoleEditor, wantOK: true, wantWorkspace: "ws-1"},
+		{name: "custom workspace claim", cfg: configs.JWTConfig{WorkspaceClaim: "tenant"}, claims: Claims{"role": "viewer", "tenant": "ws-2", "workspace": "ignored"}, wantRole: RoleViewer, wantOK: true, wantWorkspace: "ws-2"},
+		{name: "non-string workspace claim", claims: Claims{"role": "viewer", "workspace": 7.0}, wantRole: RoleViewer, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			verifier, err := NewJWTVerifier(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			role, ok := verifier.Role(tt.claims)
+			if role != tt.wantRole || ok != tt.wantOK {
+				t.Fatalf("Role = %q, %v, want %q, %v", role, ok, tt.wantRole, tt.wantOK)
+			}
+			if workspace := verifier.Workspace(tt.claims); workspace != tt.wantWorkspace {
+				t.Fatalf("Workspace = %q, want %q", workspace, tt.wantWorkspace)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"kite/internal/api/payloads"
+	"kite/internal/models"
+	"time"
+)
+
+// SchemaVersion 当前导出的 bundle 格式版本，格式发生不兼容的变化时递增，并在 upgrades 中注册升级函数
+const SchemaVersion = 1
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Bundle 可移植的 mock 集合，用于在不同环境之间迁移或提交到代码仓库
+type Bundle struct {
+	SchemaVersion int       `json:"schema_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Mocks         []Mock    `json:"mocks"`
+}
+
+// Mock bundle 中的一个 mock，字段与创建 mock 的请求体一致，另外保留 uuid 用于识别同一个 mock
+type Mock struct {
+	Uuid string `json:"uuid,omitempty"`
+	payloads.MockApiPayload
+}
+
+// upgrades 将旧版本的 bundle（以通用 map 表示）升级到下一个版本，键为升级前的版本
+var upgrades = map[int]func(root map[string]interface{}) error{}
+
+// New 由 mock 生成 bundle
+func New(apis []*models.Api) (*Bundle, error) {
+	mocks := make([]Mock, 0, len(apis))
+	for _, api := range apis {
+		mock, err := FromApi(api)
+		if err != nil {
+			return nil, fmt.Errorf("mock %s: %w", api.Uuid, err)
+		}
+		mocks = append(mocks, mock)
+	}
+	return &Bundle{SchemaVersion: SchemaVersion, ExportedAt: time.Now().UTC(), Mocks: mocks}, nil
+}
+
+// FromApi 通过接口输出格式转换，保证导出的字段与接口一致
+func FromApi(api *models.Api) (Mock, error) {
+	resp, err := payloads.NewMockApiResponse(api)
+	if err != nil {
+		return Mock{}, err
+	}
+	return Mock{
+		Uuid: resp.Uuid,
+		MockApiPayload: payloads.MockApiPayload{
+			UserId:          resp.UserId,
+			Path:            resp.Path,
+			Method:          resp.Method,
+			StatusCode:      resp.StatusCode,
+			ContentType:     resp.ContentType,
+			Charset:         resp.Charset,
+			ResponseHeaders: resp.ResponseHeaders,
+			ResponseBody:    resp.ResponseBody,
+			RequestMatchers: resp.RequestMatchers,
+			Priority:        resp.Priority,
+			Template:        resp.Template,
+			Delay:           resp.Delay,
+			ThrottleBps:     resp.ThrottleBps,
+			Fault:           resp.Fault,
+			Responses:       resp.Responses,
+			SequenceMode:    resp.SequenceMode,
+			ScenarioName:    resp.ScenarioName,
+			RequiredState:   resp.RequiredState,
+			NewState:        resp.NewState,
+			Tags:            resp.Tags,
+		},
+	}, nil
+}
+
+// Encode 将 bundle 编码为 JSON 或 YAML，YAML 的字段名与 JSON 保持一致
+func Encode(b *Bundle, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJSON, "":
+		return data, nil
+	case FormatYAML:
+		var root interface{}
+		if err := json.Unmarshal(data, &root); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(root)
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected json or yaml", format)
+	}
+}
+
+// Decode 解析 JSON 或 YAML 格式的 bundle，旧版本的 bundle 会依次升级到当前版本
+func Decode(data []byte) (*Bundle, error) {
+	var raw interface{}
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON bundle: %w", err)
+		}
+	} else if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid YAML bundle: %w", err)
+	}
+	root, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid bundle: the document root must be an object")
+	}
+	version, ok := root["schema_version"].(float64)
+	if !ok {
+		// YAML 中的整数解析为 int
+		intVersion, isInt := root["schema_version"].(int)
+		if !isInt {
+			return nil, errors.New("invalid bundle: missing schema_version")
+		}
+		version = float64(intVersion)
+	}
+	current := int(version)
+	if current < 1 || float64(current) != version {
+		return nil, fmt.Errorf("invalid bundle: unknown schema_version %v", root["schema_version"])
+	}
+	if current > SchemaVersion {
+		return nil, fmt.Errorf("bundle schema_version %d is newer than the supported version %d", current, SchemaVersion)
+	}
+	for ; current < SchemaVersion; current++ {
+		upgrade, ok := upgrades[current]
+		if !ok {
+			return nil, fmt.Errorf("bundle schema_version %d can not be upgraded", current)
+		}
+		if err := upgrade(root); err != nil {
+			return nil, fmt.Errorf("failed to upgrade bundle from schema_version %d: %w", current, err)
+		}
+	}
+	root["schema_version"] = SchemaVersion
+
+	// 统一转换为 JSON 后按 json 标签解析
+	normalized, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	var b Bundle
+	if err := json.Unmarshal(normalized, &b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	return &b, nil
+}
@@ -10,14 +10,22 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig `mapstructure:"server"`
-	Database MySQLConfig  `mapstructure:"database"`
-	Log      LogConfig    `mapstructure:"log"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Log       LogConfig       `mapstructure:"log"`
+	Journal   JournalConfig   `mapstructure:"journal"`
+	Routing   RoutingConfig   `mapstructure:"routing"`
+	Proxy     ProxyConfig     `mapstructure:"proxy"`
+	Workspace WorkspaceConfig `mapstructure:"workspace"`
+	Auth      AuthConfig      `mapstructure:"auth"`
 }
 
 type ServerConfig struct {
 	Port            int `mapstructure:"port"`
 	ShutdownTimeout int `mapstructure:"shutdown_timeout"`
+	// AutoMigrate 启动时执行未执行的数据库迁移，只对 mysql 与 sqlite 驱动生效。
+	// 未开启时需要先执行 migrate up 创建表结构；sqlite 内存数据库（path 为 :memory:）总是在打开时迁移
+	AutoMigrate bool `mapstructure:"auto_migrate"`
 }
 
 type LogConfig struct {
@@ -26,7 +34,98 @@ type LogConfig struct {
 	Dev      bool   `mapstructure:"dev"`
 }
 
-type MySQLConfig struct {
+// JournalConfig mock 请求日志的存储配置，sink 可选 memory、file、database
+type JournalConfig struct {
+	Sink string `mapstructure:"sink"`
+	// Capacity 内存存储保留的记录数
+	Capacity int `mapstructure:"capacity"`
+	// Path、MaxSizeMB、MaxBackups 文件存储的路径、单个文件大小上限和保留的轮转文件数
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+	// MaxBodyBytes 记录的请求体长度上限，超出部分被截断
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+}
+
+// RoutingConfig mock 请求使用的内存路由表，关闭时每次请求都查询存储
+type RoutingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RefreshInterval 检查存储是否被其他实例修改的间隔（秒），0 表示不检查，只在本实例修改时失效
+	RefreshInterval int `mapstructure:"refresh_interval"`
+}
+
+// ProxyConfig 录制与透传时转发到上游的配置
+type ProxyConfig struct {
+	// MaxBodyBytes 上游响应体的长度上限，默认 10 MiB，超出时转发失败
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+}
+
+// WorkspaceConfig 管理接口的工作区隔离。携带 API Key 的请求总是限定在 Key 所属的工作区内，
+// 配置了 workspace 的 Token、HMAC 密钥与带有工作区声明的 JWT 同样限定在该工作区内。
+// RequireApiKey 为 true 时拒绝不属于任何工作区的非 admin 调用方；为 false 时只在创建工作区之后才拒绝
+type WorkspaceConfig struct {
+	RequireApiKey bool `mapstructure:"require_api_key"`
+}
+
+// AuthConfig 管理接口的认证与授权。开启后管理接口必须携带凭证，凭证对应的角色决定可以访问的接口：
+// viewer 只读，editor 可以修改 mock 与命名空间，admin 还可以管理工作区成员、API Key 与路由表
+type AuthConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Tokens 静态 Bearer Token
+	Tokens []TokenConfig `mapstructure:"tokens"`
+	// HMACKeys 对请求签名使用的密钥
+	HMACKeys []HMACKeyConfig `mapstructure:"hmac_keys"`
+	// HMACMaxSkew 签名时间与服务器时间允许的最大偏差（秒），默认 300，窗口内同一个签名只能使用一次
+	HMACMaxSkew int `mapstructure:"hmac_max_skew"`
+	// HMACMaxBodyBytes 签名请求的请求体长度上限，校验签名前读取请求体，默认 10 MiB，超出时返回 413
+	HMACMaxBodyBytes int       `mapstructure:"hmac_max_body_bytes"`
+	JWT              JWTConfig `mapstructure:"jwt"`
+	// ApiKeyRole 所有工作区 API Key 对应的角色，默认 editor。管理 API Key 需要 admin，
+	// 只有配置为 admin 时才能使用 workspace create 创建的第一个 Key 通过接口管理其他 Key
+	ApiKeyRole string `mapstructure:"api_key_role"`
+}
+
+// TokenConfig Workspace 不为空时调用方限定在该工作区内，为空时不属于任何工作区，只有 admin 可以这样使用
+type TokenConfig struct {
+	Name      string `mapstructure:"name"`
+	Token     string `mapstructure:"token"`
+	Role      string `mapstructure:"role"`
+	Workspace string `mapstructure:"workspace"`
+}
+
+// HMACKeyConfig Workspace 的含义与 TokenConfig 相同
+type HMACKeyConfig struct {
+	KeyId     string `mapstructure:"key_id"`
+	Secret    string `mapstructure:"secret"`
+	Role      string `mapstructure:"role"`
+	Workspace string `mapstructure:"workspace"`
+}
+
+// JWTConfig Secret 用于校验 HS256/HS384/HS512 签名，JWKSFile 中的 RSA 公钥用于校验 RS256/RS384/RS512 签名
+type JWTConfig struct {
+	Secret   string `mapstructure:"secret"`
+	JWKSFile string `mapstructure:"jwks_file"`
+	// Issuer、Audience 不为空时校验 iss 与 aud
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+	// RoleClaim 保存角色的声明，可以是字符串或字符串数组，默认 role
+	RoleClaim string `mapstructure:"role_claim"`
+	// WorkspaceClaim 保存调用方所属工作区 UUID 的声明，默认 workspace，令牌没有该声明时不限定工作区
+	WorkspaceClaim string `mapstructure:"workspace_claim"`
+	// Leeway 校验 exp、nbf 时允许的时钟偏差（秒）
+	Leeway int `mapstructure:"leeway"`
+	// AllowMissingExp 为 true 时接受没有 exp 声明的令牌，默认拒绝永不过期的令牌
+	AllowMissingExp bool `mapstructure:"allow_missing_exp"`
+}
+
+// DatabaseConfig mock 数据的存储配置，driver 可选 mysql、sqlite、memory、file，未配置时使用 mysql
+type DatabaseConfig struct {
+	Driver string `mapstructure:"driver"`
+	// Path sqlite 的数据库文件，或 file 驱动存放 mock 文件的目录
+	Path string `mapstructure:"path"`
+	// Format file 驱动写入文件的格式，可选 yaml、json，默认 yaml
+	Format string `mapstructure:"format"`
+	// 以下为 mysql 驱动的连接配置
 	Host        string `mapstructure:"host"`
 	Port        int    `mapstructure:"port"`
 	Name        string `mapstructure:"name"`
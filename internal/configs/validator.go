@@ -2,17 +2,74 @@ package configs
 
 import (
 	"errors"
+	"fmt"
 )
 
 func validateConfig(cfg *Config) error {
 	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
 		return errors.New("server port must be between 1 and 65535")
 	}
-	if cfg.Database.Host == "" {
-		return errors.New("database host is required")
+	switch cfg.Database.Driver {
+	case "", "mysql":
+		if cfg.Database.Host == "" {
+			return errors.New("database host is required")
+		}
+		if cfg.Database.Port <= 0 || cfg.Database.Port > 65535 {
+			return errors.New("database port must be between 1 and 65535")
+		}
+	case "sqlite", "file":
+		if cfg.Database.Path == "" {
+			return errors.New("database path is required when the database driver is sqlite or file")
+		}
+	case "memory":
+	default:
+		return errors.New("database driver must be one of mysql, sqlite, memory, file")
 	}
-	if cfg.Database.Port <= 0 || cfg.Database.Port > 65535 {
-		return errors.New("database port must be between 1 and 65535")
+	switch cfg.Database.Format {
+	case "", "yaml", "json":
+	default:
+		return errors.New("database format must be one of yaml, json")
+	}
+	switch cfg.Journal.Sink {
+	case "", "memory", "database":
+	case "file":
+		if cfg.Journal.Path == "" {
+			return errors.New("journal path is required when the journal sink is file")
+		}
+	default:
+		return errors.New("journal sink must be one of memory, file, database")
+	}
+	if cfg.Journal.Sink == "database" && (cfg.Database.Driver == "memory" || cfg.Database.Driver == "file") {
+		return errors.New("journal sink database requires the mysql or sqlite database driver")
+	}
+	if cfg.Routing.RefreshInterval < 0 {
+		return errors.New("routing refresh interval must not be negative")
+	}
+	return validateAuth(&cfg.Auth)
+}
+
+func validateAuth(cfg *AuthConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	validRole := func(role string) bool {
+		return role == "viewer" || role == "editor" || role == "admin"
+	}
+	for _, token := range cfg.Tokens {
+		if token.Token == "" || !validRole(token.Role) {
+			return fmt.Errorf("auth token %q requires a token and a role of viewer, editor or admin", token.Name)
+		}
+	}
+	for _, key := range cfg.HMACKeys {
+		if key.KeyId == "" || key.Secret == "" || !validRole(key.Role) {
+			return fmt.Errorf("auth hmac key %q requires a key_id, a secret and a role of viewer, editor or admin", key.KeyId)
+		}
+	}
+	if cfg.ApiKeyRole != "" && !validRole(cfg.ApiKeyRole) {
+		return errors.New("auth api key role must be one of viewer, editor, admin")
+	}
+	if cfg.HMACMaxSkew < 0 || cfg.HMACMaxBodyBytes < 0 || cfg.JWT.Leeway < 0 {
+		return errors.New("auth hmac max skew, hmac max body bytes and jwt leeway must not be negative")
 	}
 	return nil
 }
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"kite/internal/configs"
+	KiteLogger "kite/pkg/logger"
+	"time"
+)
+
+// 存储驱动，memory 与 file 不使用数据库连接
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+	DriverMemory = "memory"
+	DriverFile   = "file"
+)
+
+// Connection 数据库连接，memory 与 file 驱动下 db 为 nil
+type Connection struct {
+	db     *gorm.DB
+	driver string
+}
+
+// NewConnection 根据配置的驱动建立数据库连接，连接失败时返回错误由调用方决定如何处理
+func NewConnection(cfg *configs.DatabaseConfig) (*Connection, error) {
+	driver := cfg.Driver
+	if driver == "" {
+		driver = DriverMySQL
+	}
+	var (
+		db  *gorm.DB
+		err error
+	)
+	switch driver {
+	case DriverMySQL:
+		db, err = InitDB(cfg)
+	case DriverSQLite:
+		db, err = InitSQLite(cfg)
+	case DriverMemory, DriverFile:
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Connection{db: db, driver: driver}, nil
+}
+
+// Driver 返回使用的存储驱动
+func (c *Connection) Driver() string {
+	return c.driver
+}
+
+func (c *Connection) GetDB() *gorm.DB {
+	return c.db
+}
+
+func (c *Connection) CloseDB() error {
+	if c.db == nil {
+		return nil
+	}
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	if err := sqlDB.Close(); err != nil {
+		return fmt.Errorf("failed to close database connection: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connection) WithTransaction(txFn func(tx *gorm.DB) error) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		return txFn(tx)
+	})
+}
+
+func newGormConfig() *gorm.Config {
+	return &gorm.Config{
+		SkipDefaultTransaction: true,
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: false,
+		},
+		// 简直自动创建外建约束
+		DisableForeignKeyConstraintWhenMigrating: true,
+		// 日志配置
+		Logger: logger.New(
+			&GormLogWriter{},
+			logger.Config{
+				SlowThreshold:             time.Second,
+				LogLevel:                  logger.Info,
+				IgnoreRecordNotFoundError: true,
+				Colorful:                  false,
+			},
+		),
+	}
+}
+
+type GormLogWriter struct{}
+
+func (w *GormLogWriter) Printf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	KiteLogger.Debug("gorm", zap.String("sql", msg))
+}
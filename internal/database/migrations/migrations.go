@@ -0,0 +1,319 @@
+// Package migrations 按版本管理数据库表结构。每个驱动的脚本放在以驱动命名的目录中，
+// 文件名为 <版本>_<名称>.up.sql 与 <版本>_<名称>.down.sql，已执行的版本记录在 schema_migrations 表中
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"kite/internal/models"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed mysql/*.sql sqlite/*.sql
+var scripts embed.FS
+
+// lockName 多个实例同时启动时通过 MySQL 的命名锁保证只有一个实例执行迁移
+const (
+	lockName    = "kite_schema_migrations"
+	lockTimeout = 60
+)
+
+// Migration 一个版本的升级与回滚脚本
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+// Status 迁移的执行状态，Known 为 false 表示数据库中记录了当前程序不认识的版本
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Known     bool
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New 加载数据库驱动对应的迁移脚本
+func New(db *gorm.DB) (*Migrator, error) {
+	if db == nil {
+		return nil, errors.New("migrations are only supported by the mysql and sqlite drivers")
+	}
+	migrations, err := load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// load 读取脚本目录，每个版本必须同时提供 up 与 down 脚本
+func load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(scripts, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database driver %q", dialect)
+	}
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		prefix, title, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %s", name)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", name)
+		}
+		data, err := scripts.ReadFile(path.Join(dialect, name))
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: title}
+			byVersion[version] = migration
+		} else if migration.Name != title {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, migration.Name, title)
+		}
+		if direction == "up" {
+			migration.up = string(data)
+		} else {
+			migration.down = string(data)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d_%s requires both up and down scripts", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+	return migrations, nil
+}
+
+// Latest 最新的迁移版本
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up 执行全部未执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down 按版本从新到旧回滚 steps 个已执行的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be greater than 0")
+	}
+	var done []Migration
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		applied, err := appliedVersions(db)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.rollback(db, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// To 升级或回滚到指定版本：执行不超过该版本的未执行迁移，回滚高于该版本的已执行迁移
+func (m *Migrator) To(ctx context.Context, version int) ([]Migration, error) {
+	if version < 0 {
+		return nil, errors.New("version must not be negative")
+	}
+	if version != 0 && !slices.ContainsFunc(m.migrations, func(migration Migration) bool { return migration.Version == version }) {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+	var done []Migration
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		applied, err := appliedVersions(db)
+		if err != nil {
+			return err
+		}
+		for appliedVersion := range applied {
+			if appliedVersion > m.Latest() {
+				return fmt.Errorf("database schema version %d is newer than the latest known version %d", appliedVersion, m.Latest())
+			}
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := m.rollback(db, migration); err != nil {
+					return err
+				}
+				done = append(done, migration)
+			}
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.apply(db, migration); err != nil {
+					return err
+				}
+				done = append(done, migration)
+			}
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status 返回全部迁移的执行状态，按版本排序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	db := m.db.WithContext(ctx)
+	if err := ensureTable(db); err != nil {
+		return nil, err
+	}
+	var records []models.SchemaMigration
+	if err := db.Order("version ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, Status{Version: migration.Version, Name: migration.Name, Known: true})
+	}
+	for _, record := range records {
+		index := slices.IndexFunc(statuses, func(status Status) bool { return status.Version == record.Version })
+		if index < 0 {
+			statuses = append(statuses, Status{Version: record.Version, Name: record.Name})
+			index = len(statuses) - 1
+		}
+		statuses[index].Applied = true
+		statuses[index].AppliedAt = record.AppliedAt
+	}
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return a.Version - b.Version
+	})
+	return statuses, nil
+}
+
+// apply 执行升级脚本并记录版本，sqlite 的 DDL 支持事务，失败时整体回滚；MySQL 的 DDL 会隐式提交
+func (m *Migrator) apply(db *gorm.DB, migration Migration) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := execScript(tx, migration.up); err != nil {
+			return err
+		}
+		return tx.Create(&models.SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) rollback(db *gorm.DB, migration Migration) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := execScript(tx, migration.down); err != nil {
+			return err
+		}
+		return tx.Where("version = ?", migration.Version).Delete(&models.SchemaMigration{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// withLock 在同一个连接上持有命名锁并执行迁移，sqlite 只有一个连接，无需加锁
+func (m *Migrator) withLock(ctx context.Context, fn func(db *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if db.Dialector.Name() != "mysql" {
+		if err := ensureTable(db); err != nil {
+			return err
+		}
+		return fn(db)
+	}
+	return db.Connection(func(conn *gorm.DB) error {
+		var locked int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if locked != 1 {
+			return errors.New("timed out waiting for another instance to finish migrating")
+		}
+		defer conn.Exec("SELECT RELEASE_LOCK(?)", lockName)
+		if err := ensureTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func ensureTable(db *gorm.DB) error {
+	ddl := "CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at DATETIME NOT NULL)"
+	if db.Dialector.Name() == "mysql" {
+		ddl = "CREATE TABLE IF NOT EXISTS `schema_migrations` (`version` BIGINT NOT NULL, `name` VARCHAR(255) NOT NULL, `applied_at` DATETIME NOT NULL, PRIMARY KEY (`version`)) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4"
+	}
+	return db.Exec(ddl).Error
+}
+
+func appliedVersions(db *gorm.DB) (map[int]struct{}, error) {
+	var versions []int
+	if err := db.Model(&models.SchemaMigration{}).Pluck("version", &versions).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]struct{}, len(versions))
+	for _, version := range versions {
+		applied[version] = struct{}{}
+	}
+	return applied, nil
+}
+
+// execScript 逐条执行脚本中的语句，MySQL 驱动默认不允许一次执行多条语句。
+// 语句按分号拆分，脚本的字符串中不能包含分号
+func execScript(db *gorm.DB, script string) error {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(script, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement == "" {
+			continue
+		}
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"context"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+func TestDialectsShareVersions(t *testing.T) {
+	mysql, err := load("mysql")
+	if err != nil {
+		t.Fatalf("load mysql: %v", err)
+	}
+	sqlite, err := load("sqlite")
+	if err != nil {
+		t.Fatalf("load sqlite: %v", err)
+	}
+	if len(mysql) != len(sqlite) {
+		t.Fatalf("mysql has %d migrations, sqlite has %d", len(mysql), len(sqlite))
+	}
+	for i := range mysql {
+		if mysql[i].Version != i+1 {
+			t.Fatalf("migration %d_%s should be version %d", mysql[i].Version, mysql[i].Name, i+1)
+		}
+		if mysql[i].Version != sqlite[i].Version || mysql[i].Name != sqlite[i].Name {
+			t.Fatalf("mysql %d_%s and sqlite %d_%s differ", mysql[i].Version, mysql[i].Name, sqlite[i].Version, sqlite[i].Name)
+		}
+	}
+}
+
+func TestSQLiteUpDownUp(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "mock.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	steps := []struct {
+		name    string
+		run     func() ([]Migration, error)
+		applied int
+	}{
+		{name: "up", run: func() ([]Migration, error) { return migrator.Up(ctx) }, applied: migrator.Latest()},
+		{name: "up again", run: func() ([]Migration, error) { return migrator.Up(ctx) }, applied: 0},
+		{name: "down to nothing", run: func() ([]Migration, error) { return migrator.To(ctx, 0) }, applied: migrator.Latest()},
+		{name: "up after down", run: func() ([]Migration, error) { return migrator.Up(ctx) }, applied: migrator.Latest()},
+	}
+	for _, step := range steps {
+		done, err := step.run()
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if len(done) != step.applied {
+			t.Fatalf("%s: ran %d migrations, want %d", step.name, len(done), step.applied)
+		}
+	}
+	if !db.Migrator().HasColumn("apis", "tags") || !db.Migrator().HasColumn("namespaces", "openapi_spec_hash") {
+		t.Fatalf("latest schema is missing columns")
+	}
+}
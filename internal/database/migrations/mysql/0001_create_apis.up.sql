@@ -0,0 +1,29 @@
+-- 首个发布版本的 apis 表，没有迁移之前的安装中这张表已经存在，只包含这些列。
+-- 已有的表被沿用并统一列的类型，之后新增的列由后续迁移添加；
+-- 已有的表缺少这些列时 MODIFY 失败，迁移中止而不是在不完整的表结构上继续
+CREATE TABLE IF NOT EXISTS `apis` (
+    `id`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `user_id`       VARCHAR(255)    NOT NULL,
+    `uuid`          VARCHAR(255)    NOT NULL,
+    `path`          VARCHAR(1024)   NOT NULL,
+    `method`        VARCHAR(32)     NOT NULL,
+    `status_code`   INT             NOT NULL,
+    `content_type`  VARCHAR(128)    NOT NULL,
+    `headers`       JSON            NULL,
+    `response_body` TEXT            NOT NULL,
+    `created_at`    TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE `apis`
+    MODIFY `user_id`       VARCHAR(255)  NOT NULL,
+    MODIFY `uuid`          VARCHAR(255)  NOT NULL,
+    MODIFY `path`          VARCHAR(1024) NOT NULL,
+    MODIFY `method`        VARCHAR(32)   NOT NULL,
+    MODIFY `status_code`   INT           NOT NULL,
+    MODIFY `content_type`  VARCHAR(128)  NOT NULL,
+    MODIFY `headers`       JSON          NULL,
+    MODIFY `response_body` TEXT          NOT NULL,
+    MODIFY `created_at`    TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    MODIFY `updated_at`    TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP;
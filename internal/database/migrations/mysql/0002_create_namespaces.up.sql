@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS `namespaces` (
+    `id`               BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `uid`              VARCHAR(255)    NOT NULL,
+    `not_found_status` INT             NOT NULL DEFAULT 404,
+    `delay`            JSON            NULL,
+    `throttle_bps`     INT             NOT NULL DEFAULT 0,
+    `mode`             VARCHAR(16)     NOT NULL DEFAULT 'mock',
+    `upstream_url`     VARCHAR(1024)   NOT NULL DEFAULT '',
+    `record_config`    JSON            NULL,
+    `fallback_url`     VARCHAR(1024)   NOT NULL DEFAULT '',
+    `rewrite_rules`    JSON            NULL,
+    `openapi_spec`     LONGTEXT        NULL,
+    `created_at`       TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_namespaces_uid` (`uid`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `journal_entries` (
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `uuid`           VARCHAR(64)     NOT NULL,
+    `uid`            VARCHAR(255)    NOT NULL,
+    `method`         VARCHAR(32)     NOT NULL,
+    `path`           VARCHAR(1024)   NOT NULL,
+    `query`          TEXT            NOT NULL,
+    `headers`        JSON            NULL,
+    `body`           MEDIUMTEXT      NOT NULL,
+    `body_truncated` TINYINT(1)      NOT NULL DEFAULT 0,
+    `matched_uuid`   VARCHAR(255)    NOT NULL DEFAULT '',
+    `status_code`    INT             NOT NULL,
+    `latency_ms`     DOUBLE          NOT NULL,
+    `request_id`     VARCHAR(64)     NOT NULL DEFAULT '',
+    `created_at`     DATETIME(6)     NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_journal_uid_created` (`uid`, `created_at`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,2 @@
+DROP INDEX `idx_apis_uuid` ON `apis`;
+DROP INDEX `idx_apis_user_method_path` ON `apis`;
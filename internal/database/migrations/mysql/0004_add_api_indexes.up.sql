@@ -0,0 +1,3 @@
+-- 路径只索引前缀，避免超出 InnoDB 单个索引 3072 字节的限制
+CREATE INDEX `idx_apis_user_method_path` ON `apis` (`user_id`, `method`, `path`(255));
+CREATE UNIQUE INDEX `idx_apis_uuid` ON `apis` (`uuid`);
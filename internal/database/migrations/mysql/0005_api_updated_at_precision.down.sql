@@ -0,0 +1 @@
+ALTER TABLE `apis` MODIFY `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
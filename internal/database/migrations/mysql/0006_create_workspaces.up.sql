@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS `workspaces` (
+    `id`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `uuid`       VARCHAR(64)     NOT NULL,
+    `name`       VARCHAR(255)    NOT NULL,
+    `created_at` TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_workspaces_uuid` (`uuid`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `workspace_members` (
+    `id`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `workspace_id` VARCHAR(64)     NOT NULL,
+    `user_id`      VARCHAR(255)    NOT NULL,
+    `created_at`   TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_workspace_members_user` (`workspace_id`, `user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `api_keys` (
+    `id`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `uuid`         VARCHAR(64)     NOT NULL,
+    `workspace_id` VARCHAR(64)     NOT NULL,
+    `name`         VARCHAR(255)    NOT NULL DEFAULT '',
+    `prefix`       VARCHAR(16)     NOT NULL,
+    `hash`         CHAR(64)        NOT NULL,
+    `created_at`   TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_api_keys_uuid` (`uuid`),
+    UNIQUE KEY `idx_api_keys_hash` (`hash`),
+    KEY `idx_api_keys_workspace` (`workspace_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- 已有的 mock 与命名空间配置不属于任何工作区，需要通过 workspace assign 命令分配
+ALTER TABLE `apis` ADD COLUMN `workspace_id` VARCHAR(64) NOT NULL DEFAULT '' AFTER `id`;
+CREATE INDEX `idx_apis_workspace` ON `apis` (`workspace_id`);
+ALTER TABLE `namespaces` ADD COLUMN `workspace_id` VARCHAR(64) NOT NULL DEFAULT '' AFTER `id`;
@@ -0,0 +1,3 @@
+-- 绑定文档时记录文档的摘要，请求校验器按摘要缓存，无需每次请求都重新计算
+ALTER TABLE `namespaces` ADD COLUMN `openapi_spec_hash` CHAR(64) NOT NULL DEFAULT '' AFTER `openapi_spec`;
+UPDATE `namespaces` SET `openapi_spec_hash` = SHA2(`openapi_spec`, 256) WHERE `openapi_spec` IS NOT NULL AND `openapi_spec` <> '';
@@ -0,0 +1,14 @@
+ALTER TABLE `apis`
+    DROP COLUMN `tags`,
+    DROP COLUMN `new_state`,
+    DROP COLUMN `required_state`,
+    DROP COLUMN `scenario_name`,
+    DROP COLUMN `sequence_mode`,
+    DROP COLUMN `responses`,
+    DROP COLUMN `fault`,
+    DROP COLUMN `throttle_bps`,
+    DROP COLUMN `delay`,
+    DROP COLUMN `template`,
+    DROP COLUMN `priority`,
+    DROP COLUMN `request_matchers`,
+    DROP COLUMN `charset`;
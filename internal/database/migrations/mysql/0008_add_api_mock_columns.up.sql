@@ -0,0 +1,15 @@
+-- 首个发布版本之后 mock 新增的列。已有的 mock 没有字符集，响应的 Content-Type 保持不变
+ALTER TABLE `apis`
+    ADD COLUMN `charset`          VARCHAR(32)  NOT NULL DEFAULT '' AFTER `content_type`,
+    ADD COLUMN `request_matchers` JSON         NULL AFTER `response_body`,
+    ADD COLUMN `priority`         INT          NOT NULL DEFAULT 0 AFTER `request_matchers`,
+    ADD COLUMN `template`         TINYINT(1)   NOT NULL DEFAULT 0 AFTER `priority`,
+    ADD COLUMN `delay`            JSON         NULL AFTER `template`,
+    ADD COLUMN `throttle_bps`     INT          NOT NULL DEFAULT 0 AFTER `delay`,
+    ADD COLUMN `fault`            JSON         NULL AFTER `throttle_bps`,
+    ADD COLUMN `responses`        JSON         NULL AFTER `fault`,
+    ADD COLUMN `sequence_mode`    VARCHAR(16)  NOT NULL DEFAULT '' AFTER `responses`,
+    ADD COLUMN `scenario_name`    VARCHAR(255) NOT NULL DEFAULT '' AFTER `sequence_mode`,
+    ADD COLUMN `required_state`   VARCHAR(255) NOT NULL DEFAULT '' AFTER `scenario_name`,
+    ADD COLUMN `new_state`        VARCHAR(255) NOT NULL DEFAULT '' AFTER `required_state`,
+    ADD COLUMN `tags`             JSON         NULL AFTER `new_state`;
@@ -0,0 +1 @@
+ALTER TABLE `namespaces` MODIFY `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,2 @@
+-- 修改时间精确到微秒，Fingerprint 依赖它感知同一秒内的多次修改
+ALTER TABLE `namespaces` MODIFY `updated_at` TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6);
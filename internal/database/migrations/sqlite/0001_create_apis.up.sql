@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS apis (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id          TEXT     NOT NULL,
+    uuid             TEXT     NOT NULL,
+    path             TEXT     NOT NULL,
+    method           TEXT     NOT NULL,
+    status_code      INTEGER  NOT NULL,
+    content_type     TEXT     NOT NULL,
+    charset          TEXT     NOT NULL,
+    headers          JSON,
+    response_body    TEXT     NOT NULL,
+    request_matchers JSON,
+    priority         INTEGER  NOT NULL DEFAULT 0,
+    template         NUMERIC  NOT NULL DEFAULT 0,
+    delay            JSON,
+    throttle_bps     INTEGER  NOT NULL DEFAULT 0,
+    fault            JSON,
+    responses        JSON,
+    sequence_mode    TEXT     NOT NULL DEFAULT '',
+    scenario_name    TEXT     NOT NULL DEFAULT '',
+    required_state   TEXT     NOT NULL DEFAULT '',
+    new_state        TEXT     NOT NULL DEFAULT '',
+    tags             JSON,
+    created_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
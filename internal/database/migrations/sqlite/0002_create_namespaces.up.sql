@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS namespaces (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    uid              TEXT     NOT NULL,
+    not_found_status INTEGER  NOT NULL DEFAULT 404,
+    delay            JSON,
+    throttle_bps     INTEGER  NOT NULL DEFAULT 0,
+    mode             TEXT     NOT NULL DEFAULT 'mock',
+    upstream_url     TEXT     NOT NULL DEFAULT '',
+    record_config    JSON,
+    fallback_url     TEXT     NOT NULL DEFAULT '',
+    rewrite_rules    JSON,
+    openapi_spec     TEXT,
+    created_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_namespaces_uid ON namespaces (uid);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid           TEXT     NOT NULL,
+    uid            TEXT     NOT NULL,
+    method         TEXT     NOT NULL,
+    path           TEXT     NOT NULL,
+    query          TEXT     NOT NULL,
+    headers        JSON,
+    body           TEXT     NOT NULL,
+    body_truncated NUMERIC  NOT NULL DEFAULT 0,
+    matched_uuid   TEXT     NOT NULL DEFAULT '',
+    status_code    INTEGER  NOT NULL,
+    latency_ms     REAL     NOT NULL,
+    request_id     TEXT     NOT NULL DEFAULT '',
+    created_at     DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_journal_uid_created ON journal_entries (uid, created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_apis_uuid;
+DROP INDEX IF EXISTS idx_apis_user_method_path;
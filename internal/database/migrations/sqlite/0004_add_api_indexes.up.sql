@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_apis_user_method_path ON apis (user_id, method, path);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_apis_uuid ON apis (uuid);
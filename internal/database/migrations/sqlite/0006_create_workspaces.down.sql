@@ -0,0 +1,6 @@
+ALTER TABLE namespaces DROP COLUMN workspace_id;
+DROP INDEX IF EXISTS idx_apis_workspace;
+ALTER TABLE apis DROP COLUMN workspace_id;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
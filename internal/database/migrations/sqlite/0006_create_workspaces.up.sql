@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid       TEXT     NOT NULL,
+    name       TEXT     NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspaces_uuid ON workspaces (uuid);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    workspace_id TEXT     NOT NULL,
+    user_id      TEXT     NOT NULL,
+    created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_members_user ON workspace_members (workspace_id, user_id);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid         TEXT     NOT NULL,
+    workspace_id TEXT     NOT NULL,
+    name         TEXT     NOT NULL DEFAULT '',
+    prefix       TEXT     NOT NULL,
+    hash         TEXT     NOT NULL,
+    created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_uuid ON api_keys (uuid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_hash ON api_keys (hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_workspace ON api_keys (workspace_id);
+
+-- 已有的 mock 与命名空间配置不属于任何工作区，需要通过 workspace assign 命令分配
+ALTER TABLE apis ADD COLUMN workspace_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_apis_workspace ON apis (workspace_id);
+ALTER TABLE namespaces ADD COLUMN workspace_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- 绑定文档时记录文档的摘要，请求校验器按摘要缓存，无需每次请求都重新计算。
+-- sqlite 没有摘要函数，已绑定的文档保持为空，校验器退化为按 updated_at 缓存，重新绑定后写入摘要
+ALTER TABLE namespaces ADD COLUMN openapi_spec_hash TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+-- sqlite 驱动发布时 mock 的全部列已经存在，0001 直接创建完整的表，无需修改
@@ -0,0 +1 @@
+-- sqlite 的 DATETIME 以文本存储完整精度，无需修改
@@ -5,28 +5,12 @@ import (
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
-	"gorm.io/gorm/schema"
 	"kite/internal/configs"
 	KiteLogger "kite/pkg/logger"
-	"os"
 	"time"
 )
 
-type MySQLConnection struct {
-	db *gorm.DB
-}
-
-func NewMySQLConnection(cfg *configs.MySQLConfig) *MySQLConnection {
-	db, err := InitDB(cfg)
-	if err != nil {
-		KiteLogger.Error("Failed to initialize database", zap.Error(err))
-		os.Exit(1)
-	}
-	return &MySQLConnection{db: db}
-}
-
-func InitDB(cfg *configs.MySQLConfig) (*gorm.DB, error) {
+func InitDB(cfg *configs.DatabaseConfig) (*gorm.DB, error) {
 	var err error
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
@@ -38,27 +22,8 @@ func InitDB(cfg *configs.MySQLConfig) (*gorm.DB, error) {
 		cfg.Charset,
 	)
 
-	gormConfig := &gorm.Config{
-		SkipDefaultTransaction: true,
-		NamingStrategy: schema.NamingStrategy{
-			SingularTable: false,
-		},
-		// 简直自动创建外建约束
-		DisableForeignKeyConstraintWhenMigrating: true,
-		// 日志配置
-		Logger: logger.New(
-			&GormLogWriter{},
-			logger.Config{
-				SlowThreshold:             time.Second,
-				LogLevel:                  logger.Info,
-				IgnoreRecordNotFoundError: true,
-				Colorful:                  false,
-			},
-		),
-	}
-
 	// 连接数据库
-	DB, err := gorm.Open(mysql.Open(dsn), gormConfig)
+	DB, err := gorm.Open(mysql.Open(dsn), newGormConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect database: %w", err)
 	}
@@ -84,35 +49,3 @@ func InitDB(cfg *configs.MySQLConfig) (*gorm.DB, error) {
 
 	return DB, nil
 }
-
-func (c *MySQLConnection) GetDB() *gorm.DB {
-	return c.db
-}
-
-func (c *MySQLConnection) CloseDB() error {
-	if c.db == nil {
-		return nil
-	}
-	sqlDB, err := c.db.DB()
-	if err != nil {
-		return fmt.Errorf("failed to get database connection: %w", err)
-	}
-	if err := sqlDB.Close(); err != nil {
-		return fmt.Errorf("failed to close database connection: %w", err)
-	}
-
-	return nil
-}
-
-func (c *MySQLConnection) WithTransaction(txFn func(tx *gorm.DB) error) error {
-	return c.db.Transaction(func(tx *gorm.DB) error {
-		return txFn(tx)
-	})
-}
-
-type GormLogWriter struct{}
-
-func (w *GormLogWriter) Printf(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	KiteLogger.Debug("gorm", zap.String("sql", msg))
-}
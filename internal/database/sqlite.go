@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"kite/internal/configs"
+	"kite/internal/database/migrations"
+	KiteLogger "kite/pkg/logger"
+	"os"
+	"path/filepath"
+)
+
+// InitSQLite 打开 sqlite 数据库文件，path 为 :memory: 时使用内存数据库。
+// 数据库文件的表结构需要开启 server.auto_migrate 或执行 migrate up 创建；
+// 内存数据库无法由其他进程迁移，打开后直接执行全部迁移
+func InitSQLite(cfg *configs.DatabaseConfig) (*gorm.DB, error) {
+	dsn := cfg.Path
+	if dsn != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(dsn), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+	// 等待锁而不是立即返回 SQLITE_BUSY
+	dsn += "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+	DB, err := gorm.Open(sqlite.Open(dsn), newGormConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	// sqlite 同一时间只允许一个写入者，单连接可以避免写冲突，也让 :memory: 数据库在连接之间共享
+	sqlDB.SetMaxOpenConns(1)
+	KiteLogger.Info("Successfully opened database", zap.String("path", cfg.Path))
+
+	if cfg.Path == ":memory:" {
+		migrator, err := migrations.New(DB)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to migrate in-memory database: %w", err)
+		}
+	}
+
+	return DB, nil
+}
@@ -28,40 +28,73 @@ const (
 	// UserNotFoundError 业务级的错误
 	UserNotFoundError ErrorCode = -3000 - iota
 	ApiCreateError
+	ApiNotFoundError
+	ApiUpdateError
+	ApiDeleteError
+	MockNotMatchedError
+	NamespaceUpdateError
+	TemplateRenderError
+	SequenceExhaustedError
+	ScenarioNotFoundError
+	UpstreamRequestError
+	ImportError
+	OpenApiNotBoundError
 )
 
 // 错误码到 HTTP 状态码的映射
 var errorCodeToHTTPStatus = map[ErrorCode]int{
-	InternalServerError: http.StatusInternalServerError,
-	DatabaseError:       http.StatusInternalServerError,
-	ConfigError:         http.StatusInternalServerError,
-	DataError:           http.StatusInternalServerError,
-	MarshalError:        http.StatusInternalServerError,
-	UnmarshalError:      http.StatusInternalServerError,
-	ValidationError:     http.StatusBadRequest,
-	BadRequestError:     http.StatusBadRequest,
-	UnauthorizedError:   http.StatusUnauthorized,
-	ForbiddenError:      http.StatusForbidden,
-	NotFoundError:       http.StatusNotFound,
-	UserNotFoundError:   http.StatusNotFound,
-	ApiCreateError:      http.StatusBadRequest,
+	InternalServerError:    http.StatusInternalServerError,
+	DatabaseError:          http.StatusInternalServerError,
+	ConfigError:            http.StatusInternalServerError,
+	DataError:              http.StatusInternalServerError,
+	MarshalError:           http.StatusInternalServerError,
+	UnmarshalError:         http.StatusInternalServerError,
+	ValidationError:        http.StatusBadRequest,
+	BadRequestError:        http.StatusBadRequest,
+	UnauthorizedError:      http.StatusUnauthorized,
+	ForbiddenError:         http.StatusForbidden,
+	NotFoundError:          http.StatusNotFound,
+	UserNotFoundError:      http.StatusNotFound,
+	ApiCreateError:         http.StatusBadRequest,
+	ApiNotFoundError:       http.StatusNotFound,
+	ApiUpdateError:         http.StatusBadRequest,
+	ApiDeleteError:         http.StatusBadRequest,
+	MockNotMatchedError:    http.StatusNotFound,
+	NamespaceUpdateError:   http.StatusBadRequest,
+	TemplateRenderError:    http.StatusInternalServerError,
+	SequenceExhaustedError: http.StatusInternalServerError,
+	ScenarioNotFoundError:  http.StatusNotFound,
+	UpstreamRequestError:   http.StatusBadGateway,
+	ImportError:            http.StatusBadRequest,
+	OpenApiNotBoundError:   http.StatusNotFound,
 }
 
 // 错误码到消息的映射
 var errorCodeToMessage = map[ErrorCode]string{
-	InternalServerError: "Internal Server Error",
-	DatabaseError:       "Internal Server Error",
-	ConfigError:         "Internal Server Error",
-	DataError:           "Internal Server Error",
-	MarshalError:        "Internal Server Error",
-	UnmarshalError:      "Internal Server Error",
-	ValidationError:     "Validation Error",
-	BadRequestError:     "Bad Request",
-	UnauthorizedError:   "Unauthorized",
-	ForbiddenError:      "Forbidden",
-	NotFoundError:       "Not Found",
-	UserNotFoundError:   "User Not Found",
-	ApiCreateError:      "Api Create Error",
+	InternalServerError:    "Internal Server Error",
+	DatabaseError:          "Internal Server Error",
+	ConfigError:            "Internal Server Error",
+	DataError:              "Internal Server Error",
+	MarshalError:           "Internal Server Error",
+	UnmarshalError:         "Internal Server Error",
+	ValidationError:        "Validation Error",
+	BadRequestError:        "Bad Request",
+	UnauthorizedError:      "Unauthorized",
+	ForbiddenError:         "Forbidden",
+	NotFoundError:          "Not Found",
+	UserNotFoundError:      "User Not Found",
+	ApiCreateError:         "Api Create Error",
+	ApiNotFoundError:       "Api Not Found",
+	ApiUpdateError:         "Api Update Error",
+	ApiDeleteError:         "Api Delete Error",
+	MockNotMatchedError:    "No Mock Matched",
+	NamespaceUpdateError:   "Namespace Update Error",
+	TemplateRenderError:    "Template Render Error",
+	SequenceExhaustedError: "Response Sequence Exhausted",
+	ScenarioNotFoundError:  "Scenario Not Found",
+	UpstreamRequestError:   "Upstream Request Failed",
+	ImportError:            "Import Error",
+	OpenApiNotBoundError:   "OpenAPI Document Not Bound",
 }
 
 type AppError struct {
@@ -70,6 +103,7 @@ type AppError struct {
 	Detail     string
 	Err        error
 	HTTPStatus int
+	Data       interface{}
 }
 
 func (e *AppError) Error() string {
@@ -107,6 +141,18 @@ func (e *AppError) WithDetail(detail string) *AppError {
 	return e
 }
 
+// WithHTTPStatus 覆盖错误码默认对应的 HTTP 状态码
+func (e *AppError) WithHTTPStatus(status int) *AppError {
+	e.HTTPStatus = status
+	return e
+}
+
+// WithData 附加结构化的错误详情，会随错误响应一起返回
+func (e *AppError) WithData(data interface{}) *AppError {
+	e.Data = data
+	return e
+}
+
 func IsAppError(err error) (*AppError, bool) {
 	if err == nil {
 		return nil, false
@@ -0,0 +1,76 @@
+package importers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"kite/internal/models"
+	"net/url"
+)
+
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method string `json:"method"`
+		Url    string `json:"url"`
+	} `json:"request"`
+	Response struct {
+		Status  int         `json:"status"`
+		Headers []nameValue `json:"headers"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+// FromHAR 将 HAR 文件中的每个请求转换为 mock，只保留 URL 的路径部分；
+// 没有拿到响应的请求（status 为 0，例如被取消的请求）会被跳过
+func FromHAR(data []byte, uid string) ([]*models.Api, []Skipped, error) {
+	var file harFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("invalid HAR file: %w", err)
+	}
+	apis := make([]*models.Api, 0, len(file.Log.Entries))
+	skipped := make([]Skipped, 0)
+	for _, entry := range file.Log.Entries {
+		parsed, err := url.Parse(entry.Request.Url)
+		if err != nil {
+			skipped = append(skipped, Skipped{Method: entry.Request.Method, Path: entry.Request.Url, Reason: "invalid url"})
+			continue
+		}
+		path := parsed.Path
+		if path == "" {
+			path = "/"
+		}
+		if entry.Response.Status == 0 {
+			skipped = append(skipped, Skipped{Method: entry.Request.Method, Path: path, Reason: "no response recorded"})
+			continue
+		}
+		body := entry.Response.Content.Text
+		if entry.Response.Content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(body)
+			if err != nil {
+				skipped = append(skipped, Skipped{Method: entry.Request.Method, Path: path, Reason: "invalid base64 response body"})
+				continue
+			}
+			body = string(decoded)
+		}
+		headers, contentType := responseHeaders(normalizeNames(entry.Response.Headers))
+		if entry.Response.Content.MimeType != "" {
+			contentType = entry.Response.Content.MimeType
+		}
+		api, err := newApi(uid, entry.Request.Method, path, entry.Response.Status, contentType, headers, body, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		apis = append(apis, api)
+	}
+	return apis, skipped, nil
+}
@@ -0,0 +1,43 @@
+package importers
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFromHAR(t *testing.T) {
+	data, err := os.ReadFile("testdata/session.har")
+	if err != nil {
+		t.Fatal(err)
+	}
+	apis, skipped, err := FromHAR(data, "pets")
+	if err != nil {
+		t.Fatalf("FromHAR: %v", err)
+	}
+	checkImported(t, apis, []importedMock{
+		// 只保留路径；mimeType 优先于响应头中的 Content-Type，传输层和 HTTP/2 伪头不保留
+		{Method: "GET", Path: "/users/1", Status: 200, ContentType: "application/json", Headers: map[string]string{"X-Request-Id": "abc"}, Body: `{"id":1}`},
+		// base64 编码的响应体解码后保存
+		{Method: "GET", Path: "/logo.png", Status: 200, ContentType: "image/png", Body: "\x89PNG"},
+		{Method: "POST", Path: "/", Status: 204, ContentType: "text/plain"},
+	})
+	wantSkipped := []Skipped{
+		{Method: "GET", Path: "/cancelled", Reason: "no response recorded"},
+		{Method: "GET", Path: "/bad", Reason: "invalid base64 response body"},
+		{Method: "GET", Path: "://bad", Reason: "invalid url"},
+	}
+	if len(skipped) != len(wantSkipped) {
+		t.Fatalf("skipped = %+v, want %+v", skipped, wantSkipped)
+	}
+	for i := range skipped {
+		if skipped[i] != wantSkipped[i] {
+			t.Fatalf("skipped[%d] = %+v, want %+v", i, skipped[i], wantSkipped[i])
+		}
+	}
+}
+
+func TestFromHARRejectsInvalidFiles(t *testing.T) {
+	if _, _, err := FromHAR([]byte(`{"log":`), "pets"); err == nil {
+		t.Fatalf("FromHAR should fail on invalid JSON")
+	}
+}
@@ -0,0 +1,109 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/proxy"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// PreferHeader 同一操作有多个响应时，客户端通过 Prefer: code=NNN 或 Prefer: example=name 选择非默认的响应
+const PreferHeader = "Prefer"
+
+// Skipped 导入时被跳过的条目及原因
+type Skipped struct {
+	Method string
+	Path   string
+	Reason string
+}
+
+// newApi 根据导入的响应生成 mock，Content-Type 中的字符集单独存储
+func newApi(uid string, method string, path string, status int, rawContentType string, headers map[string]string, body string, matchers *matching.RequestMatchers) (*models.Api, error) {
+	contentType, charset := rawContentType, ""
+	if mediaType, params, err := mime.ParseMediaType(rawContentType); err == nil {
+		contentType, charset = mediaType, params["charset"]
+	}
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+	var rawMatchers json.RawMessage
+	if matchers != nil {
+		if rawMatchers, err = json.Marshal(matchers); err != nil {
+			return nil, err
+		}
+	}
+	return &models.Api{
+		UserId:          uid,
+		Uuid:            uuid2.NewString(),
+		Path:            path,
+		Method:          strings.ToUpper(method),
+		StatusCode:      int16(status),
+		ContentType:     contentType,
+		Charset:         charset,
+		Headers:         encodedHeaders,
+		ResponseBody:    body,
+		RequestMatchers: rawMatchers,
+	}, nil
+}
+
+// preferMatchers 要求请求头 Prefer 中包含偏好 name=value，值可以带引号，多个偏好以逗号或分号分隔
+func preferMatchers(name string, value string) *matching.RequestMatchers {
+	return &matching.RequestMatchers{
+		Headers: []matching.FieldMatcher{{
+			Name:     PreferHeader,
+			Operator: matching.OperatorRegex,
+			Value:    fmt.Sprintf(`(^|[\s,;])%s="?%s"?($|[\s,;])`, name, regexp.QuoteMeta(value)),
+		}},
+	}
+}
+
+// responseHeaders 过滤掉由传输层维护的响应头，Content-Type 单独返回
+func responseHeaders(pairs []nameValue) (map[string]string, string) {
+	headers := make(map[string]string)
+	contentType := ""
+	for _, pair := range pairs {
+		key := http.CanonicalHeaderKey(pair.Name)
+		if key == "" || proxy.IsHopHeader(key) || strings.HasPrefix(key, ":") {
+			continue
+		}
+		if key == "Content-Type" {
+			contentType = pair.Value
+			continue
+		}
+		headers[key] = pair.Value
+	}
+	return headers, contentType
+}
+
+// nameValue HAR 和 Postman 中请求头的共同格式
+type nameValue struct {
+	Name     string `json:"name"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+// normalizeNames Postman 使用 key 表示名称，HAR 使用 name
+func normalizeNames(pairs []nameValue) []nameValue {
+	result := make([]nameValue, 0, len(pairs))
+	for _, pair := range pairs {
+		if pair.Disabled {
+			continue
+		}
+		if pair.Name == "" {
+			pair.Name = pair.Key
+		}
+		result = append(result, pair)
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package importers
+
+import (
+	"fmt"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/openapi"
+	"strconv"
+)
+
+// FromOpenAPI 为规范中每个操作的每个响应码生成一个 mock。
+// 默认响应（最小的 2xx，没有 2xx 时取最小的响应码）不带匹配条件，
+// 其余响应要求请求头 Prefer 中包含 code=NNN
+func FromOpenAPI(spec *openapi.Spec, uid string) ([]*models.Api, error) {
+	apis := make([]*models.Api, 0)
+	for _, operation := range spec.Operations {
+		if len(operation.Responses) == 0 {
+			continue
+		}
+		primary := defaultResponse(operation.Responses)
+		for i, response := range operation.Responses {
+			var matchers *matching.RequestMatchers
+			if i != primary {
+				matchers = preferMatchers("code", strconv.Itoa(response.StatusCode))
+			}
+			api, err := newApi(uid, operation.Method, operation.Path, response.StatusCode, response.ContentType, response.Headers, response.Body, matchers)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", operation.Method, operation.Path, err)
+			}
+			apis = append(apis, api)
+		}
+	}
+	return apis, nil
+}
+
+// defaultResponse 返回不需要 Prefer 头就能命中的响应的下标，响应已按状态码排序
+func defaultResponse(responses []openapi.Response) int {
+	for i, response := range responses {
+		if response.StatusCode >= 200 && response.StatusCode < 300 {
+			return i
+		}
+	}
+	return 0
+}
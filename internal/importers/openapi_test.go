@@ -0,0 +1,164 @@
+package importers
+
+import (
+	"encoding/json"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/openapi"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// importedMock 导入结果中需要比较的字段，Prefer 为 false 表示不带匹配条件的默认响应
+type importedMock struct {
+	Method      string
+	Path        string
+	Status      int
+	ContentType string
+	Headers     map[string]string
+	Body        string
+	Prefer      bool
+}
+
+func importFixture(t *testing.T, name string) (*openapi.Spec, []*models.Api) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := openapi.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	apis, err := FromOpenAPI(spec, "pets")
+	if err != nil {
+		t.Fatalf("FromOpenAPI: %v", err)
+	}
+	return spec, apis
+}
+
+func checkImported(t *testing.T, apis []*models.Api, want []importedMock) {
+	t.Helper()
+	if len(apis) != len(want) {
+		t.Fatalf("imported %d mocks, want %d", len(apis), len(want))
+	}
+	for i, api := range apis {
+		w := want[i]
+		var headers map[string]string
+		if err := json.Unmarshal(api.Headers, &headers); err != nil {
+			t.Fatal(err)
+		}
+		if w.Headers == nil {
+			w.Headers = map[string]string{}
+		}
+		got := importedMock{api.Method, api.Path, int(api.StatusCode), api.ContentType, headers, api.ResponseBody, len(api.RequestMatchers) > 0}
+		if api.UserId != "pets" || got.Method != w.Method || got.Path != w.Path || got.Status != w.Status ||
+			got.ContentType != w.ContentType || got.Body != w.Body || got.Prefer != w.Prefer || len(headers) != len(w.Headers) {
+			t.Fatalf("mock %d = %+v, want %+v", i, got, w)
+		}
+		for key, value := range w.Headers {
+			if headers[key] != value {
+				t.Fatalf("mock %d header %s = %q, want %q", i, key, headers[key], value)
+			}
+		}
+		if _, err := matching.ParsePath(api.Path); err != nil {
+			t.Fatalf("mock %d path %s is not a valid pattern: %v", i, api.Path, err)
+		}
+	}
+}
+
+func TestFromOpenAPIv3(t *testing.T) {
+	spec, apis := importFixture(t, "petstore-v3.yaml")
+	if spec.Version != "3.0.3" || spec.Title != "Petstore" {
+		t.Fatalf("spec = %s %s", spec.Version, spec.Title)
+	}
+	checkImported(t, apis, []importedMock{
+		// 与静态文本混在同一段中的参数转换为带正则的参数，schema 的 default 作为示例
+		{Method: "GET", Path: `/files/{name:.+\.json}`, Status: 200, ContentType: "application/json", Body: `{"size":512}`},
+		// 优先选择 application/json 的 example，default 响应没有状态码，忽略
+		{Method: "GET", Path: "/pets", Status: 200, ContentType: "application/json", Body: `[{"id":1,"name":"Rex"}]`},
+		// 通过 $ref 引用的 schema 生成示例，响应头取 example
+		{Method: "POST", Path: "/pets", Status: 201, ContentType: "application/json", Headers: map[string]string{"Location": "/pets/2"}, Body: `{"id":7,"name":"Rex","tag":"dog"}`},
+		{Method: "POST", Path: "/pets", Status: 400, ContentType: "text/plain", Body: "name is required", Prefer: true},
+		// 5XX 取该范围的第一个码，响应本身通过 $ref 引用
+		{Method: "POST", Path: "/pets", Status: 500, ContentType: "application/json", Body: `{"error":"internal"}`, Prefer: true},
+		// 整数路径参数加上数字约束，路径级参数对操作生效
+		{Method: "GET", Path: "/pets/{petId:-?[0-9]+}", Status: 200, ContentType: "application/json", Body: `{"id":7,"name":"Rex","tag":"dog"}`},
+		// examples 按名称排序取第一个，示例本身通过 $ref 引用
+		{Method: "GET", Path: "/pets/{petId:-?[0-9]+}", Status: 404, ContentType: "application/json", Body: `{"error":"pet was adopted"}`, Prefer: true},
+	})
+}
+
+func TestFromOpenAPIv2(t *testing.T) {
+	spec, apis := importFixture(t, "petstore-v2.json")
+	if spec.Version != "2.0" || spec.Title != "Users" {
+		t.Fatalf("spec = %s %s", spec.Version, spec.Title)
+	}
+	checkImported(t, apis, []importedMock{
+		// 媒体类型来自全局 produces，响应头取 x-example，数组元素来自 $ref 的 enum
+		{Method: "GET", Path: "/users/{id:-?[0-9]+}", Status: 200, ContentType: "application/json", Headers: map[string]string{"X-Rate-Limit": "100"}, Body: `{"id":42,"roles":["admin"]}`},
+		{Method: "GET", Path: "/users/{id:-?[0-9]+}", Status: 404, ContentType: "application/json", Body: `{"message":"user not found"}`, Prefer: true},
+		// 没有响应体的响应使用默认的 text/plain，参数只声明在 get 上，delete 的路径不加约束
+		{Method: "DELETE", Path: "/users/{id}", Status: 204, ContentType: "text/plain", Body: ""},
+		{Method: "DELETE", Path: "/users/{id}", Status: 409, ContentType: "text/plain", Body: "user has orders", Prefer: true},
+	})
+}
+
+func TestFromOpenAPIPreferMapping(t *testing.T) {
+	_, apis := importFixture(t, "petstore-v3.yaml")
+	var postPets []*models.Api
+	for _, api := range apis {
+		if api.Method == http.MethodPost && api.Path == "/pets" {
+			postPets = append(postPets, api)
+		}
+	}
+	tests := []struct {
+		prefer string
+		want   []int16
+	}{
+		{"", []int16{201}},
+		{"code=400", []int16{201, 400}},
+		{"return=minimal, code=500", []int16{201, 500}},
+		{"code=4000", []int16{201}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefer, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/pets", nil)
+			if tt.prefer != "" {
+				r.Header.Set(PreferHeader, tt.prefer)
+			}
+			req, err := matching.NewRequest(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var matched []int16
+			for _, api := range postPets {
+				matchers, err := matching.DecodeRequestMatchers(api.RequestMatchers)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ok, _ := matchers.Match(req); ok {
+					matched = append(matched, api.StatusCode)
+				}
+			}
+			if len(matched) != len(tt.want) {
+				t.Fatalf("Prefer %q matched %v, want %v", tt.prefer, matched, tt.want)
+			}
+			for i := range matched {
+				if matched[i] != tt.want[i] {
+					t.Fatalf("Prefer %q matched %v, want %v", tt.prefer, matched, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRejectsUnknownDocuments(t *testing.T) {
+	for _, data := range []string{`{"info":{"title":"x"}}`, `swagger: "1.2"`, `not: [valid`} {
+		if _, err := openapi.Parse([]byte(data)); err == nil {
+			t.Fatalf("Parse(%q) should fail", data)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type postmanCollection struct {
+	Info struct {
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item []postmanItem `json:"item"`
+}
+
+// postmanItem 既可以是文件夹（包含 item），也可以是请求（包含 request）
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Item     []postmanItem     `json:"item"`
+	Request  *postmanRequest   `json:"request"`
+	Response []postmanResponse `json:"response"`
+}
+
+type postmanRequest struct {
+	Method string          `json:"method"`
+	Url    json.RawMessage `json:"url"`
+}
+
+// postmanResponse 请求上保存的示例响应
+type postmanResponse struct {
+	Name   string      `json:"name"`
+	Code   int         `json:"code"`
+	Header []nameValue `json:"header"`
+	Body   string      `json:"body"`
+}
+
+type postmanUrl struct {
+	Raw  string   `json:"raw"`
+	Path []string `json:"path"`
+}
+
+var (
+	// postmanVariable {{name}} 形式的集合变量
+	postmanVariable = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+	// postmanPathParam :name 形式的路径变量
+	postmanPathParam = regexp.MustCompile(`^:(.+)$`)
+)
+
+// FromPostman 将 Postman v2 / v2.1 集合中的每个请求转换为 mock，递归处理文件夹。
+// 请求上保存的每个示例响应生成一个 mock，没有示例时生成一个空的 200 响应；
+// 路径中的 :id 和 {{id}} 转换为路径参数
+func FromPostman(data []byte, uid string) ([]*models.Api, []Skipped, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, nil, fmt.Errorf("invalid Postman collection: %w", err)
+	}
+	if !strings.Contains(collection.Info.Schema, "collection/v2") {
+		return nil, nil, fmt.Errorf("unsupported Postman collection schema %q, expected v2.0 or v2.1", collection.Info.Schema)
+	}
+	apis := make([]*models.Api, 0)
+	skipped := make([]Skipped, 0)
+	var walk func(items []postmanItem) error
+	walk = func(items []postmanItem) error {
+		for _, item := range items {
+			if item.Request == nil {
+				if err := walk(item.Item); err != nil {
+					return err
+				}
+				continue
+			}
+			method := item.Request.Method
+			if method == "" {
+				method = http.MethodGet
+			}
+			path, err := postmanPath(item.Request.Url)
+			if err != nil {
+				skipped = append(skipped, Skipped{Method: method, Path: item.Name, Reason: err.Error()})
+				continue
+			}
+			if len(item.Response) == 0 {
+				api, err := newApi(uid, method, path, http.StatusOK, "", map[string]string{}, "", nil)
+				if err != nil {
+					return err
+				}
+				apis = append(apis, api)
+				continue
+			}
+			for _, example := range postmanExamples(item.Response) {
+				headers, contentType := responseHeaders(normalizeNames(example.Header))
+				api, err := newApi(uid, method, path, example.Code, contentType, headers, example.Body, example.matchers)
+				if err != nil {
+					return err
+				}
+				apis = append(apis, api)
+			}
+		}
+		return nil
+	}
+	if err := walk(collection.Item); err != nil {
+		return nil, nil, err
+	}
+	return apis, skipped, nil
+}
+
+// postmanExample 一个示例响应及选择它所需的匹配条件
+type postmanExample struct {
+	postmanResponse
+	matchers *matching.RequestMatchers
+}
+
+// postmanExamples 与 OpenAPI 导入一致：第一个 2xx 示例（没有 2xx 时取第一个）不带匹配条件，
+// 其余示例要求请求头 Prefer 中包含 code=NNN；多个示例的状态码相同时改用示例名称 example=name
+func postmanExamples(responses []postmanResponse) []postmanExample {
+	primary := 0
+	for i, response := range responses {
+		if response.Code >= 200 && response.Code < 300 {
+			primary = i
+			break
+		}
+	}
+	codes := make(map[int]int, len(responses))
+	for i := range responses {
+		if responses[i].Code == 0 {
+			responses[i].Code = http.StatusOK
+		}
+		codes[responses[i].Code]++
+	}
+	examples := make([]postmanExample, 0, len(responses))
+	for i, response := range responses {
+		example := postmanExample{postmanResponse: response}
+		switch {
+		case i == primary:
+		case codes[response.Code] == 1:
+			example.matchers = preferMatchers("code", strconv.Itoa(response.Code))
+		default:
+			example.matchers = preferMatchers("example", response.Name)
+		}
+		examples = append(examples, example)
+	}
+	return examples
+}
+
+// postmanPath 从 url 中取出路径，url 可以是字符串，也可以是带 path 数组的对象
+func postmanPath(raw json.RawMessage) (string, error) {
+	var url postmanUrl
+	if err := json.Unmarshal(raw, &url.Raw); err != nil {
+		if err := json.Unmarshal(raw, &url); err != nil {
+			return "", fmt.Errorf("invalid url")
+		}
+	}
+	segments := url.Path
+	if segments == nil {
+		segments = splitRawUrl(url.Raw)
+	}
+	if segments == nil {
+		return "", fmt.Errorf("missing url")
+	}
+	for i, segment := range segments {
+		if match := postmanPathParam.FindStringSubmatch(segment); match != nil {
+			segments[i] = "{" + match[1] + "}"
+			continue
+		}
+		segments[i] = postmanVariable.ReplaceAllString(segment, "{$1}")
+	}
+	return "/" + strings.Join(segments, "/"), nil
+}
+
+// splitRawUrl 去掉协议、主机（可以是 {{baseUrl}} 这样的变量）和查询参数，返回路径段
+func splitRawUrl(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	raw, _, _ = strings.Cut(raw, "?")
+	raw, _, _ = strings.Cut(raw, "#")
+	if _, rest, found := strings.Cut(raw, "://"); found {
+		raw = rest
+	}
+	if !strings.HasPrefix(raw, "/") {
+		// 第一段是主机名
+		_, rest, found := strings.Cut(raw, "/")
+		if !found {
+			return []string{}
+		}
+		raw = rest
+	}
+	return strings.Split(strings.TrimPrefix(raw, "/"), "/")
+}
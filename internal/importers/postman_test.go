@@ -0,0 +1,83 @@
+package importers
+
+import (
+	"kite/internal/matching"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestFromPostman(t *testing.T) {
+	data, err := os.ReadFile("testdata/collection.postman.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	apis, skipped, err := FromPostman(data, "pets")
+	if err != nil {
+		t.Fatalf("FromPostman: %v", err)
+	}
+	checkImported(t, apis, []importedMock{
+		// 每个示例生成一个 mock：第一个 2xx 示例是默认响应，状态码唯一的示例按 code 选择，
+		// 状态码重复的示例按名称选择；传输层的响应头和禁用的响应头不保留
+		{Method: "GET", Path: "/users/{id}", Status: 404, ContentType: "application/json", Body: `{"error":"not found"}`, Prefer: true},
+		{Method: "GET", Path: "/users/{id}", Status: 200, ContentType: "application/json", Headers: map[string]string{"X-Trace": "1"}, Body: `{"id":1}`},
+		{Method: "GET", Path: "/users/{id}", Status: 200, ContentType: "application/json", Body: `{"id":1,"admin":true}`, Prefer: true},
+		{Method: "GET", Path: "/users/{id}", Status: 500, ContentType: "text/plain", Body: "oops", Prefer: true},
+		// 没有示例时生成空的 200 响应，{{var}} 转换为路径参数，主机和查询参数被去掉
+		{Method: "GET", Path: "/orders/{orderId}/items", Status: 200, ContentType: "text/plain"},
+		{Method: "POST", Path: "/orders", Status: 201, ContentType: "text/plain", Body: "created"},
+	})
+	if apis[1].Charset != "utf-8" {
+		t.Fatalf("charset = %q, want it split from the Content-Type", apis[1].Charset)
+	}
+	if len(skipped) != 1 || skipped[0].Method != http.MethodDelete || skipped[0].Reason != "invalid url" {
+		t.Fatalf("skipped = %+v", skipped)
+	}
+
+	tests := []struct {
+		prefer string
+		want   []string
+	}{
+		{"", []string{`{"id":1}`}},
+		{"code=404", []string{`{"error":"not found"}`, `{"id":1}`}},
+		{"code=500; respond-async", []string{`{"id":1}`, "oops"}},
+		{`example="Found admin"`, []string{`{"id":1}`, `{"id":1,"admin":true}`}},
+		{"example=Found", []string{`{"id":1}`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefer, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			if tt.prefer != "" {
+				r.Header.Set(PreferHeader, tt.prefer)
+			}
+			req, _ := matching.NewRequest(r)
+			matched := make([]string, 0)
+			for _, api := range apis[:4] {
+				matchers, err := matching.DecodeRequestMatchers(api.RequestMatchers)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ok, _ := matchers.Match(req); ok {
+					matched = append(matched, api.ResponseBody)
+				}
+			}
+			if len(matched) != len(tt.want) {
+				t.Fatalf("Prefer %q matched %v, want %v", tt.prefer, matched, tt.want)
+			}
+			for i := range matched {
+				if matched[i] != tt.want[i] {
+					t.Fatalf("Prefer %q matched %v, want %v", tt.prefer, matched, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFromPostmanRejectsInvalidCollections(t *testing.T) {
+	for _, data := range []string{`{`, `{"info":{"schema":"https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}}`} {
+		if _, _, err := FromPostman([]byte(data), "pets"); err == nil {
+			t.Fatalf("FromPostman(%s) should fail", data)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// DefaultMaxSize 单个文件的默认大小上限（字节），超过后轮转
+	DefaultMaxSize = 100 << 20
+	// DefaultMaxBackups 默认保留的轮转文件数
+	DefaultMaxBackups = 5
+	// maxLineSize 读取时单行的最大长度
+	maxLineSize = 16 << 20
+)
+
+// fileSink 以 JSONL 格式追加写入文件。文件超过 maxSize 后重命名为 path.1，
+// 已有的 path.N 依次后移，超过 maxBackups 的最旧文件被删除
+type fileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func NewFileSink(path string, maxSize int64, maxBackups int) (Sink, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxBackups <= 0 {
+		maxBackups = DefaultMaxBackups
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	s := &fileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) Append(_ context.Context, entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileSink) Search(_ context.Context, filter Filter) ([]*Entry, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matched := make([]*Entry, 0)
+	err := s.each(func(entry *Entry) error {
+		if filter.Match(entry) {
+			matched = append(matched, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	result, total := paginate(matched, filter)
+	return result, total, nil
+}
+
+// Clear 重写每个文件，去掉命名空间下的记录。某个文件重写失败时已重写的文件保持清除后的内容
+func (s *fileSink) Clear(_ context.Context, uid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	var err error
+	for _, path := range s.files() {
+		if err = rewrite(path, func(entry *Entry) bool { return entry.Uid != uid }); err != nil {
+			break
+		}
+	}
+	// 重写失败时也要重新打开文件，否则之后的记录都无法写入
+	if openErr := s.open(); err == nil {
+		err = openErr
+	}
+	return err
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	err := s.shift()
+	// 轮转失败时继续写入当前文件
+	if openErr := s.open(); err == nil {
+		err = openErr
+	}
+	return err
+}
+
+// shift 将现有文件依次后移一位，删除最旧的轮转文件
+func (s *fileSink) shift() error {
+	if err := os.Remove(s.backup(s.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(s.backup(i), s.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(s.path, s.backup(1))
+}
+
+func (s *fileSink) backup(index int) string {
+	return fmt.Sprintf("%s.%d", s.path, index)
+}
+
+// files 按写入顺序返回存在的文件，最旧的轮转文件在前
+func (s *fileSink) files() []string {
+	files := make([]string, 0, s.maxBackups+1)
+	for i := s.maxBackups; i >= 1; i-- {
+		if _, err := os.Stat(s.backup(i)); err == nil {
+			files = append(files, s.backup(i))
+		}
+	}
+	return append(files, s.path)
+}
+
+// each 按写入顺序读取全部记录，无法解析的行被忽略
+func (s *fileSink) each(fn func(entry *Entry) error) error {
+	for _, path := range s.files() {
+		if err := readFile(path, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readFile(path string, fn func(entry *Entry) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// rewrite 只保留 keep 返回 true 的记录，先写入临时文件再替换
+func rewrite(path string, keep func(entry *Entry) bool) error {
+	tmp := path + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(out)
+	err = readFile(path, func(entry *Entry) error {
+		if !keep(entry) {
+			return nil
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(append(line, '\n'))
+		return err
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
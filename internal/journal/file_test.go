@@ -0,0 +1,63 @@
+package journal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSinkClearReopensOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	sink, err := NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	ctx := context.Background()
+	if err := sink.Append(ctx, &Entry{Id: "1", Uid: "ns"}); err != nil {
+		t.Fatal(err)
+	}
+	// 临时文件的位置被目录占用，重写失败
+	if err := os.Mkdir(path+".tmp", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Clear(ctx, "ns"); err == nil {
+		t.Fatalf("Clear should fail when the file cannot be rewritten")
+	}
+	if err := sink.Append(ctx, &Entry{Id: "2", Uid: "ns"}); err != nil {
+		t.Fatalf("Append after a failed Clear: %v", err)
+	}
+	entries, total, err := sink.Search(ctx, Filter{Uid: "ns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(entries) != 2 {
+		t.Fatalf("Search = %d entries, want both entries kept", total)
+	}
+}
+
+func TestIsSensitiveHeader(t *testing.T) {
+	tests := []struct {
+		key       string
+		sensitive bool
+	}{
+		{"Authorization", true},
+		{"authorization", true},
+		{"Proxy-Authorization", true},
+		{"Cookie", true},
+		{"X-Api-Key", true},
+		{"x-api-key", true},
+		{"X-Hub-Signature-256", true},
+		{"X-Kite-Timestamp", false},
+		{"Content-Type", false},
+		{"Set-Cookie-Policy", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := IsSensitiveHeader(tt.key); got != tt.sensitive {
+				t.Fatalf("IsSensitiveHeader(%q) = %v, want %v", tt.key, got, tt.sensitive)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package journal
+
+import (
+	"context"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// ContextKeyMatchedUuid 处理器将命中的 mock uuid 写入 echo.Context 的键，供日志中间件读取
+const ContextKeyMatchedUuid = "journal.matched_uuid"
+
+// 支持的存储方式
+const (
+	SinkMemory   = "memory"
+	SinkFile     = "file"
+	SinkDatabase = "database"
+)
+
+// RedactedValue 携带凭据的请求头记录时使用的值
+const RedactedValue = "[REDACTED]"
+
+// sensitiveHeaders 携带凭据的请求头，HMAC 签名也放在 Authorization 中
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+}
+
+// IsSensitiveHeader 判断请求头是否携带凭据，名称中带有 Signature 的请求头（例如 webhook 签名）同样视为凭据
+func IsSensitiveHeader(key string) bool {
+	key = textproto.CanonicalMIMEHeaderKey(key)
+	return sensitiveHeaders[key] || strings.Contains(key, "Signature")
+}
+
+// Entry 一次 mock 请求的记录
+type Entry struct {
+	Id            string            `json:"id"`
+	Uid           string            `json:"uid"`
+	Method        string            `json:"method"`
+	Path          string            `json:"path"`
+	Query         string            `json:"query"`
+	Headers       map[string]string `json:"headers"`
+	Body          string            `json:"body"`
+	BodyTruncated bool              `json:"body_truncated"`
+	MatchedUuid   string            `json:"matched_uuid"`
+	StatusCode    int               `json:"status_code"`
+	LatencyMs     float64           `json:"latency_ms"`
+	RequestId     string            `json:"request_id"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// Filter 查询条件，空值表示不过滤，Uid 必须指定
+type Filter struct {
+	Uid         string
+	Method      string
+	PathPrefix  string
+	StatusCode  int
+	MatchedUuid string
+	// Matched 为 nil 时不过滤，true 只返回命中 mock 的请求，false 只返回未命中的请求
+	Matched   *bool
+	RequestId string
+	Since     time.Time
+	Until     time.Time
+	Offset    int
+	Limit     int
+}
+
+// Match 判断记录是否满足查询条件，供不支持查询语句的存储使用
+func (f *Filter) Match(entry *Entry) bool {
+	switch {
+	case entry.Uid != f.Uid:
+		return false
+	case f.Method != "" && entry.Method != f.Method:
+		return false
+	case f.PathPrefix != "" && !strings.HasPrefix(entry.Path, f.PathPrefix):
+		return false
+	case f.StatusCode != 0 && entry.StatusCode != f.StatusCode:
+		return false
+	case f.MatchedUuid != "" && entry.MatchedUuid != f.MatchedUuid:
+		return false
+	case f.Matched != nil && *f.Matched != (entry.MatchedUuid != ""):
+		return false
+	case f.RequestId != "" && entry.RequestId != f.RequestId:
+		return false
+	case !f.Since.IsZero() && entry.CreatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && entry.CreatedAt.After(f.Until):
+		return false
+	}
+	return true
+}
+
+// Sink 请求记录的存储
+type Sink interface {
+	Append(ctx context.Context, entry *Entry) error
+	// Search 按时间倒序返回满足条件的记录及总数
+	Search(ctx context.Context, filter Filter) ([]*Entry, int64, error)
+	// Clear 删除命名空间下的全部记录
+	Clear(ctx context.Context, uid string) error
+	Close() error
+}
+
+// paginate 对按时间正序排列的记录倒序分页，供内存和文件存储使用
+func paginate(matched []*Entry, filter Filter) ([]*Entry, int64) {
+	total := int64(len(matched))
+	result := make([]*Entry, 0)
+	for i := len(matched) - 1 - filter.Offset; i >= 0; i-- {
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+		result = append(result, matched[i])
+	}
+	return result, total
+}
@@ -0,0 +1,78 @@
+package journal
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultCapacity 内存存储默认保留的记录数
+const DefaultCapacity = 1000
+
+// memorySink 固定容量的环形缓冲区，写满后覆盖最早的记录，服务重启后清空
+type memorySink struct {
+	mu      sync.Mutex
+	entries []*Entry
+	// start 最早一条记录的下标
+	start int
+	count int
+}
+
+func NewMemorySink(capacity int) Sink {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &memorySink{entries: make([]*Entry, capacity)}
+}
+
+func (s *memorySink) Append(_ context.Context, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	capacity := len(s.entries)
+	if s.count < capacity {
+		s.entries[(s.start+s.count)%capacity] = entry
+		s.count++
+		return nil
+	}
+	s.entries[s.start] = entry
+	s.start = (s.start + 1) % capacity
+	return nil
+}
+
+func (s *memorySink) Search(_ context.Context, filter Filter) ([]*Entry, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matched := make([]*Entry, 0)
+	s.each(func(entry *Entry) {
+		if filter.Match(entry) {
+			matched = append(matched, entry)
+		}
+	})
+	result, total := paginate(matched, filter)
+	return result, total, nil
+}
+
+func (s *memorySink) Clear(_ context.Context, uid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := make([]*Entry, 0, s.count)
+	s.each(func(entry *Entry) {
+		if entry.Uid != uid {
+			kept = append(kept, entry)
+		}
+	})
+	s.entries = make([]*Entry, len(s.entries))
+	copy(s.entries, kept)
+	s.start, s.count = 0, len(kept)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	return nil
+}
+
+// each 按写入顺序遍历记录，调用方需要持有锁
+func (s *memorySink) each(fn func(entry *Entry)) {
+	for i := 0; i < s.count; i++ {
+		fn(s.entries[(s.start+i)%len(s.entries)])
+	}
+}
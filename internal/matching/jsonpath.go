@@ -0,0 +1,150 @@
+package matching
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type jsonPathStep struct {
+	key       string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+// JSONPath 支持常用的 JSONPath 子集：
+//
+//	$.store.book[0].title   成员和数组下标
+//	$['store']['book']      方括号成员
+//	$.store.book[*].author  通配符
+//	$..author               递归查找
+type JSONPath struct {
+	raw   string
+	steps []jsonPathStep
+}
+
+// ParseJSONPath 解析 JSONPath 表达式
+func ParseJSONPath(expr string) (*JSONPath, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("invalid json path %q: must start with $", expr)
+	}
+	steps := make([]jsonPathStep, 0)
+	rest := expr[1:]
+	for len(rest) > 0 {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			name, remain := readJSONPathName(rest[2:])
+			if name == "" {
+				return nil, fmt.Errorf("invalid json path %q: missing name after ..", expr)
+			}
+			steps = append(steps, jsonPathStep{key: name, recursive: true, wildcard: name == "*"})
+			rest = remain
+		case rest[0] == '.':
+			name, remain := readJSONPathName(rest[1:])
+			if name == "" {
+				return nil, fmt.Errorf("invalid json path %q: missing name after .", expr)
+			}
+			steps = append(steps, jsonPathStep{key: name, wildcard: name == "*"})
+			rest = remain
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unclosed [", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, jsonPathStep{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid json path %q: bad index %s", expr, inner)
+				}
+				steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("invalid json path %q: unexpected %q", expr, rest[0])
+		}
+	}
+	return &JSONPath{raw: expr, steps: steps}, nil
+}
+
+func readJSONPathName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// Evaluate 在解析后的 JSON 文档上求值，返回所有命中的节点
+func (p *JSONPath) Evaluate(document interface{}) []interface{} {
+	current := []interface{}{document}
+	for _, step := range p.steps {
+		next := make([]interface{}, 0)
+		for _, node := range current {
+			if step.recursive {
+				next = append(next, descendants(node, step)...)
+			} else {
+				next = append(next, applyJSONPathStep(node, step)...)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func applyJSONPathStep(node interface{}, step jsonPathStep) []interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if step.wildcard {
+			result := make([]interface{}, 0, len(value))
+			for _, child := range value {
+				result = append(result, child)
+			}
+			return result
+		}
+		if step.isIndex {
+			return nil
+		}
+		if child, ok := value[step.key]; ok {
+			return []interface{}{child}
+		}
+	case []interface{}:
+		if step.wildcard {
+			return value
+		}
+		if step.isIndex {
+			index := step.index
+			if index < 0 {
+				index += len(value)
+			}
+			if index >= 0 && index < len(value) {
+				return []interface{}{value[index]}
+			}
+		}
+	}
+	return nil
+}
+
+// descendants 递归查找所有层级上满足 step 的节点
+func descendants(node interface{}, step jsonPathStep) []interface{} {
+	result := applyJSONPathStep(node, jsonPathStep{key: step.key, wildcard: step.wildcard})
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for _, child := range value {
+			result = append(result, descendants(child, step)...)
+		}
+	case []interface{}:
+		for _, child := range value {
+			result = append(result, descendants(child, step)...)
+		}
+	}
+	return result
+}
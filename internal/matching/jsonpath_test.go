@@ -0,0 +1,58 @@
+package matching
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestJSONPathEvaluate(t *testing.T) {
+	var document interface{}
+	raw := `{"store":{"book":[{"title":"A","author":"x"},{"title":"B","author":"y"}],"bicycle":{"color":"red"}},"name.with.dots":1}`
+	if err := json.Unmarshal([]byte(raw), &document); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"$.store.bicycle.color", []string{"red"}},
+		{"$.store.book[0].title", []string{"A"}},
+		{"$.store.book[-1].title", []string{"B"}},
+		{"$.store.book[5].title", []string{}},
+		{"$['store']['bicycle']['color']", []string{"red"}},
+		{`$["name.with.dots"]`, []string{"1"}},
+		{"$.store.book[*].author", []string{"x", "y"}},
+		{"$..author", []string{"x", "y"}},
+		{"$..color", []string{"red"}},
+		{"$.store.missing", []string{}},
+		{"$.store.book.title", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := ParseJSONPath(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseJSONPath: %v", err)
+			}
+			got := make([]string, 0)
+			for _, node := range path.Evaluate(document) {
+				got = append(got, stringifyJSON(node))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Evaluate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	for _, expr := range []string{"store.book", "$.", "$..", "$.book[", "$.book[x]", "$!"} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseJSONPath(expr); err == nil {
+				t.Fatalf("ParseJSONPath(%q) should fail", expr)
+			}
+		})
+	}
+}
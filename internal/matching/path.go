@@ -0,0 +1,216 @@
+package matching
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// WildcardParam `**` 捕获的剩余路径使用的参数名
+const WildcardParam = "wildcard"
+
+type segmentKind int
+
+// 按具体程度从低到高排列，比较两个模式时数值越大越具体
+const (
+	segmentDoubleWildcard segmentKind = iota
+	segmentWildcard
+	segmentParam
+	segmentRegexParam
+	segmentStatic
+)
+
+type segment struct {
+	kind    segmentKind
+	literal string
+	name    string
+	regex   *regexp.Regexp
+}
+
+// PathPattern 编译后的路径模式，支持以下写法：
+//
+//	/users/42            静态路径
+//	/users/{id}          命名参数，匹配单个路径段
+//	/users/{id:[0-9]+}   带正则约束的命名参数
+//	/users/*/avatar      匹配任意单个路径段，不捕获
+//	/files/**            匹配剩余的零个或多个路径段，只能出现在末尾，捕获到 wildcard 参数
+type PathPattern struct {
+	raw      string
+	segments []segment
+}
+
+var patternCache sync.Map
+
+// ParsePath 解析路径模式
+func ParsePath(pattern string) (*PathPattern, error) {
+	parts := splitPath(pattern)
+	segments := make([]segment, 0, len(parts))
+	names := make(map[string]bool)
+	for i, part := range parts {
+		seg, err := parseSegment(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+		}
+		if seg.kind == segmentDoubleWildcard && i != len(parts)-1 {
+			return nil, fmt.Errorf("invalid path pattern %q: ** must be the last segment", pattern)
+		}
+		if seg.name != "" {
+			if names[seg.name] {
+				return nil, fmt.Errorf("invalid path pattern %q: duplicate parameter %s", pattern, seg.name)
+			}
+			names[seg.name] = true
+		}
+		segments = append(segments, seg)
+	}
+	return &PathPattern{raw: pattern, segments: segments}, nil
+}
+
+// CompilePath 解析路径模式并缓存结果，供请求匹配的热路径使用
+func CompilePath(pattern string) (*PathPattern, error) {
+	if cached, ok := patternCache.Load(pattern); ok {
+		return cached.(*PathPattern), nil
+	}
+	compiled, err := ParsePath(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, compiled)
+	return compiled, nil
+}
+
+func parseSegment(part string) (segment, error) {
+	switch {
+	case part == "**":
+		return segment{kind: segmentDoubleWildcard, name: WildcardParam}, nil
+	case part == "*":
+		return segment{kind: segmentWildcard}, nil
+	case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+		inner := part[1 : len(part)-1]
+		name, expr, hasRegex := strings.Cut(inner, ":")
+		if name == "" {
+			return segment{}, fmt.Errorf("empty parameter name in %s", part)
+		}
+		if !hasRegex {
+			return segment{kind: segmentParam, name: name}, nil
+		}
+		regex, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return segment{}, err
+		}
+		return segment{kind: segmentRegexParam, name: name, regex: regex}, nil
+	default:
+		return segment{kind: segmentStatic, literal: part}, nil
+	}
+}
+
+// String 返回原始的路径模式
+func (p *PathPattern) String() string {
+	return p.raw
+}
+
+// IsStatic 模式中是否只包含静态路径段
+func (p *PathPattern) IsStatic() bool {
+	for _, seg := range p.segments {
+		if seg.kind != segmentStatic {
+			return false
+		}
+	}
+	return true
+}
+
+// IndexSegment 路由树索引使用的路径段，Dynamic 表示参数或 *，可以匹配任意单个路径段
+type IndexSegment struct {
+	Literal string
+	Dynamic bool
+}
+
+// IndexSegments 返回用于建立路由树索引的路径段，以 ** 结尾时 rest 为 true，返回的路径段不包含 **
+func (p *PathPattern) IndexSegments() (segments []IndexSegment, rest bool) {
+	segments = make([]IndexSegment, 0, len(p.segments))
+	for _, seg := range p.segments {
+		switch seg.kind {
+		case segmentDoubleWildcard:
+			return segments, true
+		case segmentStatic:
+			segments = append(segments, IndexSegment{Literal: seg.literal})
+		default:
+			segments = append(segments, IndexSegment{Dynamic: true})
+		}
+	}
+	return segments, false
+}
+
+// Match 判断请求路径是否匹配，匹配时返回捕获的参数
+func (p *PathPattern) Match(path string) (map[string]string, bool) {
+	parts := splitPath(path)
+	params := make(map[string]string)
+	for i, seg := range p.segments {
+		if seg.kind == segmentDoubleWildcard {
+			params[seg.name] = strings.Join(parts[i:], "/")
+			return params, true
+		}
+		if i >= len(parts) {
+			return nil, false
+		}
+		part := parts[i]
+		switch seg.kind {
+		case segmentStatic:
+			if part != seg.literal {
+				return nil, false
+			}
+		case segmentRegexParam:
+			if !seg.regex.MatchString(part) {
+				return nil, false
+			}
+			params[seg.name] = part
+		case segmentParam:
+			if part == "" {
+				return nil, false
+			}
+			params[seg.name] = part
+		case segmentWildcard:
+			if part == "" {
+				return nil, false
+			}
+		}
+	}
+	if len(parts) != len(p.segments) {
+		return nil, false
+	}
+	return params, true
+}
+
+// Compare 比较两个模式的具体程度，a 更具体时返回正数。
+// 逐段比较：静态段 > 正则参数 > 普通参数 > * > **；前缀相同时段数多的更具体
+func Compare(a, b *PathPattern) int {
+	for i := 0; i < len(a.segments) && i < len(b.segments); i++ {
+		if diff := int(a.segments[i].kind) - int(b.segments[i].kind); diff != 0 {
+			return diff
+		}
+	}
+	if diff := len(a.segments) - len(b.segments); diff != 0 {
+		// 以 ** 结尾的模式即使段数更多也不比确定段数的模式具体
+		if endsWithDoubleWildcard(a) != endsWithDoubleWildcard(b) {
+			if endsWithDoubleWildcard(a) {
+				return -1
+			}
+			return 1
+		}
+		return diff
+	}
+	return 0
+}
+
+func endsWithDoubleWildcard(p *PathPattern) bool {
+	return len(p.segments) > 0 && p.segments[len(p.segments)-1].kind == segmentDoubleWildcard
+}
+
+// SplitPath 按路径模式相同的方式拆分请求路径
+func SplitPath(path string) []string {
+	return splitPath(path)
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
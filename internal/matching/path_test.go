@@ -0,0 +1,127 @@
+package matching
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPathPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		ok      bool
+		params  map[string]string
+	}{
+		{"/users/42", "/users/42", true, map[string]string{}},
+		{"/users/42", "/users/43", false, nil},
+		{"/users/{id}", "/users/7", true, map[string]string{"id": "7"}},
+		{"/users/{id}", "/users/", false, nil},
+		{"/users/{id}", "/users/7/posts", false, nil},
+		{"/users/{id:[0-9]+}", "/users/7", true, map[string]string{"id": "7"}},
+		{"/users/{id:[0-9]+}", "/users/me", false, nil},
+		{"/users/*/avatar", "/users/7/avatar", true, map[string]string{}},
+		{"/users/*/avatar", "/users//avatar", false, nil},
+		{"/files/**", "/files/a/b/c.txt", true, map[string]string{WildcardParam: "a/b/c.txt"}},
+		{"/files/**", "/files", true, map[string]string{WildcardParam: ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			pattern, err := ParsePath(tt.pattern)
+			if err != nil {
+				t.Fatalf("ParsePath: %v", err)
+			}
+			params, ok := pattern.Match(tt.path)
+			if ok != tt.ok || (ok && !reflect.DeepEqual(params, tt.params)) {
+				t.Fatalf("Match = %v, %v, want %v, %v", params, ok, tt.params, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	for _, pattern := range []string{"/a/**/b", "/a/{}", "/a/{id}/{id}", "/a/{id:[}"} {
+		t.Run(pattern, func(t *testing.T) {
+			if _, err := ParsePath(pattern); err == nil {
+				t.Fatalf("ParsePath(%q) should fail", pattern)
+			}
+		})
+	}
+}
+
+func TestCompareSpecificity(t *testing.T) {
+	tests := []struct {
+		more, less string
+	}{
+		{"/users/me", "/users/{id}"},
+		{"/users/{id:[0-9]+}", "/users/{id}"},
+		{"/users/{id}", "/users/*"},
+		{"/users/*", "/users/**"},
+		{"/users/{id}/posts", "/users/{id}"},
+		{"/users/{id}", "/users/{id}/**"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.more+" > "+tt.less, func(t *testing.T) {
+			more, _ := ParsePath(tt.more)
+			less, _ := ParsePath(tt.less)
+			if Compare(more, less) <= 0 || Compare(less, more) >= 0 {
+				t.Fatalf("%s should be more specific than %s", tt.more, tt.less)
+			}
+		})
+	}
+}
+
+func TestPathPatternCaptures(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		ok      bool
+		params  map[string]string
+	}{
+		{"/repos/{owner}/{repo}", "/repos/kite/mock", true, map[string]string{"owner": "kite", "repo": "mock"}},
+		{"/repos/{owner}/**", "/repos/kite/blob/main/go.mod", true, map[string]string{"owner": "kite", WildcardParam: "blob/main/go.mod"}},
+		{"/users/{id:[0-9]+}", "/users/12a", false, nil},
+		{"/users/{id:[0-9]+}", "/users/a12", false, nil},
+		{"/files/{name:.+\\.json}", "/files/a.json", true, map[string]string{"name": "a.json"}},
+		{"/files/{name:.+\\.json}", "/files/a.json.bak", false, nil},
+		{"/users/{id}", "/users/%7Bid%7D", true, map[string]string{"id": "%7Bid%7D"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			pattern, err := ParsePath(tt.pattern)
+			if err != nil {
+				t.Fatalf("ParsePath: %v", err)
+			}
+			params, ok := pattern.Match(tt.path)
+			if ok != tt.ok || (ok && !reflect.DeepEqual(params, tt.params)) {
+				t.Fatalf("Match = %v, %v, want %v, %v", params, ok, tt.params, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCompareOrdersPatterns(t *testing.T) {
+	want := []string{"/users/me", "/users/{id:[0-9]+}", "/users/{id}", "/users/*", "/users/**", "/**"}
+	// 不论输入顺序如何，按具体程度排序的结果都相同
+	for _, input := range [][]string{
+		{"/**", "/users/**", "/users/*", "/users/{id}", "/users/{id:[0-9]+}", "/users/me"},
+		{"/users/{id}", "/**", "/users/me", "/users/**", "/users/{id:[0-9]+}", "/users/*"},
+	} {
+		patterns := make([]*PathPattern, 0, len(input))
+		for _, raw := range input {
+			pattern, err := ParsePath(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			patterns = append(patterns, pattern)
+		}
+		sort.SliceStable(patterns, func(i, j int) bool { return Compare(patterns[i], patterns[j]) > 0 })
+		got := make([]string, 0, len(patterns))
+		for _, pattern := range patterns {
+			got = append(got, pattern.String())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("sorted %v = %v, want %v", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package matching
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 字段匹配运算符
+const (
+	OperatorEquals   = "equals"
+	OperatorContains = "contains"
+	OperatorRegex    = "regex"
+	OperatorAbsent   = "absent"
+)
+
+// 请求体匹配方式
+const (
+	BodyTypeText       = "text"
+	BodyTypeJSONPath   = "json_path"
+	BodyTypeJSONEquals = "json_equals"
+	BodyTypeXPath      = "xpath"
+	BodyTypeForm       = "form"
+)
+
+// FieldMatcher query、header、cookie 的匹配条件
+type FieldMatcher struct {
+	Name     string `json:"name" validate:"required"`
+	Operator string `json:"operator" validate:"required,oneof=equals contains regex absent"`
+	Value    string `json:"value,omitempty"`
+}
+
+// BodyMatcher 请求体的匹配条件。Expression 对 json_path 是 JSONPath，
+// 对 xpath 是 XPath，对 form 是表单字段名；json_equals 与 text 不需要 Expression
+type BodyMatcher struct {
+	Type       string `json:"type" validate:"required,oneof=text json_path json_equals xpath form"`
+	Expression string `json:"expression,omitempty"`
+	Operator   string `json:"operator,omitempty" validate:"omitempty,oneof=equals contains regex absent"`
+	Value      string `json:"value,omitempty"`
+}
+
+// RequestMatchers mock 的附加匹配条件，所有条件都满足时才算命中
+type RequestMatchers struct {
+	Query   []FieldMatcher `json:"query,omitempty" validate:"dive"`
+	Headers []FieldMatcher `json:"headers,omitempty" validate:"dive"`
+	Cookies []FieldMatcher `json:"cookies,omitempty" validate:"dive"`
+	Body    []BodyMatcher  `json:"body,omitempty" validate:"dive"`
+}
+
+// Request 匹配所需的请求快照，请求体只读取一次
+type Request struct {
+	Query       url.Values
+	Header      http.Header
+	Cookies     map[string]string
+	Body        []byte
+	ContentType string
+
+	jsonOnce sync.Once
+	jsonBody interface{}
+	jsonErr  error
+}
+
+// NewRequest 读取请求体并构造快照，读取后会重置 r.Body 以便后续继续使用
+func NewRequest(r *http.Request) (*Request, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	cookies := make(map[string]string)
+	for _, cookie := range r.Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	return &Request{
+		Query:       r.URL.Query(),
+		Header:      r.Header,
+		Cookies:     cookies,
+		Body:        body,
+		ContentType: contentType,
+	}, nil
+}
+
+// NewRecordedRequest 由已记录的查询字符串、请求头和请求体构造快照，用于对请求日志做匹配
+func NewRecordedRequest(rawQuery string, header http.Header, body []byte) *Request {
+	query, _ := url.ParseQuery(rawQuery)
+	cookies := make(map[string]string)
+	for _, cookie := range (&http.Request{Header: header}).Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+	contentType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return &Request{
+		Query:       query,
+		Header:      header,
+		Cookies:     cookies,
+		Body:        body,
+		ContentType: contentType,
+	}
+}
+
+// JSON 返回解析后的 JSON 请求体，结果会被缓存
+func (r *Request) JSON() (interface{}, error) {
+	r.jsonOnce.Do(func() {
+		if len(bytes.TrimSpace(r.Body)) == 0 {
+			return
+		}
+		r.jsonErr = json.Unmarshal(r.Body, &r.jsonBody)
+	})
+	return r.jsonBody, r.jsonErr
+}
+
+// Form 解析 application/x-www-form-urlencoded 与 multipart/form-data 请求体
+func (r *Request) Form() url.Values {
+	switch r.ContentType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(r.Body))
+		if err != nil {
+			return url.Values{}
+		}
+		return values
+	case "multipart/form-data":
+		req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(r.Body))
+		if err != nil {
+			return url.Values{}
+		}
+		req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+		if err := req.ParseMultipartForm(32 << 20); err != nil {
+			return url.Values{}
+		}
+		return req.MultipartForm.Value
+	}
+	return url.Values{}
+}
+
+// Count 匹配条件的数量，用于在优先级相同时比较具体程度
+func (m *RequestMatchers) Count() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.Query) + len(m.Headers) + len(m.Cookies) + len(m.Body)
+}
+
+// Validate 校验正则、JSONPath、XPath 等表达式能否被解析
+func (m *RequestMatchers) Validate() error {
+	if m == nil {
+		return nil
+	}
+	for _, group := range [][]FieldMatcher{m.Query, m.Headers, m.Cookies} {
+		for _, matcher := range group {
+			if matcher.Operator == OperatorRegex {
+				if _, err := compileRegex(matcher.Value); err != nil {
+					return fmt.Errorf("invalid regex for %s: %w", matcher.Name, err)
+				}
+			}
+		}
+	}
+	for _, matcher := range m.Body {
+		if matcher.Operator == OperatorRegex {
+			if _, err := compileRegex(matcher.Value); err != nil {
+				return fmt.Errorf("invalid regex for body matcher: %w", err)
+			}
+		}
+		switch matcher.Type {
+		case BodyTypeJSONPath:
+			if _, err := ParseJSONPath(matcher.Expression); err != nil {
+				return err
+			}
+		case BodyTypeXPath:
+			if _, err := ParseXPath(matcher.Expression); err != nil {
+				return err
+			}
+		case BodyTypeJSONEquals:
+			if !json.Valid([]byte(matcher.Value)) {
+				return fmt.Errorf("json_equals value must be valid JSON")
+			}
+		case BodyTypeForm:
+			if matcher.Expression == "" {
+				return fmt.Errorf("form matcher requires the field name as expression")
+			}
+		}
+	}
+	return nil
+}
+
+// Match 判断请求是否满足全部条件，不满足时返回第一个失败条件的描述
+func (m *RequestMatchers) Match(req *Request) (bool, string) {
+	if m == nil {
+		return true, ""
+	}
+	for _, matcher := range m.Query {
+		values, present := req.Query[matcher.Name]
+		if !matchField(matcher.Operator, matcher.Value, values, present) {
+			return false, describeField("query", matcher)
+		}
+	}
+	for _, matcher := range m.Headers {
+		values, present := req.Header[http.CanonicalHeaderKey(matcher.Name)]
+		if !matchField(matcher.Operator, matcher.Value, values, present) {
+			return false, describeField("header", matcher)
+		}
+	}
+	for _, matcher := range m.Cookies {
+		value, present := req.Cookies[matcher.Name]
+		if !matchField(matcher.Operator, matcher.Value, []string{value}, present) {
+			return false, describeField("cookie", matcher)
+		}
+	}
+	for _, matcher := range m.Body {
+		if !matchBody(matcher, req) {
+			return false, describeBody(matcher)
+		}
+	}
+	return true, ""
+}
+
+func matchBody(matcher BodyMatcher, req *Request) bool {
+	operator := matcher.Operator
+	if operator == "" {
+		operator = OperatorEquals
+	}
+	switch matcher.Type {
+	case BodyTypeText:
+		return matchField(operator, matcher.Value, []string{string(req.Body)}, len(req.Body) > 0)
+	case BodyTypeJSONEquals:
+		actual, err := req.JSON()
+		if err != nil || actual == nil {
+			return false
+		}
+		var expected interface{}
+		if err := json.Unmarshal([]byte(matcher.Value), &expected); err != nil {
+			return false
+		}
+		return reflect.DeepEqual(actual, expected)
+	case BodyTypeJSONPath:
+		path, err := ParseJSONPath(matcher.Expression)
+		if err != nil {
+			return false
+		}
+		document, err := req.JSON()
+		if err != nil || document == nil {
+			return operator == OperatorAbsent
+		}
+		nodes := path.Evaluate(document)
+		values := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			values = append(values, stringifyJSON(node))
+		}
+		return matchField(operator, matcher.Value, values, len(values) > 0)
+	case BodyTypeXPath:
+		path, err := ParseXPath(matcher.Expression)
+		if err != nil {
+			return false
+		}
+		values, err := path.Evaluate(req.Body)
+		if err != nil {
+			return operator == OperatorAbsent
+		}
+		return matchField(operator, matcher.Value, values, len(values) > 0)
+	case BodyTypeForm:
+		values, present := req.Form()[matcher.Expression]
+		return matchField(operator, matcher.Value, values, present)
+	}
+	return false
+}
+
+// matchField 多值字段只要有一个值满足条件即视为匹配
+func matchField(operator string, expected string, values []string, present bool) bool {
+	if operator == OperatorAbsent {
+		return !present
+	}
+	if !present {
+		return false
+	}
+	for _, value := range values {
+		switch operator {
+		case OperatorEquals:
+			if value == expected {
+				return true
+			}
+		case OperatorContains:
+			if strings.Contains(value, expected) {
+				return true
+			}
+		case OperatorRegex:
+			regex, err := compileRegex(expected)
+			if err == nil && regex.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stringifyJSON 字符串直接返回原值，其他类型返回其 JSON 表示
+func stringifyJSON(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func describeField(kind string, matcher FieldMatcher) string {
+	if matcher.Operator == OperatorAbsent {
+		return fmt.Sprintf("%s %q should be absent", kind, matcher.Name)
+	}
+	return fmt.Sprintf("%s %q %s %q", kind, matcher.Name, matcher.Operator, matcher.Value)
+}
+
+func describeBody(matcher BodyMatcher) string {
+	operator := matcher.Operator
+	if operator == "" {
+		operator = OperatorEquals
+	}
+	switch matcher.Type {
+	case BodyTypeJSONEquals:
+		return "body is not equal to the expected JSON"
+	case BodyTypeText:
+		return fmt.Sprintf("body %s %q", operator, matcher.Value)
+	default:
+		return fmt.Sprintf("body %s %q %s %q", matcher.Type, matcher.Expression, operator, matcher.Value)
+	}
+}
+
+var regexCache sync.Map
+
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	if cached, ok := regexCache.Load(expr); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(expr, regex)
+	return regex, nil
+}
+
+// DecodeRequestMatchers 解析存储的匹配条件，未配置时返回 nil
+func DecodeRequestMatchers(raw json.RawMessage) (*RequestMatchers, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var matchers RequestMatchers
+	if err := json.Unmarshal(raw, &matchers); err != nil {
+		return nil, err
+	}
+	return &matchers, nil
+}
@@ -0,0 +1,187 @@
+package matching
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestRequest(t *testing.T, target string, contentType string, body string, headers map[string]string) *Request {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	req, err := NewRequest(r)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	return req
+}
+
+func TestFieldMatchers(t *testing.T) {
+	req := newTestRequest(t, "/orders?status=paid&tag=a&tag=b", "", "", map[string]string{
+		"X-Tenant": "acme-eu",
+		"Cookie":   "session=abc123; theme=dark",
+	})
+	tests := []struct {
+		name     string
+		matchers RequestMatchers
+		want     bool
+	}{
+		{"query equals", RequestMatchers{Query: []FieldMatcher{{Name: "status", Operator: OperatorEquals, Value: "paid"}}}, true},
+		{"query equals mismatch", RequestMatchers{Query: []FieldMatcher{{Name: "status", Operator: OperatorEquals, Value: "open"}}}, false},
+		{"query any of multiple values", RequestMatchers{Query: []FieldMatcher{{Name: "tag", Operator: OperatorEquals, Value: "b"}}}, true},
+		{"query missing", RequestMatchers{Query: []FieldMatcher{{Name: "page", Operator: OperatorEquals, Value: "1"}}}, false},
+		{"query absent", RequestMatchers{Query: []FieldMatcher{{Name: "page", Operator: OperatorAbsent}}}, true},
+		{"query absent but present", RequestMatchers{Query: []FieldMatcher{{Name: "status", Operator: OperatorAbsent}}}, false},
+		{"header case insensitive name", RequestMatchers{Headers: []FieldMatcher{{Name: "x-tenant", Operator: OperatorEquals, Value: "acme-eu"}}}, true},
+		{"header contains", RequestMatchers{Headers: []FieldMatcher{{Name: "X-Tenant", Operator: OperatorContains, Value: "acme"}}}, true},
+		{"header regex", RequestMatchers{Headers: []FieldMatcher{{Name: "X-Tenant", Operator: OperatorRegex, Value: `^acme-(eu|us)$`}}}, true},
+		{"header regex mismatch", RequestMatchers{Headers: []FieldMatcher{{Name: "X-Tenant", Operator: OperatorRegex, Value: `^acme-us$`}}}, false},
+		{"cookie equals", RequestMatchers{Cookies: []FieldMatcher{{Name: "session", Operator: OperatorEquals, Value: "abc123"}}}, true},
+		{"cookie absent", RequestMatchers{Cookies: []FieldMatcher{{Name: "token", Operator: OperatorAbsent}}}, true},
+		{"cookie mismatch", RequestMatchers{Cookies: []FieldMatcher{{Name: "theme", Operator: OperatorEquals, Value: "light"}}}, false},
+		{"all conditions must match", RequestMatchers{
+			Query:   []FieldMatcher{{Name: "status", Operator: OperatorEquals, Value: "paid"}},
+			Headers: []FieldMatcher{{Name: "X-Tenant", Operator: OperatorEquals, Value: "other"}},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := tt.matchers.Match(req)
+			if got != tt.want {
+				t.Fatalf("Match = %v (%s), want %v", got, reason, tt.want)
+			}
+			if !got && reason == "" {
+				t.Fatalf("a failed match must describe the failing condition")
+			}
+		})
+	}
+}
+
+func TestBodyMatchers(t *testing.T) {
+	jsonBody := `{"order":{"id":42,"items":[{"sku":"a-1","qty":2},{"sku":"b-2","qty":1}]},"note":"gift"}`
+	xmlBody := `<order version="2"><id>42</id><item sku="a-1">first</item><item sku="b-2">second</item></order>`
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		matcher     BodyMatcher
+		want        bool
+	}{
+		{"text equals", "text/plain", "hello", BodyMatcher{Type: BodyTypeText, Value: "hello"}, true},
+		{"text contains", "text/plain", "hello world", BodyMatcher{Type: BodyTypeText, Operator: OperatorContains, Value: "world"}, true},
+		{"text absent on empty body", "text/plain", "", BodyMatcher{Type: BodyTypeText, Operator: OperatorAbsent}, true},
+		{"json equals ignores formatting", "application/json", `{ "b": [1, 2], "a": "x" }`, BodyMatcher{Type: BodyTypeJSONEquals, Value: `{"a":"x","b":[1,2]}`}, true},
+		{"json equals mismatch", "application/json", `{"a":"x"}`, BodyMatcher{Type: BodyTypeJSONEquals, Value: `{"a":"y"}`}, false},
+		{"json equals invalid body", "application/json", `{`, BodyMatcher{Type: BodyTypeJSONEquals, Value: `{}`}, false},
+		{"json path number", "application/json", jsonBody, BodyMatcher{Type: BodyTypeJSONPath, Expression: "$.order.id", Value: "42"}, true},
+		{"json path index", "application/json", jsonBody, BodyMatcher{Type: BodyTypeJSONPath, Expression: "$.order.items[1].sku", Value: "b-2"}, true},
+		{"json path wildcard any", "application/json", jsonBody, BodyMatcher{Type: BodyTypeJSONPath, Expression: "$.order.items[*].sku", Value: "a-1"}, true},
+		{"json path recursive", "application/json", jsonBody, BodyMatcher{Type: BodyTypeJSONPath, Expression: "$..qty", Value: "1"}, true},
+		{"json path regex", "application/json", jsonBody, BodyMatcher{Type: BodyTypeJSONPath, Expression: "$.note", Operator: OperatorRegex, Value: "^gi"}, true},
+		{"json path absent", "application/json", jsonBody, BodyMatcher{Type: BodyTypeJSONPath, Expression: "$.coupon", Operator: OperatorAbsent}, true},
+		{"json path absent on empty body", "application/json", "", BodyMatcher{Type: BodyTypeJSONPath, Expression: "$.coupon", Operator: OperatorAbsent}, true},
+		{"json path mismatch", "application/json", jsonBody, BodyMatcher{Type: BodyTypeJSONPath, Expression: "$.order.id", Value: "43"}, false},
+		{"xpath text", "application/xml", xmlBody, BodyMatcher{Type: BodyTypeXPath, Expression: "/order/id", Value: "42"}, true},
+		{"xpath attribute", "application/xml", xmlBody, BodyMatcher{Type: BodyTypeXPath, Expression: "/order/@version", Value: "2"}, true},
+		{"xpath predicate", "application/xml", xmlBody, BodyMatcher{Type: BodyTypeXPath, Expression: "//item[@sku='b-2']", Value: "second"}, true},
+		{"xpath index", "application/xml", xmlBody, BodyMatcher{Type: BodyTypeXPath, Expression: "/order/item[1]", Value: "first"}, true},
+		{"xpath mismatch", "application/xml", xmlBody, BodyMatcher{Type: BodyTypeXPath, Expression: "/order/id", Value: "7"}, false},
+		{"form field", "application/x-www-form-urlencoded", "user=alice&role=admin", BodyMatcher{Type: BodyTypeForm, Expression: "role", Value: "admin"}, true},
+		{"form field absent", "application/x-www-form-urlencoded", "user=alice", BodyMatcher{Type: BodyTypeForm, Expression: "role", Operator: OperatorAbsent}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, "/", tt.contentType, tt.body, nil)
+			matchers := RequestMatchers{Body: []BodyMatcher{tt.matcher}}
+			if err := matchers.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if got, reason := matchers.Match(req); got != tt.want {
+				t.Fatalf("Match = %v (%s), want %v", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultipartFormMatcher(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("kind", "avatar")
+	_ = writer.Close()
+	req := newTestRequest(t, "/", writer.FormDataContentType(), body.String(), nil)
+	matchers := RequestMatchers{Body: []BodyMatcher{{Type: BodyTypeForm, Expression: "kind", Value: "avatar"}}}
+	if ok, reason := matchers.Match(req); !ok {
+		t.Fatalf("multipart field should match: %s", reason)
+	}
+}
+
+func TestNewRequestKeepsBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+	if _, err := NewRequest(r); err != nil {
+		t.Fatal(err)
+	}
+	var rest bytes.Buffer
+	_, _ = rest.ReadFrom(r.Body)
+	if rest.String() != "payload" {
+		t.Fatalf("body after NewRequest = %q, want payload", rest.String())
+	}
+}
+
+func TestValidateRejectsInvalidExpressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		matchers RequestMatchers
+	}{
+		{"bad header regex", RequestMatchers{Headers: []FieldMatcher{{Name: "X", Operator: OperatorRegex, Value: "("}}}},
+		{"bad body regex", RequestMatchers{Body: []BodyMatcher{{Type: BodyTypeText, Operator: OperatorRegex, Value: "["}}}},
+		{"bad json path", RequestMatchers{Body: []BodyMatcher{{Type: BodyTypeJSONPath, Expression: "order.id"}}}},
+		{"bad xpath", RequestMatchers{Body: []BodyMatcher{{Type: BodyTypeXPath, Expression: "order/id"}}}},
+		{"invalid json_equals value", RequestMatchers{Body: []BodyMatcher{{Type: BodyTypeJSONEquals, Value: "{"}}}},
+		{"form without field name", RequestMatchers{Body: []BodyMatcher{{Type: BodyTypeForm, Value: "x"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.matchers.Validate(); err == nil {
+				t.Fatalf("Validate should reject %+v", tt.matchers)
+			}
+		})
+	}
+}
+
+func TestCountAndNil(t *testing.T) {
+	var matchers *RequestMatchers
+	if ok, _ := matchers.Match(&Request{}); !ok || matchers.Count() != 0 {
+		t.Fatalf("nil matchers must match everything and count zero")
+	}
+	full := &RequestMatchers{
+		Query:   []FieldMatcher{{}},
+		Headers: []FieldMatcher{{}, {}},
+		Cookies: []FieldMatcher{{}},
+		Body:    []BodyMatcher{{}},
+	}
+	if got := full.Count(); got != 5 {
+		t.Fatalf("Count = %d, want 5", got)
+	}
+}
+
+func TestDecodeRequestMatchers(t *testing.T) {
+	for _, raw := range []string{"", "null"} {
+		matchers, err := DecodeRequestMatchers([]byte(raw))
+		if err != nil || matchers != nil {
+			t.Fatalf("DecodeRequestMatchers(%q) = %v, %v, want nil", raw, matchers, err)
+		}
+	}
+	matchers, err := DecodeRequestMatchers([]byte(`{"query":[{"name":"a","operator":"equals","value":"1"}]}`))
+	if err != nil || matchers.Count() != 1 {
+		t.Fatalf("DecodeRequestMatchers = %+v, %v", matchers, err)
+	}
+}
@@ -0,0 +1,235 @@
+package matching
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xmlNode 简化的 XML 文档树，仅保留元素名、属性和文本
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	text     strings.Builder
+	children []*xmlNode
+	parent   *xmlNode
+}
+
+// parseXML 将 XML 文档解析为节点树，返回一个虚拟的根节点
+func parseXML(data []byte) (*xmlNode, error) {
+	root := &xmlNode{}
+	current := root
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: make(map[string]string), parent: current}
+			for _, attr := range t.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			current.children = append(current.children, node)
+			current = node
+		case xml.EndElement:
+			if current.parent != nil {
+				current = current.parent
+			}
+		case xml.CharData:
+			current.text.Write(t)
+		}
+	}
+	return root, nil
+}
+
+type xpathStep struct {
+	descendant bool
+	name       string
+	attr       string
+	text       bool
+	index      int
+	predAttr   string
+	predValue  string
+	hasPred    bool
+}
+
+// XPath 支持常用的 XPath 子集：
+//
+//	/order/id                 绝对路径
+//	//item                    任意层级
+//	/order/item[2]            下标（从 1 开始）
+//	//item[@sku='123']        属性过滤
+//	/order/@version           属性值
+//	/order/note/text()        文本
+type XPath struct {
+	raw   string
+	steps []xpathStep
+}
+
+// ParseXPath 解析 XPath 表达式
+func ParseXPath(expr string) (*XPath, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "/") {
+		return nil, fmt.Errorf("invalid xpath %q: must start with /", expr)
+	}
+	steps := make([]xpathStep, 0)
+	rest := expr
+	for len(rest) > 0 {
+		step := xpathStep{}
+		if strings.HasPrefix(rest, "//") {
+			step.descendant = true
+			rest = rest[2:]
+		} else if strings.HasPrefix(rest, "/") {
+			rest = rest[1:]
+		}
+		end := nextXPathSeparator(rest)
+		part := rest[:end]
+		rest = rest[end:]
+		if part == "" {
+			return nil, fmt.Errorf("invalid xpath %q: empty step", expr)
+		}
+		if open := strings.Index(part, "["); open >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("invalid xpath %q: unclosed [", expr)
+			}
+			predicate := part[open+1 : len(part)-1]
+			part = part[:open]
+			if err := parseXPathPredicate(&step, predicate); err != nil {
+				return nil, fmt.Errorf("invalid xpath %q: %w", expr, err)
+			}
+		}
+		switch {
+		case part == "text()":
+			step.text = true
+		case strings.HasPrefix(part, "@"):
+			step.attr = part[1:]
+		default:
+			step.name = part
+		}
+		steps = append(steps, step)
+	}
+	return &XPath{raw: expr, steps: steps}, nil
+}
+
+// nextXPathSeparator 找到下一个不在谓词中的 /
+func nextXPathSeparator(s string) int {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '/':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+func parseXPathPredicate(step *xpathStep, predicate string) error {
+	predicate = strings.TrimSpace(predicate)
+	if index, err := strconv.Atoi(predicate); err == nil {
+		if index < 1 {
+			return fmt.Errorf("index must start at 1")
+		}
+		step.index = index
+		return nil
+	}
+	name, value, ok := strings.Cut(predicate, "=")
+	name = strings.TrimSpace(name)
+	value = strings.TrimSpace(value)
+	if !ok || !strings.HasPrefix(name, "@") || len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+		return fmt.Errorf("unsupported predicate [%s]", predicate)
+	}
+	step.hasPred = true
+	step.predAttr = name[1:]
+	step.predValue = value[1 : len(value)-1]
+	return nil
+}
+
+// Evaluate 在 XML 文档上求值，返回命中节点的文本或属性值
+func (p *XPath) Evaluate(data []byte) ([]string, error) {
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*xmlNode{root}
+	for i, step := range p.steps {
+		if step.attr != "" || step.text {
+			if i != len(p.steps)-1 {
+				return nil, nil
+			}
+			values := make([]string, 0, len(nodes))
+			for _, node := range nodes {
+				if step.text {
+					values = append(values, node.text.String())
+				} else if value, ok := node.attrs[step.attr]; ok {
+					values = append(values, value)
+				}
+			}
+			return values, nil
+		}
+		next := make([]*xmlNode, 0)
+		for _, node := range nodes {
+			var children []*xmlNode
+			if step.descendant {
+				children = xmlDescendants(node)
+			} else {
+				children = node.children
+			}
+			matched := make([]*xmlNode, 0)
+			for _, child := range children {
+				if step.name != "*" && child.name != step.name {
+					continue
+				}
+				if step.hasPred && child.attrs[step.predAttr] != step.predValue {
+					continue
+				}
+				matched = append(matched, child)
+			}
+			if step.index > 0 {
+				if step.index <= len(matched) {
+					next = append(next, matched[step.index-1])
+				}
+				continue
+			}
+			next = append(next, matched...)
+		}
+		nodes = next
+	}
+	values := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		values = append(values, strings.TrimSpace(xmlInnerText(node)))
+	}
+	return values, nil
+}
+
+func xmlDescendants(node *xmlNode) []*xmlNode {
+	result := make([]*xmlNode, 0)
+	for _, child := range node.children {
+		result = append(result, child)
+		result = append(result, xmlDescendants(child)...)
+	}
+	return result
+}
+
+func xmlInnerText(node *xmlNode) string {
+	var builder strings.Builder
+	builder.WriteString(node.text.String())
+	for _, child := range node.children {
+		builder.WriteString(xmlInnerText(child))
+	}
+	return builder.String()
+}
@@ -0,0 +1,66 @@
+package matching
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestXPathEvaluate(t *testing.T) {
+	document := []byte(`<?xml version="1.0"?>
+<catalog region="eu">
+  <book id="1"><title>Go</title><price>30</price></book>
+  <book id="2"><title>XML</title><price>25</price></book>
+  <magazine><title>Weekly</title></magazine>
+</catalog>`)
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"/catalog/@region", []string{"eu"}},
+		{"/catalog/book/title", []string{"Go", "XML"}},
+		{"/catalog/book[2]/title", []string{"XML"}},
+		{"/catalog/book[3]/title", []string{}},
+		{"/catalog/book[@id='1']/price", []string{"30"}},
+		{`/catalog/book[@id="2"]/@id`, []string{"2"}},
+		{"//title", []string{"Go", "XML", "Weekly"}},
+		{"/catalog/*/title", []string{"Go", "XML", "Weekly"}},
+		{"/catalog/magazine/title/text()", []string{"Weekly"}},
+		{"/catalog/book/@missing", []string{}},
+		{"/catalog/@region/title", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := ParseXPath(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseXPath: %v", err)
+			}
+			got, err := path.Evaluate(document)
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Evaluate = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXPathEvaluateInvalidDocument(t *testing.T) {
+	path, err := ParseXPath("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := path.Evaluate([]byte("<a><b></a")); err == nil {
+		t.Fatalf("Evaluate should fail on a malformed document")
+	}
+}
+
+func TestParseXPathErrors(t *testing.T) {
+	for _, expr := range []string{"catalog", "/catalog//", "/book[0]", "/book[@id=1]", "/book[@id='1'", "/book[last()]"} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseXPath(expr); err == nil {
+				t.Fatalf("ParseXPath(%q) should fail", expr)
+			}
+		})
+	}
+}
@@ -2,19 +2,67 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
 type Api struct {
-	Id           uint64          `gorm:"column:id;primary_key;"`
-	UserId       string          `gorm:"column:user_id;not null;"`
-	Uuid         string          `gorm:"column:uuid;not null;type:varchar(255)"`
-	Path         string          `gorm:"column:path;not null;type:varchar(1024)"`
-	Method       string          `gorm:"column:method;not null;type:varchar(32)"`
-	StatusCode   int16           `gorm:"column:status_code;not null;type:int"`
-	ContentType  string          `gorm:"column:content_type;not null;type:varchar(128)"`
-	Headers      json.RawMessage `gorm:"column:headers;type:json"`
-	ResponseBody string          `gorm:"column:response_body;not null;type:text"`
-	CreatedAt    time.Time       `gorm:"column:created_at;not null;type:timestamp"`
-	UpdatedAt    time.Time       `gorm:"column:updated_at;not null;type:timestamp"`
+	Id              uint64          `gorm:"column:id;primary_key;"`
+	WorkspaceId     string          `gorm:"column:workspace_id;not null;type:varchar(64);default:''"`
+	UserId          string          `gorm:"column:user_id;not null;type:varchar(255)"`
+	Uuid            string          `gorm:"column:uuid;not null;type:varchar(255)"`
+	Path            string          `gorm:"column:path;not null;type:varchar(1024)"`
+	Method          string          `gorm:"column:method;not null;type:varchar(32)"`
+	StatusCode      int16           `gorm:"column:status_code;not null;type:int"`
+	ContentType     string          `gorm:"column:content_type;not null;type:varchar(128)"`
+	Charset         string          `gorm:"column:charset;not null;type:varchar(32)"`
+	Headers         json.RawMessage `gorm:"column:headers;type:json"`
+	ResponseBody    string          `gorm:"column:response_body;not null;type:text"`
+	RequestMatchers json.RawMessage `gorm:"column:request_matchers;type:json"`
+	Priority        int             `gorm:"column:priority;not null;type:int;default:0"`
+	Template        bool            `gorm:"column:template;not null;default:false"`
+	Delay           json.RawMessage `gorm:"column:delay;type:json"`
+	ThrottleBps     int             `gorm:"column:throttle_bps;not null;type:int;default:0"`
+	Fault           json.RawMessage `gorm:"column:fault;type:json"`
+	Responses       json.RawMessage `gorm:"column:responses;type:json"`
+	SequenceMode    string          `gorm:"column:sequence_mode;not null;type:varchar(16);default:''"`
+	ScenarioName    string          `gorm:"column:scenario_name;not null;type:varchar(255);default:''"`
+	RequiredState   string          `gorm:"column:required_state;not null;type:varchar(255);default:''"`
+	NewState        string          `gorm:"column:new_state;not null;type:varchar(255);default:''"`
+	Tags            json.RawMessage `gorm:"column:tags;type:json"`
+	CreatedAt       time.Time       `gorm:"column:created_at;not null;type:timestamp"`
+	UpdatedAt       time.Time       `gorm:"column:updated_at;not null;type:timestamp(6)"`
+}
+
+// GetHeaders 将存储的 JSON 响应头解析为 map
+func (a *Api) GetHeaders() (map[string]string, error) {
+	headers := make(map[string]string)
+	if len(a.Headers) == 0 || string(a.Headers) == "null" {
+		return headers, nil
+	}
+	if err := json.Unmarshal(a.Headers, &headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// GetTags 将存储的 JSON 标签解析为切片
+func (a *Api) GetTags() ([]string, error) {
+	tags := make([]string, 0)
+	if len(a.Tags) == 0 || string(a.Tags) == "null" {
+		return tags, nil
+	}
+	if err := json.Unmarshal(a.Tags, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetContentTypeWithCharset 返回带字符集的 Content-Type，例如 application/json; charset=utf-8
+func (a *Api) GetContentTypeWithCharset() string {
+	if a.Charset == "" || strings.Contains(strings.ToLower(a.ContentType), "charset=") {
+		return a.ContentType
+	}
+	return fmt.Sprintf("%s; charset=%s", a.ContentType, a.Charset)
 }
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JournalEntry 请求日志存储在数据库中时的记录
+type JournalEntry struct {
+	Id            uint64          `gorm:"column:id;primary_key;"`
+	Uuid          string          `gorm:"column:uuid;not null;type:varchar(64)"`
+	Uid           string          `gorm:"column:uid;not null;type:varchar(255);index:idx_journal_uid_created,priority:1"`
+	Method        string          `gorm:"column:method;not null;type:varchar(32)"`
+	Path          string          `gorm:"column:path;not null;type:varchar(1024)"`
+	Query         string          `gorm:"column:query;not null;type:text"`
+	Headers       json.RawMessage `gorm:"column:headers;type:json"`
+	Body          string          `gorm:"column:body;not null;type:mediumtext"`
+	BodyTruncated bool            `gorm:"column:body_truncated;not null;default:false"`
+	MatchedUuid   string          `gorm:"column:matched_uuid;not null;type:varchar(255);default:''"`
+	StatusCode    int             `gorm:"column:status_code;not null;type:int"`
+	LatencyMs     float64         `gorm:"column:latency_ms;not null;type:double"`
+	RequestId     string          `gorm:"column:request_id;not null;type:varchar(64);default:''"`
+	CreatedAt     time.Time       `gorm:"column:created_at;not null;precision:6;index:idx_journal_uid_created,priority:2"`
+}
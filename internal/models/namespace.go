@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Namespace mock 命名空间（即 URL 中的 uid）级别的配置
+type Namespace struct {
+	Id             uint64          `gorm:"column:id;primary_key;"`
+	WorkspaceId    string          `gorm:"column:workspace_id;not null;type:varchar(64);default:''"`
+	Uid            string          `gorm:"column:uid;not null;type:varchar(255);uniqueIndex"`
+	NotFoundStatus int             `gorm:"column:not_found_status;not null;type:int;default:404"`
+	Delay          json.RawMessage `gorm:"column:delay;type:json"`
+	ThrottleBps    int             `gorm:"column:throttle_bps;not null;type:int;default:0"`
+	Mode           string          `gorm:"column:mode;not null;type:varchar(16);default:'mock'"`
+	UpstreamUrl    string          `gorm:"column:upstream_url;not null;type:varchar(1024);default:''"`
+	RecordConfig   json.RawMessage `gorm:"column:record_config;type:json"`
+	FallbackUrl    string          `gorm:"column:fallback_url;not null;type:varchar(1024);default:''"`
+	RewriteRules   json.RawMessage `gorm:"column:rewrite_rules;type:json"`
+	OpenApiSpec    string          `gorm:"column:openapi_spec;type:longtext"`
+	// OpenApiSpecHash 绑定文档的 SHA-256 摘要，用作请求校验器的缓存键
+	OpenApiSpecHash string    `gorm:"column:openapi_spec_hash;not null;type:char(64);default:''"`
+	CreatedAt       time.Time `gorm:"column:created_at;not null;type:timestamp"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;not null;type:timestamp(6)"`
+}
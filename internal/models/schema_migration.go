@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// SchemaMigration 已执行的数据库迁移版本
+type SchemaMigration struct {
+	Version   int       `gorm:"column:version;primary_key;autoIncrement:false"`
+	Name      string    `gorm:"column:name;not null;type:varchar(255)"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+}
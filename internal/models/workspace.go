@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Workspace 工作区（租户）。mock 与命名空间配置通过 WorkspaceId 归属于工作区，
+// WorkspaceId 不对外暴露；mock 的 UserId 只是 URL 中公开的命名空间
+type Workspace struct {
+	Id        uint64    `gorm:"column:id;primary_key;"`
+	Uuid      string    `gorm:"column:uuid;not null;type:varchar(64);uniqueIndex"`
+	Name      string    `gorm:"column:name;not null;type:varchar(255)"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;type:timestamp"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null;type:timestamp"`
+}
+
+// WorkspaceMember 工作区成员，UserId 是成员的内部标识，与命名空间无关
+type WorkspaceMember struct {
+	Id          uint64    `gorm:"column:id;primary_key;"`
+	WorkspaceId string    `gorm:"column:workspace_id;not null;type:varchar(64)"`
+	UserId      string    `gorm:"column:user_id;not null;type:varchar(255)"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null;type:timestamp"`
+}
+
+// ApiKey 工作区的 API Key，只保存 SHA-256 摘要，明文只在创建时返回一次。Prefix 用于在列表中辨认
+type ApiKey struct {
+	Id          uint64    `gorm:"column:id;primary_key;"`
+	Uuid        string    `gorm:"column:uuid;not null;type:varchar(64)"`
+	WorkspaceId string    `gorm:"column:workspace_id;not null;type:varchar(64)"`
+	Name        string    `gorm:"column:name;not null;type:varchar(255);default:''"`
+	Prefix      string    `gorm:"column:prefix;not null;type:varchar(16)"`
+	Hash        string    `gorm:"column:hash;not null;type:char(64)"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null;type:timestamp"`
+}
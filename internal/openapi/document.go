@@ -0,0 +1,136 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxRefDepth 连续 $ref 的最大跳转次数，防止引用成环
+const maxRefDepth = 32
+
+// document 以通用 map 形式保存的规范文档，负责解析本地 $ref
+type document struct {
+	root map[string]interface{}
+}
+
+// load 解析 YAML 或 JSON 格式的规范文档
+func load(data []byte) (*document, error) {
+	var raw interface{}
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON spec: %w", err)
+		}
+	} else if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid YAML spec: %w", err)
+	}
+	root, ok := normalize(raw).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid spec: the document root must be an object")
+	}
+	return &document{root: root}, nil
+}
+
+// version 返回规范版本，只支持 OpenAPI 3.x 和 Swagger 2.0
+func (d *document) version() (string, error) {
+	if version := fmt.Sprint(d.root["openapi"]); strings.HasPrefix(version, "3.") {
+		return version, nil
+	}
+	if fmt.Sprint(d.root["swagger"]) == "2.0" {
+		return "2.0", nil
+	}
+	return "", errors.New("unsupported spec: expected openapi 3.x or swagger 2.0")
+}
+
+// lookup 按 JSON Pointer 查找本地引用，例如 #/components/schemas/User；外部引用返回 nil
+func (d *document) lookup(ref string) interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var current interface{} = d.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		current = object(current)[token]
+	}
+	return current
+}
+
+// deref 跟随 $ref 直到得到实际的对象
+func (d *document) deref(value interface{}) map[string]interface{} {
+	node := object(value)
+	for i := 0; node != nil && i < maxRefDepth; i++ {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node
+		}
+		node = object(d.lookup(ref))
+	}
+	return node
+}
+
+// normalize 将 YAML 解析出的 map[interface{}]interface{} 等类型转换为可以直接 JSON 序列化的类型
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalize(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return v
+	}
+}
+
+func object(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
+
+func list(value interface{}) []interface{} {
+	l, _ := value.([]interface{})
+	return l
+}
+
+func str(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
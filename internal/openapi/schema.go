@@ -0,0 +1,155 @@
+package openapi
+
+// maxSchemaDepth 生成示例时的最大嵌套层数
+const maxSchemaDepth = 8
+
+// sampler 根据 schema 生成示例值，优先使用 example、default、enum 等已有的值
+type sampler struct {
+	doc *document
+	// refs 当前递归路径上的引用，用于打断自引用的 schema
+	refs map[string]bool
+}
+
+func newSampler(doc *document) *sampler {
+	return &sampler{doc: doc, refs: make(map[string]bool)}
+}
+
+func (s *sampler) sample(value interface{}, depth int) interface{} {
+	schema := object(value)
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		if s.refs[ref] {
+			return nil
+		}
+		s.refs[ref] = true
+		defer delete(s.refs, ref)
+		return s.sample(s.doc.lookup(ref), depth+1)
+	}
+	if example, ok := schema["example"]; ok {
+		return example
+	}
+	// Swagger 2 参数和响应头上的示例
+	if example, ok := schema["x-example"]; ok {
+		return example
+	}
+	if examples := list(schema["examples"]); len(examples) > 0 {
+		return examples[0]
+	}
+	if value, ok := schema["default"]; ok {
+		return value
+	}
+	if enum := list(schema["enum"]); len(enum) > 0 {
+		return enum[0]
+	}
+	if value, ok := schema["const"]; ok {
+		return value
+	}
+	if parts := list(schema["allOf"]); len(parts) > 0 {
+		merged := make(map[string]interface{})
+		for _, part := range parts {
+			if sampled, ok := s.sample(part, depth+1).(map[string]interface{}); ok {
+				for key, item := range sampled {
+					merged[key] = item
+				}
+			}
+		}
+		for key, item := range s.properties(schema, depth) {
+			merged[key] = item
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alternatives := list(schema[key]); len(alternatives) > 0 {
+			return s.sample(alternatives[0], depth+1)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		return s.properties(schema, depth)
+	case "array":
+		item := s.sample(schema["items"], depth+1)
+		if item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{item}
+	case "string":
+		return sampleString(str(schema["format"]))
+	case "integer":
+		if minimum, ok := number(schema["minimum"]); ok {
+			return int64(minimum)
+		}
+		return 0
+	case "number":
+		if minimum, ok := number(schema["minimum"]); ok {
+			return minimum
+		}
+		return 0.0
+	case "boolean":
+		return true
+	}
+	return nil
+}
+
+// properties 为对象的每个属性生成示例，只出现在请求中的 writeOnly 属性不输出
+func (s *sampler) properties(schema map[string]interface{}, depth int) map[string]interface{} {
+	result := make(map[string]interface{})
+	for name, property := range object(schema["properties"]) {
+		if s.doc.deref(property)["writeOnly"] == true {
+			continue
+		}
+		result[name] = s.sample(property, depth+1)
+	}
+	return result
+}
+
+// schemaType 返回 schema 的类型；OpenAPI 3.1 的类型数组取第一个非 null 的类型
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if name := str(item); name != "" && name != "null" {
+				return name
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+func sampleString(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "time":
+		return "00:00:00"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "192.168.0.1"
+	case "ipv6":
+		return "::1"
+	case "byte":
+		return "c3RyaW5n"
+	case "password":
+		return "********"
+	}
+	return "string"
+}
@@ -0,0 +1,371 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// httpMethods 规范中可以出现的操作，按固定顺序遍历以保证导入结果稳定
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec 解析后的接口描述，只保留生成 mock 需要的信息
+type Spec struct {
+	// Version 规范版本，例如 2.0、3.0.3、3.1.0
+	Version    string
+	Title      string
+	Operations []Operation
+}
+
+// Operation 一个路径上的一个方法，Path 已转换为 mock 的路径模式
+type Operation struct {
+	Method      string
+	Path        string
+	OperationId string
+	Summary     string
+	Responses   []Response
+}
+
+// Response 操作的一个响应码及其示例
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Headers     map[string]string
+	Body        string
+}
+
+// Parse 解析 OpenAPI 3.x 或 Swagger 2.0 规范，支持 YAML 和 JSON；
+// 响应体优先使用规范中的示例，没有示例时根据 schema 生成
+func Parse(data []byte) (*Spec, error) {
+	doc, err := load(data)
+	if err != nil {
+		return nil, err
+	}
+	spec := &Spec{
+		Title: str(object(doc.root["info"])["title"]),
+	}
+	spec.Version, err = doc.version()
+	if err != nil {
+		return nil, err
+	}
+	var parser responseParser = &v3Parser{doc: doc}
+	if spec.Version == "2.0" {
+		parser = &v2Parser{doc: doc}
+	}
+
+	err = eachOperation(doc, func(path string, method string, operation map[string]interface{}, parameters []map[string]interface{}) error {
+		responses, err := parser.responses(operation)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+		}
+		spec.Operations = append(spec.Operations, Operation{
+			Method:      strings.ToUpper(method),
+			Path:        convertPath(path, pathParamTypes(doc, parameters)),
+			OperationId: str(operation["operationId"]),
+			Summary:     str(operation["summary"]),
+			Responses:   responses,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// eachOperation 按路径和方法的固定顺序遍历所有操作，parameters 已合并路径级和操作级的参数
+func eachOperation(doc *document, fn func(path string, method string, operation map[string]interface{}, parameters []map[string]interface{}) error) error {
+	paths := object(doc.root["paths"])
+	keys := make([]string, 0, len(paths))
+	for key := range paths {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		item := doc.deref(paths[key])
+		for _, method := range httpMethods {
+			operation := object(item[method])
+			if operation == nil {
+				continue
+			}
+			parameters := mergeParameters(doc, list(item["parameters"]), list(operation["parameters"]))
+			if err := fn(key, method, operation, parameters); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// responseParser 不同版本的规范描述响应的方式不同
+type responseParser interface {
+	responses(operation map[string]interface{}) ([]Response, error)
+}
+
+// v3Parser OpenAPI 3.x：响应内容按媒体类型放在 content 下
+type v3Parser struct {
+	doc *document
+}
+
+func (p *v3Parser) responses(operation map[string]interface{}) ([]Response, error) {
+	result := make([]Response, 0)
+	for code, value := range object(operation["responses"]) {
+		status, ok := parseStatus(code)
+		if !ok {
+			continue
+		}
+		response := p.doc.deref(value)
+		content := object(response["content"])
+		mediaType := preferredMediaType(content)
+		var body interface{}
+		if mediaType != "" {
+			body = p.example(p.doc.deref(content[mediaType]))
+		}
+		rendered, err := renderBody(body, mediaType)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Response{
+			StatusCode:  status,
+			ContentType: mediaType,
+			Headers:     sampleHeaders(p.doc, response["headers"], func(header map[string]interface{}) interface{} { return header["schema"] }),
+			Body:        rendered,
+		})
+	}
+	sortResponses(result)
+	return result, nil
+}
+
+// example 依次取 example、examples 中按名称排序的第一个、schema 生成的示例
+func (p *v3Parser) example(media map[string]interface{}) interface{} {
+	if example, ok := media["example"]; ok {
+		return example
+	}
+	if examples := object(media["examples"]); len(examples) > 0 {
+		names := make([]string, 0, len(examples))
+		for name := range examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if example := p.doc.deref(examples[names[0]]); example != nil {
+			if value, ok := example["value"]; ok {
+				return value
+			}
+		}
+	}
+	return newSampler(p.doc).sample(media["schema"], 0)
+}
+
+// v2Parser Swagger 2.0：响应示例按媒体类型放在 examples 下，媒体类型来自 produces
+type v2Parser struct {
+	doc *document
+}
+
+func (p *v2Parser) responses(operation map[string]interface{}) ([]Response, error) {
+	produces := list(operation["produces"])
+	if produces == nil {
+		produces = list(p.doc.root["produces"])
+	}
+	mediaTypes := make(map[string]interface{}, len(produces))
+	for _, item := range produces {
+		mediaTypes[str(item)] = true
+	}
+
+	result := make([]Response, 0)
+	for code, value := range object(operation["responses"]) {
+		status, ok := parseStatus(code)
+		if !ok {
+			continue
+		}
+		response := p.doc.deref(value)
+		var (
+			mediaType string
+			body      interface{}
+		)
+		if examples := object(response["examples"]); len(examples) > 0 {
+			mediaType = preferredMediaType(examples)
+			body = examples[mediaType]
+		} else if schema, ok := response["schema"]; ok {
+			mediaType = preferredMediaType(mediaTypes)
+			if mediaType == "" {
+				mediaType = "application/json"
+			}
+			body = newSampler(p.doc).sample(schema, 0)
+		}
+		rendered, err := renderBody(body, mediaType)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Response{
+			StatusCode:  status,
+			ContentType: mediaType,
+			Headers:     sampleHeaders(p.doc, response["headers"], func(header map[string]interface{}) interface{} { return header }),
+			Body:        rendered,
+		})
+	}
+	sortResponses(result)
+	return result, nil
+}
+
+// parseStatus 解析响应码，2XX 这类范围取该范围的第一个码，default 无法确定状态码，忽略
+func parseStatus(code string) (int, bool) {
+	if len(code) == 3 && strings.HasSuffix(strings.ToUpper(code), "XX") {
+		if digit, err := strconv.Atoi(code[:1]); err == nil && digit >= 1 && digit <= 5 {
+			return digit * 100, true
+		}
+		return 0, false
+	}
+	status, err := strconv.Atoi(code)
+	if err != nil || status < 100 || status > 599 {
+		return 0, false
+	}
+	return status, true
+}
+
+func sortResponses(responses []Response) {
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].StatusCode < responses[j].StatusCode
+	})
+}
+
+// preferredMediaType 优先选择 application/json，其次是其他 JSON 类型，再按名称取第一个
+func preferredMediaType(content map[string]interface{}) string {
+	if len(content) == 0 {
+		return ""
+	}
+	if _, ok := content["application/json"]; ok {
+		return "application/json"
+	}
+	names := make([]string, 0, len(content))
+	for name := range content {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if isJSON(name) {
+			return name
+		}
+	}
+	return names[0]
+}
+
+func isJSON(mediaType string) bool {
+	return strings.Contains(strings.ToLower(mediaType), "json")
+}
+
+// renderBody 将示例值转换为响应体；非 JSON 类型的字符串示例原样输出
+func renderBody(value interface{}, mediaType string) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	if text, ok := value.(string); ok {
+		trimmed := strings.TrimSpace(text)
+		// 以字符串形式书写的 JSON 示例直接使用
+		if !isJSON(mediaType) || (json.Valid([]byte(trimmed)) && (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["))) {
+			return text, nil
+		}
+	}
+	body, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode example: %w", err)
+	}
+	return string(body), nil
+}
+
+// sampleHeaders 为响应头生成示例值，schema 取出响应头对应的 schema
+func sampleHeaders(doc *document, value interface{}, schema func(header map[string]interface{}) interface{}) map[string]string {
+	headers := make(map[string]string)
+	for name, item := range object(value) {
+		header := doc.deref(item)
+		var example interface{}
+		if value, ok := header["example"]; ok {
+			example = value
+		} else {
+			example = newSampler(doc).sample(schema(header), 0)
+		}
+		switch v := example.(type) {
+		case nil:
+			continue
+		case string:
+			headers[name] = v
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			headers[name] = string(encoded)
+		}
+	}
+	return headers
+}
+
+// mergeParameters 合并路径级和操作级的参数，同名同位置的参数以操作级为准
+func mergeParameters(doc *document, pathLevel []interface{}, operationLevel []interface{}) []map[string]interface{} {
+	merged := make([]map[string]interface{}, 0, len(pathLevel)+len(operationLevel))
+	index := make(map[string]int)
+	for _, item := range append(append([]interface{}{}, pathLevel...), operationLevel...) {
+		parameter := doc.deref(item)
+		if parameter == nil {
+			continue
+		}
+		key := str(parameter["in"]) + ":" + str(parameter["name"])
+		if i, ok := index[key]; ok {
+			merged[i] = parameter
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, parameter)
+	}
+	return merged
+}
+
+// pathParamTypes 返回路径参数的类型；OpenAPI 3 的类型在 schema 中，Swagger 2 直接写在参数上
+func pathParamTypes(doc *document, parameters []map[string]interface{}) map[string]string {
+	types := make(map[string]string)
+	for _, parameter := range parameters {
+		if str(parameter["in"]) != "path" {
+			continue
+		}
+		schema := parameter
+		if nested := doc.deref(parameter["schema"]); nested != nil {
+			schema = nested
+		}
+		types[str(parameter["name"])] = schemaType(schema)
+	}
+	return types
+}
+
+var templateParam = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// convertPath 将规范中的路径模板转换为 mock 的路径模式：
+// 整数参数加上数字约束，与静态文本混在同一段中的参数（如 {id}.json）转换为带正则的参数
+func convertPath(path string, types map[string]string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		matches := templateParam.FindAllStringSubmatchIndex(segment, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(segment) {
+			name := segment[1 : len(segment)-1]
+			if types[name] == "integer" {
+				segments[i] = "{" + name + ":-?[0-9]+}"
+			}
+			continue
+		}
+		var expr strings.Builder
+		last := 0
+		for _, match := range matches {
+			expr.WriteString(regexp.QuoteMeta(segment[last:match[0]]))
+			expr.WriteString(".+")
+			last = match[1]
+		}
+		expr.WriteString(regexp.QuoteMeta(segment[last:]))
+		name := segment[matches[0][2]:matches[0][3]]
+		segments[i] = "{" + name + ":" + expr.String() + "}"
+	}
+	return strings.Join(segments, "/")
+}
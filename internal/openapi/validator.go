@@ -0,0 +1,543 @@
+package openapi
+
+import (
+	"fmt"
+	"kite/internal/matching"
+	"math"
+	"net/mail"
+	"net/textproto"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 违规所在的位置
+const (
+	LocationPath   = "path"
+	LocationMethod = "method"
+	LocationQuery  = "query"
+	LocationHeader = "header"
+	LocationCookie = "cookie"
+	LocationBody   = "body"
+)
+
+// ignoredHeaders OpenAPI 规定这些请求头不能作为参数描述，由 content 等字段表达
+var ignoredHeaders = map[string]bool{"Accept": true, "Content-Type": true, "Authorization": true}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Violation 请求中不符合规范的一处，Tag 和 Param 与 validator 字段错误的含义一致，
+// 例如 required、oneof、gte，另外还有 type、pattern、format 等 schema 特有的标签
+type Violation struct {
+	Location string
+	Field    string
+	Tag      string
+	Param    string
+}
+
+// Validator 按规范中的操作定义校验请求的路径参数、查询参数、请求头和 JSON 请求体
+type Validator struct {
+	doc        *document
+	swagger2   bool
+	operations []*operationSpec
+}
+
+type operationSpec struct {
+	method     string
+	path       string
+	pattern    *matching.PathPattern
+	operation  map[string]interface{}
+	parameters []map[string]interface{}
+}
+
+// NewValidator 解析规范并编译每个操作的路径模式
+func NewValidator(data []byte) (*Validator, error) {
+	doc, err := load(data)
+	if err != nil {
+		return nil, err
+	}
+	version, err := doc.version()
+	if err != nil {
+		return nil, err
+	}
+	v := &Validator{doc: doc, swagger2: version == "2.0"}
+	err = eachOperation(doc, func(path string, method string, operation map[string]interface{}, parameters []map[string]interface{}) error {
+		// 不加类型约束，类型不对的路径参数也能找到操作并报告具体的错误
+		pattern, err := matching.ParsePath(convertPath(path, nil))
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+		}
+		v.operations = append(v.operations, &operationSpec{
+			method:     strings.ToUpper(method),
+			path:       path,
+			pattern:    pattern,
+			operation:  operation,
+			parameters: parameters,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Validate 找到请求对应的操作并校验，返回操作的描述（如 GET /pets/{id}）和全部违规；
+// 规范中没有定义 OPTIONS 时不校验，避免拦截 CORS 预检请求
+func (v *Validator) Validate(method string, path string, req *matching.Request) (string, []Violation) {
+	var (
+		found   *operationSpec
+		params  map[string]string
+		methods []string
+	)
+	for _, op := range v.operations {
+		matched, ok := op.pattern.Match(path)
+		if !ok {
+			continue
+		}
+		methods = append(methods, op.method)
+		if op.method != method {
+			continue
+		}
+		if found == nil || matching.Compare(op.pattern, found.pattern) > 0 {
+			found, params = op, matched
+		}
+	}
+	if found == nil {
+		// 与 mock 的匹配规则一致，没有定义 HEAD 时按 GET 校验
+		if method == "HEAD" && contains(methods, "GET") {
+			return v.Validate("GET", path, req)
+		}
+		if method == "OPTIONS" {
+			return "", nil
+		}
+		if len(methods) == 0 {
+			return "", []Violation{{Location: LocationPath, Field: "path", Tag: "operation"}}
+		}
+		sort.Strings(methods)
+		return "", []Violation{{Location: LocationMethod, Field: "method", Tag: "oneof", Param: strings.Join(methods, " ")}}
+	}
+
+	c := &checker{doc: v.doc}
+	for _, parameter := range found.parameters {
+		c.parameter(parameter, params, req, v.swagger2)
+	}
+	if v.swagger2 {
+		c.swagger2Body(found, req)
+	} else {
+		c.requestBody(v.doc.deref(found.operation["requestBody"]), req)
+	}
+	return found.method + " " + found.path, c.violations
+}
+
+// checker 收集一次校验中的全部违规
+type checker struct {
+	doc        *document
+	violations []Violation
+}
+
+func (c *checker) add(location string, field string, tag string, param string) {
+	c.violations = append(c.violations, Violation{Location: location, Field: field, Tag: tag, Param: param})
+}
+
+// parameter 校验路径、查询、请求头和 cookie 参数；查询参数和请求头中的字符串按 schema 的类型转换后再校验
+func (c *checker) parameter(parameter map[string]interface{}, pathParams map[string]string, req *matching.Request, swagger2 bool) {
+	name := str(parameter["name"])
+	location := str(parameter["in"])
+	required := parameter["required"] == true
+
+	var values []string
+	switch location {
+	case LocationPath:
+		if value, ok := pathParams[name]; ok {
+			values = []string{value}
+		}
+	case LocationQuery:
+		values = req.Query[name]
+	case LocationHeader:
+		if ignoredHeaders[textproto.CanonicalMIMEHeaderKey(name)] {
+			return
+		}
+		values = req.Header.Values(name)
+	case LocationCookie:
+		if value, ok := req.Cookies[name]; ok {
+			values = []string{value}
+		}
+	default:
+		// Swagger 2 的 body、formData 参数在请求体中校验
+		return
+	}
+	if len(values) == 0 {
+		if required {
+			c.add(location, name, "required", "")
+		}
+		return
+	}
+
+	schema := parameter
+	if !swagger2 {
+		schema = c.doc.deref(parameter["schema"])
+	}
+	schema = c.doc.deref(schema)
+	if schema == nil {
+		return
+	}
+	if schemaType(schema) == "array" {
+		items := c.doc.deref(schema["items"])
+		if len(values) == 1 && strings.Contains(values[0], ",") {
+			values = strings.Split(values[0], ",")
+		}
+		converted := make([]interface{}, 0, len(values))
+		for i, value := range values {
+			item, ok := convertScalar(value, schemaType(items))
+			if !ok {
+				c.add(location, fmt.Sprintf("%s[%d]", name, i), "type", schemaType(items))
+				return
+			}
+			converted = append(converted, item)
+		}
+		c.value(schema, converted, location, name, 0)
+		return
+	}
+	value, ok := convertScalar(values[0], schemaType(schema))
+	if !ok {
+		c.add(location, name, "type", schemaType(schema))
+		return
+	}
+	c.value(schema, value, location, name, 0)
+}
+
+// requestBody 校验 OpenAPI 3 的 requestBody：是否必填、Content-Type 是否允许，JSON 请求体按 schema 校验
+func (c *checker) requestBody(body map[string]interface{}, req *matching.Request) {
+	if body == nil {
+		return
+	}
+	c.body(body["required"] == true, object(body["content"]), func(media interface{}) interface{} {
+		return c.doc.deref(media)["schema"]
+	}, req)
+}
+
+// swagger2Body 校验 Swagger 2 中 in: body 的参数，允许的 Content-Type 来自 consumes
+func (c *checker) swagger2Body(op *operationSpec, req *matching.Request) {
+	for _, parameter := range op.parameters {
+		if str(parameter["in"]) != LocationBody {
+			continue
+		}
+		consumes := list(op.operation["consumes"])
+		if consumes == nil {
+			consumes = list(c.doc.root["consumes"])
+		}
+		content := make(map[string]interface{}, len(consumes))
+		for _, item := range consumes {
+			content[str(item)] = true
+		}
+		if len(content) == 0 {
+			content["application/json"] = true
+		}
+		c.body(parameter["required"] == true, content, func(interface{}) interface{} {
+			return parameter["schema"]
+		}, req)
+		return
+	}
+}
+
+func (c *checker) body(required bool, content map[string]interface{}, schemaOf func(media interface{}) interface{}, req *matching.Request) {
+	if len(strings.TrimSpace(string(req.Body))) == 0 {
+		if required {
+			c.add(LocationBody, "body", "required", "")
+		}
+		return
+	}
+	if len(content) == 0 {
+		return
+	}
+	mediaType, ok := matchMediaType(content, req.ContentType)
+	if !ok {
+		allowed := make([]string, 0, len(content))
+		for name := range content {
+			allowed = append(allowed, name)
+		}
+		sort.Strings(allowed)
+		c.add(LocationHeader, "Content-Type", "oneof", strings.Join(allowed, " "))
+		return
+	}
+	if !isJSON(req.ContentType) {
+		return
+	}
+	document, err := req.JSON()
+	if err != nil {
+		c.add(LocationBody, "body", "json", "")
+		return
+	}
+	c.value(schemaOf(content[mediaType]), document, LocationBody, "body", 0)
+}
+
+// matchMediaType 按精确匹配、image/* 通配、*/* 通配的顺序查找请求的 Content-Type
+func matchMediaType(content map[string]interface{}, contentType string) (string, bool) {
+	if _, ok := content[contentType]; ok {
+		return contentType, true
+	}
+	if major, _, found := strings.Cut(contentType, "/"); found {
+		if _, ok := content[major+"/*"]; ok {
+			return major + "/*", true
+		}
+	}
+	if _, ok := content["*/*"]; ok {
+		return "*/*", true
+	}
+	return "", false
+}
+
+// value 按 schema 校验一个值，field 为出错时报告的字段路径，例如 body.items[0].name
+func (c *checker) value(rawSchema interface{}, value interface{}, location string, field string, depth int) {
+	schema := c.doc.deref(rawSchema)
+	if schema == nil || depth > maxSchemaDepth*4 {
+		return
+	}
+	if value == nil {
+		if schema["nullable"] == true || schema["x-nullable"] == true || allowsNull(schema) || len(schema) == 0 {
+			return
+		}
+		if t := schemaType(schema); t != "" {
+			c.add(location, field, "type", t)
+		}
+		return
+	}
+	for _, part := range list(schema["allOf"]) {
+		c.value(part, value, location, field, depth+1)
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		alternatives := list(schema[key])
+		if len(alternatives) == 0 {
+			continue
+		}
+		// anyOf 命中一个即可，oneOf 要求恰好命中一个
+		matched := 0
+		for _, alternative := range alternatives {
+			probe := &checker{doc: c.doc}
+			probe.value(alternative, value, location, field, depth+1)
+			if len(probe.violations) == 0 {
+				matched++
+				if key == "anyOf" {
+					break
+				}
+			}
+		}
+		switch {
+		case matched == 0:
+			c.add(location, field, "schema", "")
+		case matched > 1 && key == "oneOf":
+			c.add(location, field, "one_schema", strconv.Itoa(matched))
+		}
+	}
+	if enum := list(schema["enum"]); len(enum) > 0 {
+		allowed := false
+		names := make([]string, 0, len(enum))
+		for _, item := range enum {
+			names = append(names, fmt.Sprint(item))
+			allowed = allowed || equalValues(item, value)
+		}
+		if !allowed {
+			c.add(location, field, "oneof", strings.Join(names, " "))
+			return
+		}
+	}
+
+	t := schemaType(schema)
+	if t != "" && !hasType(value, t) {
+		c.add(location, field, "type", t)
+		return
+	}
+	switch v := value.(type) {
+	case string:
+		c.stringValue(schema, v, location, field)
+	case []interface{}:
+		if minItems, ok := number(schema["minItems"]); ok && float64(len(v)) < minItems {
+			c.add(location, field, "min_items", formatNumber(minItems))
+		}
+		if maxItems, ok := number(schema["maxItems"]); ok && float64(len(v)) > maxItems {
+			c.add(location, field, "max_items", formatNumber(maxItems))
+		}
+		if items, ok := schema["items"]; ok {
+			for i, item := range v {
+				c.value(items, item, location, fmt.Sprintf("%s[%d]", field, i), depth+1)
+			}
+		}
+	case map[string]interface{}:
+		c.objectValue(schema, v, location, field, depth)
+	default:
+		if n, ok := number(value); ok {
+			c.numberValue(schema, n, location, field)
+		}
+	}
+}
+
+func (c *checker) stringValue(schema map[string]interface{}, value string, location string, field string) {
+	length := float64(len([]rune(value)))
+	if minLength, ok := number(schema["minLength"]); ok && length < minLength {
+		c.add(location, field, "min", formatNumber(minLength))
+	}
+	if maxLength, ok := number(schema["maxLength"]); ok && length > maxLength {
+		c.add(location, field, "max", formatNumber(maxLength))
+	}
+	if pattern := str(schema["pattern"]); pattern != "" {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(value) {
+			c.add(location, field, "pattern", pattern)
+		}
+	}
+	switch format := str(schema["format"]); format {
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			c.add(location, field, "email", "")
+		}
+	case "uuid":
+		if !uuidPattern.MatchString(value) {
+			c.add(location, field, "uuid", "")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			c.add(location, field, "format", format)
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			c.add(location, field, "format", format)
+		}
+	}
+}
+
+func (c *checker) numberValue(schema map[string]interface{}, value float64, location string, field string) {
+	if minimum, ok := number(schema["minimum"]); ok {
+		// OpenAPI 3.0 中 exclusiveMinimum 是布尔值，3.1 中是数值
+		if schema["exclusiveMinimum"] == true {
+			if value <= minimum {
+				c.add(location, field, "gt", formatNumber(minimum))
+			}
+		} else if value < minimum {
+			c.add(location, field, "gte", formatNumber(minimum))
+		}
+	}
+	if exclusive, ok := number(schema["exclusiveMinimum"]); ok && value <= exclusive {
+		c.add(location, field, "gt", formatNumber(exclusive))
+	}
+	if maximum, ok := number(schema["maximum"]); ok {
+		if schema["exclusiveMaximum"] == true {
+			if value >= maximum {
+				c.add(location, field, "lt", formatNumber(maximum))
+			}
+		} else if value > maximum {
+			c.add(location, field, "lte", formatNumber(maximum))
+		}
+	}
+	if exclusive, ok := number(schema["exclusiveMaximum"]); ok && value >= exclusive {
+		c.add(location, field, "lt", formatNumber(exclusive))
+	}
+}
+
+// objectValue 校验必填属性、每个属性的值以及是否允许额外的属性；只读属性不要求在请求中出现
+func (c *checker) objectValue(schema map[string]interface{}, value map[string]interface{}, location string, field string, depth int) {
+	properties := object(schema["properties"])
+	for _, item := range list(schema["required"]) {
+		name := str(item)
+		if _, ok := value[name]; ok {
+			continue
+		}
+		if c.doc.deref(properties[name])["readOnly"] == true {
+			continue
+		}
+		c.add(location, field+"."+name, "required", "")
+	}
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if property, ok := properties[key]; ok {
+			c.value(property, value[key], location, field+"."+key, depth+1)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				c.add(location, field+"."+key, "unknown", "")
+			}
+		case map[string]interface{}:
+			c.value(additional, value[key], location, field+"."+key, depth+1)
+		}
+	}
+}
+
+// convertScalar 将查询参数、请求头中的字符串按 schema 类型转换，无法转换时返回 false
+func convertScalar(value string, t string) (interface{}, bool) {
+	switch t {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		return float64(n), err == nil
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		return n, err == nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		return b, err == nil
+	}
+	return value, true
+}
+
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		n, ok := number(value)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := number(value)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+// allowsNull OpenAPI 3.1 通过类型数组中的 null 表示可以为空
+func allowsNull(schema map[string]interface{}) bool {
+	for _, item := range list(schema["type"]) {
+		if str(item) == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues 比较枚举值，数值统一按 float64 比较
+func equalValues(a interface{}, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
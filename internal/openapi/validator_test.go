@@ -0,0 +1,121 @@
+package openapi
+
+import (
+	"kite/internal/matching"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const validatorSpec = `
+openapi: 3.0.3
+info: {title: orders, version: "1"}
+paths:
+  /orders/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: integer, minimum: 1}}
+    get:
+      parameters:
+        - {name: verbose, in: query, schema: {type: boolean}}
+        - {name: X-Client, in: header, required: true, schema: {type: string, enum: [web, ios]}}
+      responses: {"200": {description: ok}}
+  /payments:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [method]
+              properties:
+                method:
+                  oneOf:
+                    - {$ref: "#/components/schemas/Card"}
+                    - {$ref: "#/components/schemas/Wallet"}
+                note:
+                  anyOf:
+                    - {type: string, maxLength: 5}
+                    - {type: string, pattern: "^[a-z]+$"}
+      responses: {"201": {description: created}}
+components:
+  schemas:
+    Card:
+      type: object
+      required: [number]
+      properties:
+        number: {type: string, pattern: "^[0-9]{16}$"}
+    Wallet:
+      type: object
+      required: [wallet]
+      properties:
+        wallet: {type: string}
+`
+
+func TestValidatorValidate(t *testing.T) {
+	validator, err := NewValidator([]byte(validatorSpec))
+	if err != nil {
+		t.Fatalf("NewValidator: %v", err)
+	}
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		header  map[string]string
+		body    string
+		want    []string
+		wantOps string
+	}{
+		{name: "valid path and header", method: "GET", target: "/orders/5?verbose=true", header: map[string]string{"X-Client": "web"}, wantOps: "GET /orders/{id}"},
+		{name: "path parameter below minimum", method: "GET", target: "/orders/0", header: map[string]string{"X-Client": "web"}, want: []string{"path:id:gte"}},
+		{name: "query parameter wrong type", method: "GET", target: "/orders/5?verbose=maybe", header: map[string]string{"X-Client": "web"}, want: []string{"query:verbose:type"}},
+		{name: "missing required header", method: "GET", target: "/orders/5", want: []string{"header:X-Client:required"}},
+		{name: "header not in enum", method: "GET", target: "/orders/5", header: map[string]string{"X-Client": "tv"}, want: []string{"header:X-Client:oneof"}},
+		{name: "method not defined", method: "DELETE", target: "/orders/5", want: []string{"method:method:oneof"}},
+		{name: "oneOf matches exactly one", method: "POST", target: "/payments", body: `{"method":{"number":"4111111111111111"}}`},
+		{name: "oneOf matches none", method: "POST", target: "/payments", body: `{"method":{"number":"41"}}`, want: []string{"body:body.method:schema"}},
+		{name: "oneOf matches more than one", method: "POST", target: "/payments", body: `{"method":{"number":"4111111111111111","wallet":"w"}}`, want: []string{"body:body.method:one_schema"}},
+		{name: "anyOf matching both is fine", method: "POST", target: "/payments", body: `{"method":{"wallet":"w"},"note":"abc"}`},
+		{name: "anyOf matches none", method: "POST", target: "/payments", body: `{"method":{"wallet":"w"},"note":"ABCDEFG"}`, want: []string{"body:body.note:schema"}},
+		{name: "missing required body", method: "POST", target: "/payments", want: []string{"body:body:required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+			for key, value := range tt.header {
+				r.Header.Set(key, value)
+			}
+			req, err := matching.NewRequest(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := r.URL.Path
+			operation, violations := validator.Validate(tt.method, path, req)
+			if tt.wantOps != "" && operation != tt.wantOps {
+				t.Fatalf("operation = %q, want %q", operation, tt.wantOps)
+			}
+			got := make([]string, 0, len(violations))
+			for _, violation := range violations {
+				got = append(got, violation.Location+":"+violation.Field+":"+violation.Tag)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatorUnknownPath(t *testing.T) {
+	validator, err := NewValidator([]byte(validatorSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := matching.NewRequest(httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if _, violations := validator.Validate(http.MethodGet, "/unknown", req); len(violations) != 1 || violations[0].Tag != "operation" {
+		t.Fatalf("violations = %+v, want a single operation violation", violations)
+	}
+}
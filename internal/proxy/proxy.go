@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"kite/internal/configs"
+	"kite/pkg/utils/httpclient"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// 命名空间的工作模式
+const (
+	// ModeMock 按已配置的 mock 回放
+	ModeMock = "mock"
+	// ModeRecord 将请求转发到上游，并把每一对请求和响应录制为新的 mock
+	ModeRecord = "record"
+)
+
+const (
+	// forwardTimeout 转发到上游的超时时间
+	forwardTimeout = 30 * time.Second
+	// defaultMaxBodyBytes 未配置时上游响应体的长度上限
+	defaultMaxBodyBytes = 10 << 20
+)
+
+// hopHeaders 逐跳头以及由传输层重新生成的头，转发和录制时都不保留
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"Content-Length",
+	// 由客户端自动协商压缩并解压，录制下来的是解压后的响应体
+	"Accept-Encoding",
+	"Content-Encoding",
+	"Host",
+	"Date",
+}
+
+// Exchange 上游返回的响应
+type Exchange struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Forwarder 将 mock 请求转发到真实的上游服务。
+// 上游返回什么就原样返回什么：不重试（请求可能不是幂等的）、不跟随重定向、不补充任何请求头
+type Forwarder struct {
+	client       *httpclient.Client
+	maxBodyBytes int64
+}
+
+func NewForwarder(cfg *configs.ProxyConfig) *Forwarder {
+	maxBodyBytes := int64(cfg.MaxBodyBytes)
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+	return &Forwarder{
+		client: httpclient.New(httpclient.ClientConfig{
+			Timeout: forwardTimeout,
+			RetryConfig: httpclient.RetryConfig{
+				MaxRetries:  -1,
+				RetryPolicy: httpclient.NeverRetry,
+			},
+			EnableCompression: true,
+			DisableRedirects:  true,
+		}),
+		maxBodyBytes: maxBodyBytes,
+	}
+}
+
+// Forward 将请求以相同的方法、路径、查询参数、请求头和请求体发送到 upstream，客户端断开时 ctx 取消转发
+func (f *Forwarder) Forward(ctx context.Context, upstream string, r *http.Request, path string, body []byte) (*Exchange, error) {
+	target := strings.TrimRight(upstream, "/") + path
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	var payload io.Reader
+	if len(body) > 0 {
+		payload = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method, target, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upstream request: %w", err)
+	}
+	for key, values := range r.Header {
+		if !IsHopHeader(key) {
+			req.Header[key] = append([]string(nil), values...)
+		}
+	}
+	// 客户端没有发送 User-Agent 时不让 net/http 补上默认值
+	if _, ok := req.Header["User-Agent"]; !ok {
+		req.Header.Set("User-Agent", "")
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upstream response: %w", err)
+	}
+	if int64(len(respBody)) > f.maxBodyBytes {
+		return nil, fmt.Errorf("upstream response exceeds %d bytes", f.maxBodyBytes)
+	}
+	header := resp.Header.Clone()
+	for _, key := range hopHeaders {
+		header.Del(key)
+	}
+	return &Exchange{
+		StatusCode: resp.StatusCode,
+		Header:     header,
+		Body:       respBody,
+	}, nil
+}
+
+// IsHopHeader 判断响应头是否由传输层维护，转发、录制和导入时都不保留
+func IsHopHeader(key string) bool {
+	key = textproto.CanonicalMIMEHeaderKey(key)
+	for _, hop := range hopHeaders {
+		if key == hop {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"kite/internal/configs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForwardPassesRequestThrough(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.Header().Set("X-Upstream", "yes")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name        string
+		body        string
+		header      http.Header
+		wantHeaders http.Header
+	}{
+		{
+			name:        "no headers are injected",
+			wantHeaders: http.Header{},
+		},
+		{
+			name:        "multi-valued headers are kept as sent",
+			body:        "payload",
+			header:      http.Header{"X-Tag": {"a", "b"}, "Content-Type": {"text/csv"}, "User-Agent": {"curl/8"}},
+			wantHeaders: http.Header{"X-Tag": {"a", "b"}, "Content-Type": {"text/csv"}, "User-Agent": {"curl/8"}},
+		},
+		{
+			name:        "hop-by-hop headers are dropped",
+			header:      http.Header{"Connection": {"close"}, "Proxy-Authorization": {"secret"}, "X-Keep": {"1"}},
+			wantHeaders: http.Header{"X-Keep": {"1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/orders?page=2", strings.NewReader(tt.body))
+			r.Header = tt.header
+			if r.Header == nil {
+				r.Header = http.Header{}
+			}
+			exchange, err := NewForwarder(&configs.ProxyConfig{}).Forward(context.Background(), upstream.URL+"/", r, "/orders", []byte(tt.body))
+			if err != nil {
+				t.Fatalf("Forward: %v", err)
+			}
+			if exchange.StatusCode != http.StatusCreated || string(exchange.Body) != "created" || exchange.Header.Get("X-Upstream") != "yes" {
+				t.Fatalf("unexpected exchange %+v", exchange)
+			}
+			if got.Method != http.MethodPost || got.URL.RequestURI() != "/orders?page=2" || gotBody != tt.body {
+				t.Fatalf("upstream received %s %s %q", got.Method, got.URL.RequestURI(), gotBody)
+			}
+			received := got.Header.Clone()
+			// 由传输层维护的头不属于转发的内容
+			received.Del("Accept-Encoding")
+			received.Del("Content-Length")
+			if !reflect.DeepEqual(received, tt.wantHeaders) {
+				t.Fatalf("upstream headers = %v, want %v", received, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+func TestForwardDoesNotRetry(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/pay", nil)
+	exchange, err := NewForwarder(&configs.ProxyConfig{}).Forward(context.Background(), upstream.URL, r, "/pay", nil)
+	if err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	if exchange.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Fatalf("status %d after %d calls, want one call returning 503", exchange.StatusCode, calls.Load())
+	}
+
+	upstream.Close()
+	start := time.Now()
+	if _, err := NewForwarder(&configs.ProxyConfig{}).Forward(context.Background(), upstream.URL, r, "/pay", nil); err == nil {
+		t.Fatalf("Forward to a closed upstream should fail")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("transport errors must fail fast, took %v", elapsed)
+	}
+}
+
+func TestForwardDoesNotFollowRedirects(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer upstream.Close()
+
+	r := httptest.NewRequest(http.MethodGet, "/old", nil)
+	exchange, err := NewForwarder(&configs.ProxyConfig{}).Forward(context.Background(), upstream.URL, r, "/old", nil)
+	if err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	if exchange.StatusCode != http.StatusFound || exchange.Header.Get("Location") != "/elsewhere" {
+		t.Fatalf("redirect must be returned as is, got %d %v", exchange.StatusCode, exchange.Header)
+	}
+}
+
+func TestForwardStopsWhenContextIsCancelled(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	start := time.Now()
+	if _, err := NewForwarder(&configs.ProxyConfig{}).Forward(ctx, upstream.URL, r, "/slow", nil); err == nil {
+		t.Fatalf("Forward should fail once the context is cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Forward kept waiting %v after the context was cancelled", elapsed)
+	}
+}
+
+func TestForwardLimitsResponseBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		size    int
+		wantErr bool
+	}{
+		{name: "below the limit", limit: 8, size: 7},
+		{name: "at the limit", limit: 8, size: 8},
+		{name: "over the limit", limit: 8, size: 9, wantErr: true},
+		{name: "default limit", size: defaultMaxBodyBytes},
+		{name: "over the default limit", size: defaultMaxBodyBytes + 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(strings.Repeat("x", tt.size)))
+			}))
+			defer upstream.Close()
+
+			r := httptest.NewRequest(http.MethodGet, "/large", nil)
+			exchange, err := NewForwarder(&configs.ProxyConfig{MaxBodyBytes: tt.limit}).Forward(context.Background(), upstream.URL, r, "/large", nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Forward of a %d byte body should fail", tt.size)
+				}
+				return
+			}
+			if err != nil || len(exchange.Body) != tt.size {
+				t.Fatalf("Forward = %v, body of %d bytes, want %d", err, len(exchange.Body), tt.size)
+			}
+		})
+	}
+}
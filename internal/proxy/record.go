@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"encoding/json"
+	"kite/internal/matching"
+	"sort"
+)
+
+// 录制请求体匹配条件的方式
+const (
+	// RecordBodyNone 不录制请求体
+	RecordBodyNone = "none"
+	// RecordBodyExact 请求体全文相等
+	RecordBodyExact = "exact"
+	// RecordBodyJSON 请求体是 JSON 时按语义相等匹配，否则退化为全文相等
+	RecordBodyJSON = "json"
+)
+
+// RecordConfig 录制时根据真实请求生成哪些匹配条件，默认只按方法和路径匹配
+type RecordConfig struct {
+	// Headers 作为匹配条件的请求头，请求中没有该头时录制为 absent
+	Headers []string `json:"headers,omitempty"`
+	// Query 是否将查询参数录制为匹配条件
+	Query bool   `json:"query"`
+	Body  string `json:"body,omitempty" validate:"omitempty,oneof=none exact json"`
+}
+
+// Matchers 根据录制配置和真实请求生成 mock 的匹配条件
+func (c *RecordConfig) Matchers(req *matching.Request) *matching.RequestMatchers {
+	matchers := &matching.RequestMatchers{}
+	if c == nil {
+		return matchers
+	}
+	for _, name := range c.Headers {
+		value := req.Header.Get(name)
+		if value == "" {
+			matchers.Headers = append(matchers.Headers, matching.FieldMatcher{Name: name, Operator: matching.OperatorAbsent})
+			continue
+		}
+		matchers.Headers = append(matchers.Headers, matching.FieldMatcher{Name: name, Operator: matching.OperatorEquals, Value: value})
+	}
+	if c.Query {
+		keys := make([]string, 0, len(req.Query))
+		for key := range req.Query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			matchers.Query = append(matchers.Query, matching.FieldMatcher{Name: key, Operator: matching.OperatorEquals, Value: req.Query.Get(key)})
+		}
+	}
+	if len(req.Body) == 0 {
+		return matchers
+	}
+	switch c.Body {
+	case RecordBodyExact:
+		matchers.Body = append(matchers.Body, matching.BodyMatcher{Type: matching.BodyTypeText, Operator: matching.OperatorEquals, Value: string(req.Body)})
+	case RecordBodyJSON:
+		if document, err := req.JSON(); err == nil && document != nil {
+			matchers.Body = append(matchers.Body, matching.BodyMatcher{Type: matching.BodyTypeJSONEquals, Value: string(req.Body)})
+		} else {
+			matchers.Body = append(matchers.Body, matching.BodyMatcher{Type: matching.BodyTypeText, Operator: matching.OperatorEquals, Value: string(req.Body)})
+		}
+	}
+	return matchers
+}
+
+// DecodeRecordConfig 解析存储的录制配置，未配置时返回 nil
+func DecodeRecordConfig(raw json.RawMessage) (*RecordConfig, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var config RecordConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"kite/internal/matching"
+	"regexp"
+)
+
+// RewriteRule 透传响应的改写规则，Path 为空时对所有路径生效。
+// 依次执行：替换状态码、删除响应头、设置响应头、按正则替换响应体
+type RewriteRule struct {
+	Path          string            `json:"path,omitempty"`
+	StatusCode    int               `json:"status_code,omitempty" validate:"omitempty,gte=100,lte=599"`
+	RemoveHeaders []string          `json:"remove_headers,omitempty"`
+	SetHeaders    map[string]string `json:"set_headers,omitempty"`
+	Replace       []Replacement     `json:"replace,omitempty" validate:"dive"`
+}
+
+// Replacement 响应体的正则替换，Replacement 中可以使用 $1、${name} 引用捕获组
+type Replacement struct {
+	Pattern     string `json:"pattern" validate:"required"`
+	Replacement string `json:"replacement"`
+}
+
+// Validate 检查路径模式和正则表达式能否编译
+func (r *RewriteRule) Validate() error {
+	if r.Path != "" {
+		if _, err := matching.ParsePath(r.Path); err != nil {
+			return err
+		}
+	}
+	for _, replacement := range r.Replace {
+		if _, err := regexp.Compile(replacement.Pattern); err != nil {
+			return fmt.Errorf("invalid replace pattern %q: %w", replacement.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// Apply 将路径匹配的规则按顺序作用到上游响应上
+func (r *RewriteRule) Apply(exchange *Exchange, path string) error {
+	if r.Path != "" {
+		pattern, err := matching.CompilePath(r.Path)
+		if err != nil {
+			return err
+		}
+		if _, ok := pattern.Match(path); !ok {
+			return nil
+		}
+	}
+	if r.StatusCode != 0 {
+		exchange.StatusCode = r.StatusCode
+	}
+	for _, key := range r.RemoveHeaders {
+		exchange.Header.Del(key)
+	}
+	for key, value := range r.SetHeaders {
+		exchange.Header.Set(key, value)
+	}
+	for _, replacement := range r.Replace {
+		re, err := regexp.Compile(replacement.Pattern)
+		if err != nil {
+			return err
+		}
+		exchange.Body = re.ReplaceAll(exchange.Body, []byte(replacement.Replacement))
+	}
+	return nil
+}
+
+// DecodeRewriteRules 解析存储的改写规则
+func DecodeRewriteRules(raw json.RawMessage) ([]RewriteRule, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var rules []RewriteRule
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,49 @@
+package proxy
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRewriteRuleApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       RewriteRule
+		path       string
+		wantStatus int
+		wantBody   string
+		wantHeader string
+	}{
+		{"no path applies everywhere", RewriteRule{StatusCode: 200}, "/any", 200, "id=42 host=a.internal", "keep"},
+		{"path mismatch leaves response alone", RewriteRule{Path: "/users/{id}", StatusCode: 200}, "/orders/1", 500, "id=42 host=a.internal", "keep"},
+		{"path match", RewriteRule{Path: "/users/{id}", StatusCode: 201}, "/users/1", 201, "id=42 host=a.internal", "keep"},
+		{"remove and set headers", RewriteRule{RemoveHeaders: []string{"X-Old"}, SetHeaders: map[string]string{"X-New": "set"}}, "/", 500, "id=42 host=a.internal", "set"},
+		{"replace with capture groups", RewriteRule{Replace: []Replacement{{Pattern: `host=(\w+)\.internal`, Replacement: "host=$1.public"}}}, "/", 500, "id=42 host=a.public", "keep"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchange := &Exchange{StatusCode: 500, Header: http.Header{"X-Old": {"keep"}}, Body: []byte("id=42 host=a.internal")}
+			if err := tt.rule.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if err := tt.rule.Apply(exchange, tt.path); err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			header := exchange.Header.Get("X-Old")
+			if header == "" {
+				header = exchange.Header.Get("X-New")
+			}
+			if exchange.StatusCode != tt.wantStatus || string(exchange.Body) != tt.wantBody || header != tt.wantHeader {
+				t.Fatalf("got %d %q %q, want %d %q %q", exchange.StatusCode, exchange.Body, header, tt.wantStatus, tt.wantBody, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestRewriteRuleValidate(t *testing.T) {
+	for _, rule := range []RewriteRule{{Path: "/a/**/b"}, {Replace: []Replacement{{Pattern: "("}}}} {
+		if err := rule.Validate(); err == nil {
+			t.Fatalf("Validate should reject %+v", rule)
+		}
+	}
+}
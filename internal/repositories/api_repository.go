@@ -2,42 +2,73 @@ package repositories
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
+	"errors"
+	"fmt"
 	"gorm.io/gorm"
 	"kite/internal/api/payloads"
+	"kite/internal/configs"
 	"kite/internal/database"
 	KiteError "kite/internal/errors"
 	"kite/internal/models"
+	"kite/internal/tenant"
+	"strings"
 )
 
+// ErrRecordNotFound 记录不存在，屏蔽底层存储的错误类型
+var ErrRecordNotFound = errors.New("record not found")
+
+// ApiFilter 列表查询条件，空值表示不过滤
+type ApiFilter struct {
+	UserId     string
+	Method     string
+	PathPrefix string
+	Tag        string
+	Offset     int
+	Limit      int
+}
+
 type ApiRepository interface {
 	CreateApi(ctx context.Context, payload payloads.MockApiPayload, uuid string) error
-	QueryApiWithUidAndPathAndMethod(ctx context.Context, uid string, path string, method string) (*models.Api, error)
+	InsertApi(ctx context.Context, api *models.Api) error
+	// InsertApis 写入多个 mock，任何一个写入失败时都不写入
+	InsertApis(ctx context.Context, apis []*models.Api) error
+	UpdateApi(ctx context.Context, api *models.Api) error
+	DeleteApiByUuid(ctx context.Context, uuid string) error
+	GetApiByUuid(ctx context.Context, uuid string) (*models.Api, error)
+	ListApis(ctx context.Context, filter ApiFilter) ([]*models.Api, int64, error)
+	QueryApisWithUidAndMethod(ctx context.Context, uid string, method string) ([]*models.Api, error)
+	// Fingerprint 返回反映全部 mock 当前状态的摘要，任何新增、修改、删除都会使摘要变化，用于感知其他实例的修改
+	Fingerprint(ctx context.Context) (string, error)
 }
 
 type apiRepository struct {
 	db *gorm.DB
 }
 
-func NewApiRepository(connection *database.MySQLConnection) ApiRepository {
-	return &apiRepository{db: connection.GetDB()}
+// NewApiRepository 根据配置的驱动创建 mock 存储
+func NewApiRepository(cfg *configs.DatabaseConfig, connection *database.Connection) (ApiRepository, error) {
+	switch connection.Driver() {
+	case database.DriverMemory:
+		return NewMemoryApiRepository(), nil
+	case database.DriverFile:
+		return NewFileApiRepository(cfg.Path, cfg.Format)
+	default:
+		return &apiRepository{db: connection.GetDB()}, nil
+	}
 }
 
 func (r *apiRepository) CreateApi(ctx context.Context, payload payloads.MockApiPayload, uuid string) error {
-	var headers json.RawMessage
-	headers, err := payload.GetHeadersJSON()
-	if err != nil {
+	api := &models.Api{Uuid: uuid}
+	if err := payload.ApplyTo(api); err != nil {
 		return KiteError.New(KiteError.MarshalError, err)
 	}
-	api := &models.Api{
-		Uuid:         uuid,
-		UserId:       payload.UserId,
-		Path:         payload.Path,
-		Method:       payload.Method,
-		StatusCode:   payload.StatusCode,
-		ContentType:  payload.ContentType,
-		Headers:      headers,
-		ResponseBody: payload.ResponseBody,
+	return r.InsertApi(ctx, api)
+}
+
+func (r *apiRepository) InsertApi(ctx context.Context, api *models.Api) error {
+	if workspace, ok := tenant.Workspace(ctx); ok {
+		api.WorkspaceId = workspace
 	}
 	result := r.db.WithContext(ctx).Create(api)
 	if result.Error != nil {
@@ -46,13 +77,151 @@ func (r *apiRepository) CreateApi(ctx context.Context, payload payloads.MockApiP
 	return nil
 }
 
-func (r *apiRepository) QueryApiWithUidAndPathAndMethod(ctx context.Context, uid string, path string, method string) (*models.Api, error) {
+func (r *apiRepository) InsertApis(ctx context.Context, apis []*models.Api) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, api := range apis {
+			if workspace, ok := tenant.Workspace(ctx); ok {
+				api.WorkspaceId = workspace
+			}
+			if err := tx.Create(api).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateApi 限定工作区时先确认记录属于该工作区，Save 在记录不存在时会插入新记录
+func (r *apiRepository) UpdateApi(ctx context.Context, api *models.Api) error {
+	if workspace, ok := tenant.Workspace(ctx); ok {
+		var count int64
+		if err := scope(ctx, r.db).Model(&models.Api{}).Where("id = ?", api.Id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrRecordNotFound
+		}
+		api.WorkspaceId = workspace
+	}
+	result := r.db.WithContext(ctx).Save(api)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (r *apiRepository) DeleteApiByUuid(ctx context.Context, uuid string) error {
+	result := scope(ctx, r.db).Where("uuid = ?", uuid).Delete(&models.Api{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *apiRepository) GetApiByUuid(ctx context.Context, uuid string) (*models.Api, error) {
 	var api *models.Api
-	result := r.db.WithContext(ctx).
-		Where("user_id = ? AND path = ? AND method = ?", uid, path, method).
-		First(&api)
+	result := scope(ctx, r.db).Where("uuid = ?", uuid).First(&api)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, translateError(result.Error)
 	}
 	return api, nil
 }
+
+func (r *apiRepository) ListApis(ctx context.Context, filter ApiFilter) ([]*models.Api, int64, error) {
+	query := scope(ctx, r.db).Model(&models.Api{})
+	if filter.UserId != "" {
+		query = query.Where("user_id = ?", filter.UserId)
+	}
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if filter.PathPrefix != "" {
+		query = query.Where(likeCondition(r.db, "path"), escapeLike(filter.PathPrefix)+"%")
+	}
+	if filter.Tag != "" {
+		query = query.Where(tagCondition(r.db), filter.Tag)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var apis []*models.Api
+	if filter.Limit > 0 {
+		query = query.Offset(filter.Offset).Limit(filter.Limit)
+	}
+	if err := query.Order("id ASC").Find(&apis).Error; err != nil {
+		return nil, 0, err
+	}
+	return apis, total, nil
+}
+
+// QueryApisWithUidAndMethod 查询命名空间下指定方法的全部 mock，路径匹配由调用方完成
+func (r *apiRepository) QueryApisWithUidAndMethod(ctx context.Context, uid string, method string) ([]*models.Api, error) {
+	var apis []*models.Api
+	result := scope(ctx, r.db).
+		Where("user_id = ? AND method = ?", uid, method).
+		Order("id ASC").
+		Find(&apis)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return apis, nil
+}
+
+// Fingerprint 由记录数、最大 id 和精确到微秒的最近修改时间组成，不限定工作区
+func (r *apiRepository) Fingerprint(ctx context.Context) (string, error) {
+	var row struct {
+		Total       int64
+		MaxId       uint64
+		LastUpdated sql.NullString
+	}
+	err := r.db.WithContext(ctx).Model(&models.Api{}).
+		Select("COUNT(*) AS total, COALESCE(MAX(id), 0) AS max_id, MAX(updated_at) AS last_updated").
+		Scan(&row).Error
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d:%d:%s", row.Total, row.MaxId, row.LastUpdated.String), nil
+}
+
+// scope 将查询限定在 context 中的工作区内
+func scope(ctx context.Context, db *gorm.DB) *gorm.DB {
+	db = db.WithContext(ctx)
+	if workspace, ok := tenant.Workspace(ctx); ok {
+		return db.Where("workspace_id = ?", workspace)
+	}
+	return db
+}
+
+// translateError 将 GORM 的错误转换为仓储层的错误
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRecordNotFound
+	}
+	return err
+}
+
+// likeCondition 前缀匹配条件，sqlite 没有默认的转义字符，需要显式声明
+func likeCondition(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == database.DriverSQLite {
+		return column + ` LIKE ? ESCAPE '\'`
+	}
+	return column + " LIKE ?"
+}
+
+// tagCondition 标签过滤条件，tags 列是 JSON 数组
+func tagCondition(db *gorm.DB) string {
+	if db.Dialector.Name() == database.DriverSQLite {
+		return "EXISTS (SELECT 1 FROM json_each(tags) WHERE json_each.value = ?)"
+	}
+	return "JSON_CONTAINS(tags, JSON_QUOTE(?))"
+}
+
+// escapeLike 转义 LIKE 语句中的通配符
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,573 @@
+// Package conformance 所有存储驱动都必须通过的行为测试。
+// 每个驱动在自己的测试中调用 RunApiRepository、RunNamespaceRepository 与 RunWorkspaceRepository，factory 每次返回一个空的存储
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kite/internal/api/payloads"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"kite/internal/tenant"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// RunApiRepository 验证 ApiRepository 的增删改查、过滤、分页与排序
+func RunApiRepository(t *testing.T, factory func(t *testing.T) repositories.ApiRepository) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		payload := newPayload("u1", "GET", "/users/{id}", "alpha", "beta")
+		payload.ResponseHeaders = []payloads.Headers{{Key: "X-Mock", Value: "1"}}
+		mustCreate(t, repo, payload, "uuid-1")
+
+		api, err := repo.GetApiByUuid(ctx, "uuid-1")
+		if err != nil {
+			t.Fatalf("GetApiByUuid: %v", err)
+		}
+		if api.Id == 0 || api.CreatedAt.IsZero() || api.UpdatedAt.IsZero() {
+			t.Fatalf("id and timestamps must be assigned, got id=%d created_at=%v updated_at=%v", api.Id, api.CreatedAt, api.UpdatedAt)
+		}
+		if api.UserId != "u1" || api.Method != "GET" || api.Path != "/users/{id}" || api.StatusCode != 200 || api.ResponseBody != "ok" {
+			t.Fatalf("unexpected api %+v", api)
+		}
+		headers, err := api.GetHeaders()
+		if err != nil || headers["X-Mock"] != "1" {
+			t.Fatalf("headers = %v, %v", headers, err)
+		}
+		tags, err := api.GetTags()
+		if err != nil || !reflect.DeepEqual(tags, []string{"alpha", "beta"}) {
+			t.Fatalf("tags = %v, %v", tags, err)
+		}
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		repo := factory(t)
+		if _, err := repo.GetApiByUuid(context.Background(), "missing"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("expected ErrRecordNotFound, got %v", err)
+		}
+	})
+
+	t.Run("IdsIncrease", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		var previous uint64
+		for i := 0; i < 3; i++ {
+			api := &models.Api{Uuid: fmt.Sprintf("uuid-%d", i)}
+			payload := newPayload("u1", "GET", fmt.Sprintf("/p%d", i))
+			if err := payload.ApplyTo(api); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.InsertApi(ctx, api); err != nil {
+				t.Fatalf("InsertApi: %v", err)
+			}
+			if api.Id <= previous {
+				t.Fatalf("id %d is not greater than the previous id %d", api.Id, previous)
+			}
+			previous = api.Id
+		}
+	})
+
+	t.Run("InsertApis", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		mustCreate(t, repo, newPayload("u1", "GET", "/existing"), "uuid-0")
+		existing, err := repo.GetApiByUuid(ctx, "uuid-0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch := func(uuids ...string) []*models.Api {
+			apis := make([]*models.Api, 0, len(uuids))
+			for i, uuid := range uuids {
+				api := &models.Api{Uuid: uuid}
+				payload := newPayload("u1", "GET", fmt.Sprintf("/%s/%d", uuid, i))
+				if err := payload.ApplyTo(api); err != nil {
+					t.Fatal(err)
+				}
+				apis = append(apis, api)
+			}
+			return apis
+		}
+
+		if err := repo.InsertApis(ctx, batch("uuid-1", "uuid-2")); err != nil {
+			t.Fatalf("InsertApis: %v", err)
+		}
+		_, total, err := repo.ListApis(ctx, repositories.ApiFilter{})
+		if err != nil || total != 3 {
+			t.Fatalf("ListApis = %d, %v, want 3 apis", total, err)
+		}
+
+		// 最后一个 mock 与已有记录的 id 冲突、响应头无法解析，只要有驱动拒绝写入，前面的 mock 也不能留下
+		failing := batch("uuid-3", "uuid-4")
+		failing[1].Id = existing.Id
+		failing[1].Headers = json.RawMessage(`{`)
+		err = repo.InsertApis(ctx, failing)
+		want := int64(5)
+		if err != nil {
+			want = 3
+		}
+		apis, total, listErr := repo.ListApis(ctx, repositories.ApiFilter{})
+		if listErr != nil || total != want {
+			t.Fatalf("after InsertApis returned %v got %v (total %d), want %d apis", err, uuids(apis), total, want)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		mustCreate(t, repo, newPayload("u1", "GET", "/a"), "uuid-1")
+		api, err := repo.GetApiByUuid(ctx, "uuid-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		api.ResponseBody = "changed"
+		api.StatusCode = 418
+		if err := repo.UpdateApi(ctx, api); err != nil {
+			t.Fatalf("UpdateApi: %v", err)
+		}
+		updated, err := repo.GetApiByUuid(ctx, "uuid-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Id != api.Id || updated.ResponseBody != "changed" || updated.StatusCode != 418 {
+			t.Fatalf("update was not persisted: %+v", updated)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		mustCreate(t, repo, newPayload("u1", "GET", "/a"), "uuid-1")
+		mustCreate(t, repo, newPayload("u1", "GET", "/b"), "uuid-2")
+		if err := repo.DeleteApiByUuid(ctx, "uuid-1"); err != nil {
+			t.Fatalf("DeleteApiByUuid: %v", err)
+		}
+		if _, err := repo.GetApiByUuid(ctx, "uuid-1"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("deleted api is still readable: %v", err)
+		}
+		if err := repo.DeleteApiByUuid(ctx, "uuid-1"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("deleting twice must return ErrRecordNotFound, got %v", err)
+		}
+		if _, err := repo.GetApiByUuid(ctx, "uuid-2"); err != nil {
+			t.Fatalf("other apis must be kept: %v", err)
+		}
+	})
+
+	t.Run("ReturnedCopies", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		mustCreate(t, repo, newPayload("u1", "GET", "/a"), "uuid-1")
+		api, err := repo.GetApiByUuid(ctx, "uuid-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		api.ResponseBody = "mutated"
+		stored, err := repo.GetApiByUuid(ctx, "uuid-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.ResponseBody != "ok" {
+			t.Fatalf("modifying a returned api must not change the stored one")
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		mustCreate(t, repo, newPayload("u1", "GET", "/users", "team-a"), "uuid-1")
+		mustCreate(t, repo, newPayload("u1", "POST", "/users", "team-b"), "uuid-2")
+		mustCreate(t, repo, newPayload("u1", "GET", "/user_x/1", "team-a", "team-b"), "uuid-3")
+		mustCreate(t, repo, newPayload("u1", "GET", "/userxx/1"), "uuid-4")
+		mustCreate(t, repo, newPayload("u2", "GET", "/users", "team-a"), "uuid-5")
+
+		cases := []struct {
+			name   string
+			filter repositories.ApiFilter
+			want   []string
+			total  int64
+		}{
+			{"all", repositories.ApiFilter{}, []string{"uuid-1", "uuid-2", "uuid-3", "uuid-4", "uuid-5"}, 5},
+			{"user", repositories.ApiFilter{UserId: "u1"}, []string{"uuid-1", "uuid-2", "uuid-3", "uuid-4"}, 4},
+			{"method", repositories.ApiFilter{UserId: "u1", Method: "POST"}, []string{"uuid-2"}, 1},
+			{"prefix", repositories.ApiFilter{PathPrefix: "/users"}, []string{"uuid-1", "uuid-2", "uuid-5"}, 3},
+			// 前缀中的 _ 与 % 按字面量匹配
+			{"prefix literal", repositories.ApiFilter{PathPrefix: "/user_"}, []string{"uuid-3"}, 1},
+			{"tag", repositories.ApiFilter{UserId: "u1", Tag: "team-a"}, []string{"uuid-1", "uuid-3"}, 2},
+			{"tag exact", repositories.ApiFilter{Tag: "team"}, []string{}, 0},
+			{"page", repositories.ApiFilter{Offset: 1, Limit: 2}, []string{"uuid-2", "uuid-3"}, 5},
+			{"page beyond", repositories.ApiFilter{Offset: 10, Limit: 2}, []string{}, 5},
+		}
+		for _, c := range cases {
+			apis, total, err := repo.ListApis(ctx, c.filter)
+			if err != nil {
+				t.Fatalf("%s: ListApis: %v", c.name, err)
+			}
+			if got := uuids(apis); total != c.total || !reflect.DeepEqual(got, c.want) {
+				t.Errorf("%s: got %v (total %d), want %v (total %d)", c.name, got, total, c.want, c.total)
+			}
+		}
+	})
+
+	t.Run("FingerprintChanges", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		// 只要求与上一次的摘要不同，删除最新的记录后可能回到更早的摘要
+		var previous, previousStep string
+		record := func(step string) {
+			t.Helper()
+			fingerprint, err := repo.Fingerprint(ctx)
+			if err != nil {
+				t.Fatalf("Fingerprint: %v", err)
+			}
+			if previousStep != "" && fingerprint == previous {
+				t.Fatalf("fingerprint after %s equals the one after %s", step, previousStep)
+			}
+			previous, previousStep = fingerprint, step
+		}
+		record("empty")
+		mustCreate(t, repo, newPayload("u1", "GET", "/a"), "uuid-1")
+		record("create")
+		api, err := repo.GetApiByUuid(ctx, "uuid-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		api.ResponseBody = "changed"
+		if err := repo.UpdateApi(ctx, api); err != nil {
+			t.Fatal(err)
+		}
+		record("update")
+		mustCreate(t, repo, newPayload("u1", "GET", "/b"), "uuid-2")
+		record("second create")
+		if err := repo.DeleteApiByUuid(ctx, "uuid-2"); err != nil {
+			t.Fatal(err)
+		}
+		record("delete")
+	})
+
+	t.Run("QueryWithUidAndMethod", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		mustCreate(t, repo, newPayload("u1", "GET", "/b"), "uuid-1")
+		mustCreate(t, repo, newPayload("u1", "POST", "/a"), "uuid-2")
+		mustCreate(t, repo, newPayload("u1", "get", "/a"), "uuid-3")
+		mustCreate(t, repo, newPayload("u2", "GET", "/a"), "uuid-4")
+		apis, err := repo.QueryApisWithUidAndMethod(ctx, "u1", "GET")
+		if err != nil {
+			t.Fatalf("QueryApisWithUidAndMethod: %v", err)
+		}
+		if got, want := uuids(apis), []string{"uuid-1", "uuid-3"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v in id order", got, want)
+		}
+	})
+
+	t.Run("WorkspaceScope", func(t *testing.T) {
+		repo := factory(t)
+		w1 := tenant.WithWorkspace(context.Background(), "w1")
+		w2 := tenant.WithWorkspace(context.Background(), "w2")
+		if err := repo.CreateApi(w1, newPayload("u1", "GET", "/a"), "a"); err != nil {
+			t.Fatalf("CreateApi: %v", err)
+		}
+		if err := repo.CreateApi(w2, newPayload("u2", "GET", "/b"), "b"); err != nil {
+			t.Fatalf("CreateApi: %v", err)
+		}
+		mustCreate(t, repo, newPayload("u3", "GET", "/c"), "c")
+
+		api, err := repo.GetApiByUuid(w1, "a")
+		if err != nil || api.WorkspaceId != "w1" {
+			t.Fatalf("GetApiByUuid = %+v, %v, want workspace w1", api, err)
+		}
+		if _, err := repo.GetApiByUuid(w1, "b"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("another workspace's api must not be visible, got %v", err)
+		}
+		if _, err := repo.GetApiByUuid(w1, "c"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("an api without workspace must not be visible to a workspace, got %v", err)
+		}
+		apis, total, err := repo.ListApis(w1, repositories.ApiFilter{})
+		if err != nil || total != 1 || !reflect.DeepEqual(uuids(apis), []string{"a"}) {
+			t.Fatalf("ListApis = %v (total %d), %v, want [a]", uuids(apis), total, err)
+		}
+		apis, total, err = repo.ListApis(context.Background(), repositories.ApiFilter{})
+		if err != nil || total != 3 {
+			t.Fatalf("unscoped ListApis = %v (total %d), %v, want all apis", uuids(apis), total, err)
+		}
+		if apis, err := repo.QueryApisWithUidAndMethod(w1, "u2", "GET"); err != nil || len(apis) != 0 {
+			t.Fatalf("QueryApisWithUidAndMethod = %v, %v, want none", uuids(apis), err)
+		}
+
+		other, err := repo.GetApiByUuid(w2, "b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		other.ResponseBody = "changed"
+		if err := repo.UpdateApi(w1, other); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("updating another workspace's api must fail with ErrRecordNotFound, got %v", err)
+		}
+		if err := repo.DeleteApiByUuid(w1, "b"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("deleting another workspace's api must fail with ErrRecordNotFound, got %v", err)
+		}
+		if stored, err := repo.GetApiByUuid(w2, "b"); err != nil || stored.ResponseBody != "ok" {
+			t.Fatalf("another workspace's api was modified: %+v, %v", stored, err)
+		}
+
+		// 不限定工作区时按记录中的 WorkspaceId 写入
+		legacy, err := repo.GetApiByUuid(context.Background(), "c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		legacy.WorkspaceId = "w1"
+		if err := repo.UpdateApi(context.Background(), legacy); err != nil {
+			t.Fatalf("UpdateApi: %v", err)
+		}
+		if _, err := repo.GetApiByUuid(w1, "c"); err != nil {
+			t.Fatalf("assigned api must be visible to its workspace, got %v", err)
+		}
+		if err := repo.DeleteApiByUuid(w2, "b"); err != nil {
+			t.Fatalf("DeleteApiByUuid: %v", err)
+		}
+	})
+}
+
+// RunNamespaceRepository 验证 NamespaceRepository 的读取与保存
+func RunNamespaceRepository(t *testing.T, factory func(t *testing.T) repositories.NamespaceRepository) {
+	t.Run("GetMissing", func(t *testing.T) {
+		repo := factory(t)
+		if _, err := repo.GetNamespaceByUid(context.Background(), "missing"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("expected ErrRecordNotFound, got %v", err)
+		}
+	})
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		namespace := &models.Namespace{
+			Uid:             "u1",
+			NotFoundStatus:  501,
+			Delay:           json.RawMessage(`{"distribution":"fixed","fixed_ms":5}`),
+			Mode:            "mock",
+			RewriteRules:    json.RawMessage(`[]`),
+			OpenApiSpec:     "openapi: 3.0.0",
+			OpenApiSpecHash: strings.Repeat("a", 64),
+		}
+		if err := repo.SaveNamespace(ctx, namespace); err != nil {
+			t.Fatalf("SaveNamespace: %v", err)
+		}
+		if namespace.Id == 0 {
+			t.Fatalf("id must be assigned on the first save")
+		}
+		stored, err := repo.GetNamespaceByUid(ctx, "u1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Id != namespace.Id || stored.NotFoundStatus != 501 || stored.Mode != "mock" || stored.OpenApiSpec != "openapi: 3.0.0" ||
+			stored.OpenApiSpecHash != namespace.OpenApiSpecHash {
+			t.Fatalf("unexpected namespace %+v", stored)
+		}
+		if !jsonEqual(stored.Delay, namespace.Delay) {
+			t.Fatalf("delay = %s, want %s", stored.Delay, namespace.Delay)
+		}
+
+		stored.NotFoundStatus = 404
+		stored.OpenApiSpec = ""
+		stored.OpenApiSpecHash = ""
+		if err := repo.SaveNamespace(ctx, stored); err != nil {
+			t.Fatalf("SaveNamespace: %v", err)
+		}
+		updated, err := repo.GetNamespaceByUid(ctx, "u1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Id != namespace.Id || updated.NotFoundStatus != 404 || updated.OpenApiSpec != "" || updated.OpenApiSpecHash != "" {
+			t.Fatalf("update was not persisted: %+v", updated)
+		}
+	})
+
+	t.Run("FingerprintChanges", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		var previous, previousStep string
+		record := func(step string) {
+			t.Helper()
+			fingerprint, err := repo.Fingerprint(ctx)
+			if err != nil {
+				t.Fatalf("Fingerprint: %v", err)
+			}
+			if previousStep != "" && fingerprint == previous {
+				t.Fatalf("fingerprint after %s equals the one after %s", step, previousStep)
+			}
+			previous, previousStep = fingerprint, step
+		}
+		record("empty")
+		namespace := &models.Namespace{Uid: "u1", NotFoundStatus: 404, Mode: "mock"}
+		if err := repo.SaveNamespace(ctx, namespace); err != nil {
+			t.Fatal(err)
+		}
+		record("create")
+		namespace.NotFoundStatus = 501
+		if err := repo.SaveNamespace(ctx, namespace); err != nil {
+			t.Fatal(err)
+		}
+		record("update")
+		namespace.NotFoundStatus = 502
+		if err := repo.SaveNamespace(ctx, namespace); err != nil {
+			t.Fatal(err)
+		}
+		record("second update")
+	})
+
+	t.Run("WorkspaceScope", func(t *testing.T) {
+		repo := factory(t)
+		w1 := tenant.WithWorkspace(context.Background(), "w1")
+		w2 := tenant.WithWorkspace(context.Background(), "w2")
+		namespace := &models.Namespace{Uid: "u1", NotFoundStatus: 404, Mode: "mock"}
+		if err := repo.SaveNamespace(w1, namespace); err != nil {
+			t.Fatalf("SaveNamespace: %v", err)
+		}
+		if namespace.WorkspaceId != "w1" {
+			t.Fatalf("workspace = %q, want w1", namespace.WorkspaceId)
+		}
+		if _, err := repo.GetNamespaceByUid(w2, "u1"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("another workspace's namespace must not be visible, got %v", err)
+		}
+		stored, err := repo.GetNamespaceByUid(context.Background(), "u1")
+		if err != nil || stored.WorkspaceId != "w1" {
+			t.Fatalf("unscoped GetNamespaceByUid = %+v, %v", stored, err)
+		}
+		stored.NotFoundStatus = 500
+		if err := repo.SaveNamespace(w2, stored); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("saving another workspace's namespace must fail with ErrRecordNotFound, got %v", err)
+		}
+		if stored, err := repo.GetNamespaceByUid(w1, "u1"); err != nil || stored.NotFoundStatus != 404 {
+			t.Fatalf("another workspace's namespace was modified: %+v, %v", stored, err)
+		}
+	})
+}
+
+// RunWorkspaceRepository 验证 WorkspaceRepository 的工作区、成员与 API Key
+func RunWorkspaceRepository(t *testing.T, factory func(t *testing.T) repositories.WorkspaceRepository) {
+	t.Run("Workspaces", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		if _, err := repo.GetWorkspaceByUuid(ctx, "missing"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("expected ErrRecordNotFound, got %v", err)
+		}
+		if count, err := repo.CountWorkspaces(ctx); err != nil || count != 0 {
+			t.Fatalf("CountWorkspaces = %d, %v, want 0", count, err)
+		}
+		workspace := &models.Workspace{Uuid: "w1", Name: "team"}
+		if err := repo.CreateWorkspace(ctx, workspace); err != nil {
+			t.Fatalf("CreateWorkspace: %v", err)
+		}
+		if count, err := repo.CountWorkspaces(ctx); err != nil || count != 1 {
+			t.Fatalf("CountWorkspaces = %d, %v, want 1", count, err)
+		}
+		if workspace.Id == 0 || workspace.CreatedAt.IsZero() {
+			t.Fatalf("id and timestamps must be assigned, got %+v", workspace)
+		}
+		stored, err := repo.GetWorkspaceByUuid(ctx, "w1")
+		if err != nil || stored.Id != workspace.Id || stored.Name != "team" {
+			t.Fatalf("GetWorkspaceByUuid = %+v, %v", stored, err)
+		}
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		for _, uuid := range []string{"w1", "w2"} {
+			if err := repo.CreateWorkspace(ctx, &models.Workspace{Uuid: uuid, Name: uuid}); err != nil {
+				t.Fatalf("CreateWorkspace: %v", err)
+			}
+		}
+		for _, member := range []*models.WorkspaceMember{{WorkspaceId: "w1", UserId: "alice"}, {WorkspaceId: "w1", UserId: "bob"}, {WorkspaceId: "w2", UserId: "alice"}} {
+			if err := repo.AddMember(ctx, member); err != nil {
+				t.Fatalf("AddMember: %v", err)
+			}
+		}
+		members, err := repo.ListMembers(ctx, "w1")
+		if err != nil || len(members) != 2 || members[0].UserId != "alice" || members[1].UserId != "bob" {
+			t.Fatalf("ListMembers = %+v, %v", members, err)
+		}
+		if err := repo.RemoveMember(ctx, "w1", "alice"); err != nil {
+			t.Fatalf("RemoveMember: %v", err)
+		}
+		if err := repo.RemoveMember(ctx, "w1", "alice"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("expected ErrRecordNotFound, got %v", err)
+		}
+		if members, _ := repo.ListMembers(ctx, "w2"); len(members) != 1 {
+			t.Fatalf("removing a member must not affect other workspaces, got %+v", members)
+		}
+	})
+
+	t.Run("ApiKeys", func(t *testing.T) {
+		repo := factory(t)
+		ctx := context.Background()
+		for _, uuid := range []string{"w1", "w2"} {
+			if err := repo.CreateWorkspace(ctx, &models.Workspace{Uuid: uuid, Name: uuid}); err != nil {
+				t.Fatalf("CreateWorkspace: %v", err)
+			}
+		}
+		for i, workspace := range []string{"w1", "w1", "w2"} {
+			key := &models.ApiKey{Uuid: fmt.Sprintf("k%d", i), WorkspaceId: workspace, Name: "key", Prefix: "kite_", Hash: fmt.Sprintf("hash-%d", i)}
+			if err := repo.CreateApiKey(ctx, key); err != nil {
+				t.Fatalf("CreateApiKey: %v", err)
+			}
+		}
+		keys, err := repo.ListApiKeys(ctx, "w1")
+		if err != nil || len(keys) != 2 {
+			t.Fatalf("ListApiKeys = %+v, %v", keys, err)
+		}
+		key, err := repo.GetApiKeyByHash(ctx, "hash-2")
+		if err != nil || key.Uuid != "k2" || key.WorkspaceId != "w2" {
+			t.Fatalf("GetApiKeyByHash = %+v, %v", key, err)
+		}
+		if err := repo.DeleteApiKey(ctx, "w1", "k2"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("deleting another workspace's key must fail with ErrRecordNotFound, got %v", err)
+		}
+		if err := repo.DeleteApiKey(ctx, "w2", "k2"); err != nil {
+			t.Fatalf("DeleteApiKey: %v", err)
+		}
+		if _, err := repo.GetApiKeyByHash(ctx, "hash-2"); !errors.Is(err, repositories.ErrRecordNotFound) {
+			t.Fatalf("deleted key must not authenticate, got %v", err)
+		}
+	})
+}
+
+func newPayload(uid string, method string, path string, tags ...string) payloads.MockApiPayload {
+	return payloads.MockApiPayload{
+		UserId:          uid,
+		Path:            path,
+		Method:          method,
+		StatusCode:      200,
+		ContentType:     "text/plain",
+		Charset:         "utf-8",
+		ResponseHeaders: []payloads.Headers{},
+		ResponseBody:    "ok",
+		Tags:            tags,
+	}
+}
+
+func mustCreate(t *testing.T, repo repositories.ApiRepository, payload payloads.MockApiPayload, uuid string) {
+	t.Helper()
+	if err := repo.CreateApi(context.Background(), payload, uuid); err != nil {
+		t.Fatalf("CreateApi: %v", err)
+	}
+}
+
+func uuids(apis []*models.Api) []string {
+	result := make([]string, 0, len(apis))
+	for _, api := range apis {
+		result = append(result, api.Uuid)
+	}
+	return result
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var left, right interface{}
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
@@ -0,0 +1,68 @@
+package repositories_test
+
+import (
+	"context"
+	"kite/internal/configs"
+	"kite/internal/database"
+	"kite/internal/database/migrations"
+	"kite/internal/repositories"
+	"kite/internal/repositories/conformance"
+	"testing"
+)
+
+// runConformance 对一种驱动运行全部存储行为测试，open 每次返回一个空的存储
+func runConformance(t *testing.T, open func(t *testing.T) (*configs.DatabaseConfig, *database.Connection)) {
+	t.Run("ApiRepository", func(t *testing.T) {
+		conformance.RunApiRepository(t, func(t *testing.T) repositories.ApiRepository {
+			cfg, connection := open(t)
+			repo, err := repositories.NewApiRepository(cfg, connection)
+			if err != nil {
+				t.Fatalf("NewApiRepository: %v", err)
+			}
+			return repo
+		})
+	})
+	t.Run("NamespaceRepository", func(t *testing.T) {
+		conformance.RunNamespaceRepository(t, func(t *testing.T) repositories.NamespaceRepository {
+			cfg, connection := open(t)
+			repo, err := repositories.NewNamespaceRepository(cfg, connection)
+			if err != nil {
+				t.Fatalf("NewNamespaceRepository: %v", err)
+			}
+			return repo
+		})
+	})
+	t.Run("WorkspaceRepository", func(t *testing.T) {
+		conformance.RunWorkspaceRepository(t, func(t *testing.T) repositories.WorkspaceRepository {
+			cfg, connection := open(t)
+			repo, err := repositories.NewWorkspaceRepository(cfg, connection)
+			if err != nil {
+				t.Fatalf("NewWorkspaceRepository: %v", err)
+			}
+			return repo
+		})
+	})
+}
+
+// connect 建立连接并在测试结束时关闭
+func connect(t *testing.T, cfg *configs.DatabaseConfig) *database.Connection {
+	t.Helper()
+	connection, err := database.NewConnection(cfg)
+	if err != nil {
+		t.Fatalf("NewConnection: %v", err)
+	}
+	t.Cleanup(func() { _ = connection.CloseDB() })
+	return connection
+}
+
+// migrate 执行全部迁移
+func migrate(t *testing.T, connection *database.Connection) {
+	t.Helper()
+	migrator, err := migrations.New(connection.GetDB())
+	if err != nil {
+		t.Fatalf("migrations.New: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+}
@@ -0,0 +1,604 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"kite/internal/api/payloads"
+	"kite/internal/bundle"
+	KiteError "kite/internal/errors"
+	"kite/internal/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// file 驱动写入文件的格式
+const (
+	FileFormatYAML = "yaml"
+	FileFormatJSON = "json"
+)
+
+// 数据目录下 mock 与命名空间配置各自的子目录
+const (
+	fileApisDir       = "apis"
+	fileNamespacesDir = "namespaces"
+	fileWorkspacesDir = "workspaces"
+)
+
+// apiFile 单个 mock 文件的内容，字段与导出的 bundle 一致，便于手工编辑和提交到代码仓库。
+// 手工编写的文件可以省略 id、uuid 和时间，uuid 缺省时使用文件名
+type apiFile struct {
+	Id          uint64    `json:"id,omitempty"`
+	WorkspaceId string    `json:"workspace_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	bundle.Mock
+}
+
+// namespaceFile 单个命名空间配置文件的内容
+type namespaceFile struct {
+	Id              uint64          `json:"id,omitempty"`
+	WorkspaceId     string          `json:"workspace_id,omitempty"`
+	Uid             string          `json:"uid"`
+	NotFoundStatus  int             `json:"not_found_status"`
+	Delay           json.RawMessage `json:"delay,omitempty"`
+	ThrottleBps     int             `json:"throttle_bps"`
+	Mode            string          `json:"mode"`
+	UpstreamUrl     string          `json:"upstream_url,omitempty"`
+	RecordConfig    json.RawMessage `json:"record_config,omitempty"`
+	FallbackUrl     string          `json:"fallback_url,omitempty"`
+	RewriteRules    json.RawMessage `json:"rewrite_rules,omitempty"`
+	OpenApiSpec     string          `json:"openapi_spec,omitempty"`
+	OpenApiSpecHash string          `json:"openapi_spec_hash,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// workspaceFile 单个工作区文件的内容，成员与 API Key 保存在工作区文件中
+type workspaceFile struct {
+	Id        uint64                `json:"id,omitempty"`
+	Uuid      string                `json:"uuid"`
+	Name      string                `json:"name"`
+	Members   []workspaceMemberFile `json:"members"`
+	ApiKeys   []apiKeyFile          `json:"api_keys"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+type workspaceMemberFile struct {
+	Id        uint64    `json:"id"`
+	UserId    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// apiKeyFile 只保存 API Key 的摘要
+type apiKeyFile struct {
+	Id        uint64    `json:"id"`
+	Uuid      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// fileStore 一个目录下每条记录对应一个文件，启动时全部读入内存，修改时同步写回对应的文件
+type fileStore struct {
+	dir    string
+	format string
+	// files 记录 key 与文件名的对应关系，手工编写的文件名可能与 key 不同
+	files map[string]string
+}
+
+func newFileStore(root string, sub string, format string) (*fileStore, error) {
+	if format == "" {
+		format = FileFormatYAML
+	}
+	if format != FileFormatYAML && format != FileFormatJSON {
+		return nil, fmt.Errorf("unsupported file format %q, expected yaml or json", format)
+	}
+	dir := filepath.Join(root, sub)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	return &fileStore{dir: dir, format: format, files: make(map[string]string)}, nil
+}
+
+// load 按文件名顺序读取目录下的全部 JSON、YAML 文件，decode 返回记录的 key
+func (s *fileStore) load(decode func(name string, data []byte) (string, error)) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		key, err := decode(strings.TrimSuffix(entry.Name(), ext), data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Join(s.dir, entry.Name()), err)
+		}
+		if previous, ok := s.files[key]; ok {
+			return fmt.Errorf("%s: duplicated with %s", filepath.Join(s.dir, entry.Name()), previous)
+		}
+		s.files[key] = entry.Name()
+	}
+	return nil
+}
+
+// write 先写入临时文件再重命名，避免进程中断时留下不完整的文件
+func (s *fileStore) write(key string, record interface{}) error {
+	data, err := encodeDocument(record, s.format)
+	if err != nil {
+		return err
+	}
+	name, ok := s.files[key]
+	if !ok {
+		name = key + "." + s.format
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	s.files[key] = name
+	return nil
+}
+
+func (s *fileStore) remove(key string) error {
+	name, ok := s.files[key]
+	if !ok {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	delete(s.files, key)
+	return nil
+}
+
+// encodeDocument 编码为 JSON 或 YAML，YAML 的字段名与 JSON 保持一致
+func encodeDocument(v interface{}, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if format == FileFormatJSON {
+		return append(data, '\n'), nil
+	}
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(root)
+}
+
+// decodeDocument 解析 JSON 或 YAML，统一转换为 JSON 后按 json 标签解析
+func decodeDocument(data []byte, v interface{}) error {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return json.Unmarshal(trimmed, v)
+	}
+	var root interface{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	normalized, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(normalized, v)
+}
+
+// fileApiRepository 将 mock 存储为数据目录下 apis 子目录中的文件，查询由内存存储完成。
+// 文件只在启动时读取，运行期间在目录外修改的文件需要重启后生效
+type fileApiRepository struct {
+	mu     sync.Mutex
+	memory *memoryApiRepository
+	store  *fileStore
+}
+
+func NewFileApiRepository(root string, format string) (ApiRepository, error) {
+	store, err := newFileStore(root, fileApisDir, format)
+	if err != nil {
+		return nil, err
+	}
+	r := &fileApiRepository{memory: &memoryApiRepository{}, store: store}
+	var apis []*models.Api
+	err = store.load(func(name string, data []byte) (string, error) {
+		var record apiFile
+		if err := decodeDocument(data, &record); err != nil {
+			return "", err
+		}
+		if record.Uuid == "" {
+			record.Uuid = name
+		}
+		api := &models.Api{Id: record.Id, WorkspaceId: record.WorkspaceId, Uuid: record.Uuid, CreatedAt: record.CreatedAt, UpdatedAt: record.UpdatedAt}
+		if err := record.ApplyTo(api); err != nil {
+			return "", err
+		}
+		apis = append(apis, api)
+		return api.Uuid, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 保持 id 的顺序，未指定 id 的记录按文件名顺序排在最后
+	slices.SortStableFunc(apis, func(a, b *models.Api) int {
+		switch {
+		case a.Id == b.Id:
+			return 0
+		case a.Id == 0:
+			return 1
+		case b.Id == 0:
+			return -1
+		case a.Id < b.Id:
+			return -1
+		default:
+			return 1
+		}
+	})
+	for _, api := range apis {
+		if api.Id == 0 {
+			api.Id = r.memory.nextId + 1
+		}
+		if api.CreatedAt.IsZero() {
+			api.CreatedAt = time.Now()
+		}
+		if api.UpdatedAt.IsZero() {
+			api.UpdatedAt = api.CreatedAt
+		}
+		r.memory.restore(api)
+	}
+	return r, nil
+}
+
+func (r *fileApiRepository) CreateApi(ctx context.Context, payload payloads.MockApiPayload, uuid string) error {
+	api := &models.Api{Uuid: uuid}
+	if err := payload.ApplyTo(api); err != nil {
+		return KiteError.New(KiteError.MarshalError, err)
+	}
+	return r.InsertApi(ctx, api)
+}
+
+func (r *fileApiRepository) InsertApi(ctx context.Context, api *models.Api) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.memory.InsertApi(ctx, api); err != nil {
+		return err
+	}
+	if err := r.save(api); err != nil {
+		_ = r.memory.DeleteApiByUuid(ctx, api.Uuid)
+		return err
+	}
+	return nil
+}
+
+// InsertApis 任何一个文件写入失败时删除已经写入的文件和记录
+func (r *fileApiRepository) InsertApis(ctx context.Context, apis []*models.Api) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, api := range apis {
+		if err := r.memory.InsertApi(ctx, api); err != nil {
+			r.rollback(ctx, apis[:i])
+			return err
+		}
+		if err := r.save(api); err != nil {
+			r.rollback(ctx, apis[:i+1])
+			return err
+		}
+	}
+	return nil
+}
+
+// rollback 删除 InsertApis 已经写入的记录和文件
+func (r *fileApiRepository) rollback(ctx context.Context, apis []*models.Api) {
+	for _, api := range apis {
+		_ = r.memory.DeleteApiByUuid(ctx, api.Uuid)
+		_ = r.store.remove(api.Uuid)
+	}
+}
+
+func (r *fileApiRepository) UpdateApi(ctx context.Context, api *models.Api) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous, err := r.memory.GetApiByUuid(ctx, api.Uuid)
+	if err != nil {
+		return err
+	}
+	if err := r.memory.UpdateApi(ctx, api); err != nil {
+		return err
+	}
+	if err := r.save(api); err != nil {
+		_ = r.memory.UpdateApi(ctx, previous)
+		return err
+	}
+	return nil
+}
+
+func (r *fileApiRepository) DeleteApiByUuid(ctx context.Context, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.memory.DeleteApiByUuid(ctx, uuid); err != nil {
+		return err
+	}
+	return r.store.remove(uuid)
+}
+
+func (r *fileApiRepository) GetApiByUuid(ctx context.Context, uuid string) (*models.Api, error) {
+	return r.memory.GetApiByUuid(ctx, uuid)
+}
+
+func (r *fileApiRepository) ListApis(ctx context.Context, filter ApiFilter) ([]*models.Api, int64, error) {
+	return r.memory.ListApis(ctx, filter)
+}
+
+func (r *fileApiRepository) QueryApisWithUidAndMethod(ctx context.Context, uid string, method string) ([]*models.Api, error) {
+	return r.memory.QueryApisWithUidAndMethod(ctx, uid, method)
+}
+
+func (r *fileApiRepository) Fingerprint(ctx context.Context) (string, error) {
+	return r.memory.Fingerprint(ctx)
+}
+
+func (r *fileApiRepository) save(api *models.Api) error {
+	mock, err := bundle.FromApi(api)
+	if err != nil {
+		return KiteError.New(KiteError.MarshalError, err)
+	}
+	return r.store.write(api.Uuid, apiFile{Id: api.Id, WorkspaceId: api.WorkspaceId, CreatedAt: api.CreatedAt, UpdatedAt: api.UpdatedAt, Mock: mock})
+}
+
+// fileNamespaceRepository 将命名空间配置存储为数据目录下 namespaces 子目录中的文件
+type fileNamespaceRepository struct {
+	mu     sync.Mutex
+	memory *memoryNamespaceRepository
+	store  *fileStore
+}
+
+func NewFileNamespaceRepository(root string, format string) (NamespaceRepository, error) {
+	store, err := newFileStore(root, fileNamespacesDir, format)
+	if err != nil {
+		return nil, err
+	}
+	r := &fileNamespaceRepository{memory: &memoryNamespaceRepository{namespaces: make(map[string]*models.Namespace)}, store: store}
+	var pending []*models.Namespace
+	err = store.load(func(name string, data []byte) (string, error) {
+		var record namespaceFile
+		if err := decodeDocument(data, &record); err != nil {
+			return "", err
+		}
+		if record.Uid == "" {
+			record.Uid = name
+		}
+		namespace := &models.Namespace{
+			Id:              record.Id,
+			WorkspaceId:     record.WorkspaceId,
+			Uid:             record.Uid,
+			NotFoundStatus:  record.NotFoundStatus,
+			Delay:           record.Delay,
+			ThrottleBps:     record.ThrottleBps,
+			Mode:            record.Mode,
+			UpstreamUrl:     record.UpstreamUrl,
+			RecordConfig:    record.RecordConfig,
+			FallbackUrl:     record.FallbackUrl,
+			RewriteRules:    record.RewriteRules,
+			OpenApiSpec:     record.OpenApiSpec,
+			OpenApiSpecHash: record.OpenApiSpecHash,
+			CreatedAt:       record.CreatedAt,
+			UpdatedAt:       record.UpdatedAt,
+		}
+		if namespace.Id == 0 {
+			pending = append(pending, namespace)
+		} else {
+			r.memory.restore(namespace)
+		}
+		return namespace.Uid, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, namespace := range pending {
+		namespace.Id = r.memory.nextId + 1
+		r.memory.restore(namespace)
+	}
+	return r, nil
+}
+
+func (r *fileNamespaceRepository) GetNamespaceByUid(ctx context.Context, uid string) (*models.Namespace, error) {
+	return r.memory.GetNamespaceByUid(ctx, uid)
+}
+
+func (r *fileNamespaceRepository) Fingerprint(ctx context.Context) (string, error) {
+	return r.memory.Fingerprint(ctx)
+}
+
+func (r *fileNamespaceRepository) SaveNamespace(ctx context.Context, namespace *models.Namespace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.memory.SaveNamespace(ctx, namespace); err != nil {
+		return err
+	}
+	return r.store.write(namespace.Uid, namespaceFile{
+		Id:              namespace.Id,
+		WorkspaceId:     namespace.WorkspaceId,
+		Uid:             namespace.Uid,
+		NotFoundStatus:  namespace.NotFoundStatus,
+		Delay:           namespace.Delay,
+		ThrottleBps:     namespace.ThrottleBps,
+		Mode:            namespace.Mode,
+		UpstreamUrl:     namespace.UpstreamUrl,
+		RecordConfig:    namespace.RecordConfig,
+		FallbackUrl:     namespace.FallbackUrl,
+		RewriteRules:    namespace.RewriteRules,
+		OpenApiSpec:     namespace.OpenApiSpec,
+		OpenApiSpecHash: namespace.OpenApiSpecHash,
+		CreatedAt:       namespace.CreatedAt,
+		UpdatedAt:       namespace.UpdatedAt,
+	})
+}
+
+// fileWorkspaceRepository 将工作区存储为数据目录下 workspaces 子目录中的文件，每个工作区一个文件
+type fileWorkspaceRepository struct {
+	mu     sync.Mutex
+	memory *memoryWorkspaceRepository
+	store  *fileStore
+}
+
+func NewFileWorkspaceRepository(root string, format string) (WorkspaceRepository, error) {
+	store, err := newFileStore(root, fileWorkspacesDir, format)
+	if err != nil {
+		return nil, err
+	}
+	r := &fileWorkspaceRepository{memory: &memoryWorkspaceRepository{}, store: store}
+	err = store.load(func(name string, data []byte) (string, error) {
+		var record workspaceFile
+		if err := decodeDocument(data, &record); err != nil {
+			return "", err
+		}
+		if record.Uuid == "" {
+			record.Uuid = name
+		}
+		workspace := &models.Workspace{Id: record.Id, Uuid: record.Uuid, Name: record.Name, CreatedAt: record.CreatedAt, UpdatedAt: record.UpdatedAt}
+		members := make([]*models.WorkspaceMember, 0, len(record.Members))
+		for _, member := range record.Members {
+			members = append(members, &models.WorkspaceMember{Id: member.Id, WorkspaceId: record.Uuid, UserId: member.UserId, CreatedAt: member.CreatedAt})
+		}
+		keys := make([]*models.ApiKey, 0, len(record.ApiKeys))
+		for _, key := range record.ApiKeys {
+			keys = append(keys, &models.ApiKey{Id: key.Id, Uuid: key.Uuid, WorkspaceId: record.Uuid, Name: key.Name, Prefix: key.Prefix, Hash: key.Hash, CreatedAt: key.CreatedAt})
+		}
+		r.memory.restore(workspace, members, keys)
+		return workspace.Uuid, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *fileWorkspaceRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.memory.CreateWorkspace(ctx, workspace); err != nil {
+		return err
+	}
+	if err := r.save(ctx, workspace.Uuid); err != nil {
+		r.memory.deleteWorkspace(workspace.Uuid)
+		return err
+	}
+	return nil
+}
+
+func (r *fileWorkspaceRepository) GetWorkspaceByUuid(ctx context.Context, uuid string) (*models.Workspace, error) {
+	return r.memory.GetWorkspaceByUuid(ctx, uuid)
+}
+
+func (r *fileWorkspaceRepository) CountWorkspaces(ctx context.Context) (int64, error) {
+	return r.memory.CountWorkspaces(ctx)
+}
+
+func (r *fileWorkspaceRepository) ListMembers(ctx context.Context, workspaceId string) ([]*models.WorkspaceMember, error) {
+	return r.memory.ListMembers(ctx, workspaceId)
+}
+
+func (r *fileWorkspaceRepository) AddMember(ctx context.Context, member *models.WorkspaceMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.memory.AddMember(ctx, member); err != nil {
+		return err
+	}
+	if err := r.save(ctx, member.WorkspaceId); err != nil {
+		_ = r.memory.RemoveMember(ctx, member.WorkspaceId, member.UserId)
+		return err
+	}
+	return nil
+}
+
+func (r *fileWorkspaceRepository) RemoveMember(ctx context.Context, workspaceId string, userId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.memory.RemoveMember(ctx, workspaceId, userId); err != nil {
+		return err
+	}
+	return r.save(ctx, workspaceId)
+}
+
+func (r *fileWorkspaceRepository) CreateApiKey(ctx context.Context, key *models.ApiKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.memory.CreateApiKey(ctx, key); err != nil {
+		return err
+	}
+	if err := r.save(ctx, key.WorkspaceId); err != nil {
+		_ = r.memory.DeleteApiKey(ctx, key.WorkspaceId, key.Uuid)
+		return err
+	}
+	return nil
+}
+
+func (r *fileWorkspaceRepository) ListApiKeys(ctx context.Context, workspaceId string) ([]*models.ApiKey, error) {
+	return r.memory.ListApiKeys(ctx, workspaceId)
+}
+
+func (r *fileWorkspaceRepository) DeleteApiKey(ctx context.Context, workspaceId string, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.memory.DeleteApiKey(ctx, workspaceId, uuid); err != nil {
+		return err
+	}
+	return r.save(ctx, workspaceId)
+}
+
+func (r *fileWorkspaceRepository) GetApiKeyByHash(ctx context.Context, hash string) (*models.ApiKey, error) {
+	return r.memory.GetApiKeyByHash(ctx, hash)
+}
+
+// save 将工作区及其成员和 API Key 整体写回文件
+func (r *fileWorkspaceRepository) save(ctx context.Context, workspaceId string) error {
+	workspace, err := r.memory.GetWorkspaceByUuid(ctx, workspaceId)
+	if err != nil {
+		return err
+	}
+	members, _ := r.memory.ListMembers(ctx, workspaceId)
+	keys, _ := r.memory.ListApiKeys(ctx, workspaceId)
+	record := workspaceFile{
+		Id:        workspace.Id,
+		Uuid:      workspace.Uuid,
+		Name:      workspace.Name,
+		Members:   make([]workspaceMemberFile, 0, len(members)),
+		ApiKeys:   make([]apiKeyFile, 0, len(keys)),
+		CreatedAt: workspace.CreatedAt,
+		UpdatedAt: workspace.UpdatedAt,
+	}
+	for _, member := range members {
+		record.Members = append(record.Members, workspaceMemberFile{Id: member.Id, UserId: member.UserId, CreatedAt: member.CreatedAt})
+	}
+	for _, key := range keys {
+		record.ApiKeys = append(record.ApiKeys, apiKeyFile{Id: key.Id, Uuid: key.Uuid, Name: key.Name, Prefix: key.Prefix, Hash: key.Hash, CreatedAt: key.CreatedAt})
+	}
+	return r.store.write(workspace.Uuid, record)
+}
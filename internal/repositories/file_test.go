@@ -0,0 +1,18 @@
+package repositories_test
+
+import (
+	"kite/internal/configs"
+	"kite/internal/database"
+	"testing"
+)
+
+func TestFileConformance(t *testing.T) {
+	for _, format := range []string{"yaml", "json"} {
+		t.Run(format, func(t *testing.T) {
+			runConformance(t, func(t *testing.T) (*configs.DatabaseConfig, *database.Connection) {
+				cfg := &configs.DatabaseConfig{Driver: database.DriverFile, Path: t.TempDir(), Format: format}
+				return cfg, connect(t, cfg)
+			})
+		})
+	}
+}
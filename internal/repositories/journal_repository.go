@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"kite/internal/configs"
+	"kite/internal/database"
+	"kite/internal/journal"
+	"kite/internal/models"
+)
+
+// NewJournalSink 根据配置创建请求日志的存储，未配置时使用内存存储
+func NewJournalSink(cfg *configs.JournalConfig, connection *database.Connection) (journal.Sink, error) {
+	switch cfg.Sink {
+	case "", journal.SinkMemory:
+		return journal.NewMemorySink(cfg.Capacity), nil
+	case journal.SinkFile:
+		return journal.NewFileSink(cfg.Path, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
+	case journal.SinkDatabase:
+		if connection.GetDB() == nil {
+			return nil, fmt.Errorf("journal sink database requires the mysql or sqlite database driver")
+		}
+		return &journalRepository{db: connection.GetDB()}, nil
+	default:
+		return nil, fmt.Errorf("unknown journal sink %q", cfg.Sink)
+	}
+}
+
+// journalRepository 将请求日志存储在 journal_entries 表中
+type journalRepository struct {
+	db *gorm.DB
+}
+
+func (r *journalRepository) Append(ctx context.Context, entry *journal.Entry) error {
+	headers, err := json.Marshal(entry.Headers)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(&models.JournalEntry{
+		Uuid:          entry.Id,
+		Uid:           entry.Uid,
+		Method:        entry.Method,
+		Path:          entry.Path,
+		Query:         entry.Query,
+		Headers:       headers,
+		Body:          entry.Body,
+		BodyTruncated: entry.BodyTruncated,
+		MatchedUuid:   entry.MatchedUuid,
+		StatusCode:    entry.StatusCode,
+		LatencyMs:     entry.LatencyMs,
+		RequestId:     entry.RequestId,
+		CreatedAt:     entry.CreatedAt,
+	}).Error
+}
+
+func (r *journalRepository) Search(ctx context.Context, filter journal.Filter) ([]*journal.Entry, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.JournalEntry{}).Where("uid = ?", filter.Uid)
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if filter.PathPrefix != "" {
+		query = query.Where(likeCondition(r.db, "path"), escapeLike(filter.PathPrefix)+"%")
+	}
+	if filter.StatusCode != 0 {
+		query = query.Where("status_code = ?", filter.StatusCode)
+	}
+	if filter.MatchedUuid != "" {
+		query = query.Where("matched_uuid = ?", filter.MatchedUuid)
+	}
+	if filter.Matched != nil {
+		if *filter.Matched {
+			query = query.Where("matched_uuid <> ''")
+		} else {
+			query = query.Where("matched_uuid = ''")
+		}
+	}
+	if filter.RequestId != "" {
+		query = query.Where("request_id = ?", filter.RequestId)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at <= ?", filter.Until)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if filter.Limit > 0 {
+		query = query.Offset(filter.Offset).Limit(filter.Limit)
+	}
+	var records []*models.JournalEntry
+	if err := query.Order("id DESC").Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	entries := make([]*journal.Entry, 0, len(records))
+	for _, record := range records {
+		headers := make(map[string]string)
+		if len(record.Headers) > 0 {
+			if err := json.Unmarshal(record.Headers, &headers); err != nil {
+				return nil, 0, err
+			}
+		}
+		entries = append(entries, &journal.Entry{
+			Id:            record.Uuid,
+			Uid:           record.Uid,
+			Method:        record.Method,
+			Path:          record.Path,
+			Query:         record.Query,
+			Headers:       headers,
+			Body:          record.Body,
+			BodyTruncated: record.BodyTruncated,
+			MatchedUuid:   record.MatchedUuid,
+			StatusCode:    record.StatusCode,
+			LatencyMs:     record.LatencyMs,
+			RequestId:     record.RequestId,
+			CreatedAt:     record.CreatedAt,
+		})
+	}
+	return entries, total, nil
+}
+
+func (r *journalRepository) Clear(ctx context.Context, uid string) error {
+	return r.db.WithContext(ctx).Where("uid = ?", uid).Delete(&models.JournalEntry{}).Error
+}
+
+// Close 数据库连接由 Connection 统一关闭
+func (r *journalRepository) Close() error {
+	return nil
+}
@@ -0,0 +1,375 @@
+package repositories
+
+import (
+	"context"
+	"kite/internal/api/payloads"
+	KiteError "kite/internal/errors"
+	"kite/internal/models"
+	"kite/internal/tenant"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryApiRepository 进程内的 mock 存储，用于测试和嵌入式服务。
+// 读写都复制记录，调用方修改返回的对象不会影响存储的数据
+type memoryApiRepository struct {
+	mu     sync.RWMutex
+	apis   []*models.Api
+	nextId uint64
+	// version 每次写入时递增，作为 Fingerprint
+	version uint64
+}
+
+func NewMemoryApiRepository() ApiRepository {
+	return &memoryApiRepository{}
+}
+
+func (r *memoryApiRepository) CreateApi(ctx context.Context, payload payloads.MockApiPayload, uuid string) error {
+	api := &models.Api{Uuid: uuid}
+	if err := payload.ApplyTo(api); err != nil {
+		return KiteError.New(KiteError.MarshalError, err)
+	}
+	return r.InsertApi(ctx, api)
+}
+
+func (r *memoryApiRepository) InsertApi(ctx context.Context, api *models.Api) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.insert(ctx, api)
+	return nil
+}
+
+// InsertApis 持有锁写入全部 mock，读取方不会看到只写入了一部分的结果
+func (r *memoryApiRepository) InsertApis(ctx context.Context, apis []*models.Api) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, api := range apis {
+		r.insert(ctx, api)
+	}
+	return nil
+}
+
+func (r *memoryApiRepository) insert(ctx context.Context, api *models.Api) {
+	if workspace, ok := tenant.Workspace(ctx); ok {
+		api.WorkspaceId = workspace
+	}
+	r.nextId++
+	now := time.Now()
+	api.Id, api.CreatedAt, api.UpdatedAt = r.nextId, now, now
+	stored := *api
+	r.apis = append(r.apis, &stored)
+	r.version++
+}
+
+// restore 保留记录原有的 id 与时间写入，用于从持久化的数据恢复
+func (r *memoryApiRepository) restore(api *models.Api) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *api
+	r.apis = append(r.apis, &stored)
+	r.nextId = max(r.nextId, api.Id)
+}
+
+func (r *memoryApiRepository) UpdateApi(ctx context.Context, api *models.Api) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, stored := range r.apis {
+		if stored.Id == api.Id && tenant.Contains(ctx, stored.WorkspaceId) {
+			if workspace, ok := tenant.Workspace(ctx); ok {
+				api.WorkspaceId = workspace
+			}
+			api.UpdatedAt = time.Now()
+			updated := *api
+			r.apis[i] = &updated
+			r.version++
+			return nil
+		}
+	}
+	return ErrRecordNotFound
+}
+
+func (r *memoryApiRepository) DeleteApiByUuid(ctx context.Context, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, stored := range r.apis {
+		if stored.Uuid == uuid && tenant.Contains(ctx, stored.WorkspaceId) {
+			r.apis = slices.Delete(r.apis, i, i+1)
+			r.version++
+			return nil
+		}
+	}
+	return ErrRecordNotFound
+}
+
+func (r *memoryApiRepository) GetApiByUuid(ctx context.Context, uuid string) (*models.Api, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, stored := range r.apis {
+		if stored.Uuid == uuid && tenant.Contains(ctx, stored.WorkspaceId) {
+			api := *stored
+			return &api, nil
+		}
+	}
+	return nil, ErrRecordNotFound
+}
+
+func (r *memoryApiRepository) ListApis(ctx context.Context, filter ApiFilter) ([]*models.Api, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	matched := make([]*models.Api, 0)
+	for _, stored := range r.apis {
+		if !tenant.Contains(ctx, stored.WorkspaceId) {
+			continue
+		}
+		if filter.UserId != "" && stored.UserId != filter.UserId {
+			continue
+		}
+		if filter.Method != "" && stored.Method != filter.Method {
+			continue
+		}
+		if filter.PathPrefix != "" && !strings.HasPrefix(stored.Path, filter.PathPrefix) {
+			continue
+		}
+		if filter.Tag != "" {
+			tags, err := stored.GetTags()
+			if err != nil || !slices.Contains(tags, filter.Tag) {
+				continue
+			}
+		}
+		api := *stored
+		matched = append(matched, &api)
+	}
+	total := int64(len(matched))
+	if filter.Limit > 0 {
+		start := min(filter.Offset, len(matched))
+		end := min(start+filter.Limit, len(matched))
+		matched = matched[start:end]
+	}
+	return matched, total, nil
+}
+
+func (r *memoryApiRepository) QueryApisWithUidAndMethod(ctx context.Context, uid string, method string) ([]*models.Api, error) {
+	apis, _, err := r.ListApis(ctx, ApiFilter{UserId: uid, Method: method})
+	return apis, err
+}
+
+func (r *memoryApiRepository) Fingerprint(_ context.Context) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return strconv.FormatUint(r.version, 10), nil
+}
+
+// memoryNamespaceRepository 进程内的命名空间配置存储
+type memoryNamespaceRepository struct {
+	mu         sync.RWMutex
+	namespaces map[string]*models.Namespace
+	nextId     uint64
+	// version 每次写入时递增，作为 Fingerprint
+	version uint64
+}
+
+func NewMemoryNamespaceRepository() NamespaceRepository {
+	return &memoryNamespaceRepository{namespaces: make(map[string]*models.Namespace)}
+}
+
+// restore 保留记录原有的 id 与时间写入，用于从持久化的数据恢复
+func (r *memoryNamespaceRepository) restore(namespace *models.Namespace) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *namespace
+	r.namespaces[namespace.Uid] = &stored
+	r.nextId = max(r.nextId, namespace.Id)
+}
+
+func (r *memoryNamespaceRepository) GetNamespaceByUid(ctx context.Context, uid string) (*models.Namespace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stored, ok := r.namespaces[uid]
+	if !ok || !tenant.Contains(ctx, stored.WorkspaceId) {
+		return nil, ErrRecordNotFound
+	}
+	namespace := *stored
+	return &namespace, nil
+}
+
+func (r *memoryNamespaceRepository) SaveNamespace(ctx context.Context, namespace *models.Namespace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if workspace, ok := tenant.Workspace(ctx); ok {
+		if stored, exists := r.namespaces[namespace.Uid]; exists && stored.WorkspaceId != workspace {
+			return ErrRecordNotFound
+		}
+		namespace.WorkspaceId = workspace
+	}
+	now := time.Now()
+	if namespace.Id == 0 {
+		r.nextId++
+		namespace.Id, namespace.CreatedAt = r.nextId, now
+	}
+	namespace.UpdatedAt = now
+	stored := *namespace
+	r.namespaces[namespace.Uid] = &stored
+	r.version++
+	return nil
+}
+
+func (r *memoryNamespaceRepository) Fingerprint(_ context.Context) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return strconv.FormatUint(r.version, 10), nil
+}
+
+// memoryWorkspaceRepository 进程内的工作区存储
+type memoryWorkspaceRepository struct {
+	mu         sync.RWMutex
+	workspaces []*models.Workspace
+	members    []*models.WorkspaceMember
+	keys       []*models.ApiKey
+	nextId     uint64
+}
+
+func NewMemoryWorkspaceRepository() WorkspaceRepository {
+	return &memoryWorkspaceRepository{}
+}
+
+func (r *memoryWorkspaceRepository) CreateWorkspace(_ context.Context, workspace *models.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextId++
+	now := time.Now()
+	workspace.Id, workspace.CreatedAt, workspace.UpdatedAt = r.nextId, now, now
+	stored := *workspace
+	r.workspaces = append(r.workspaces, &stored)
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) GetWorkspaceByUuid(_ context.Context, uuid string) (*models.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, stored := range r.workspaces {
+		if stored.Uuid == uuid {
+			workspace := *stored
+			return &workspace, nil
+		}
+	}
+	return nil, ErrRecordNotFound
+}
+
+func (r *memoryWorkspaceRepository) CountWorkspaces(_ context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.workspaces)), nil
+}
+
+func (r *memoryWorkspaceRepository) ListMembers(_ context.Context, workspaceId string) ([]*models.WorkspaceMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	members := make([]*models.WorkspaceMember, 0)
+	for _, stored := range r.members {
+		if stored.WorkspaceId == workspaceId {
+			member := *stored
+			members = append(members, &member)
+		}
+	}
+	return members, nil
+}
+
+func (r *memoryWorkspaceRepository) AddMember(_ context.Context, member *models.WorkspaceMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextId++
+	member.Id, member.CreatedAt = r.nextId, time.Now()
+	stored := *member
+	r.members = append(r.members, &stored)
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) RemoveMember(_ context.Context, workspaceId string, userId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, stored := range r.members {
+		if stored.WorkspaceId == workspaceId && stored.UserId == userId {
+			r.members = slices.Delete(r.members, i, i+1)
+			return nil
+		}
+	}
+	return ErrRecordNotFound
+}
+
+func (r *memoryWorkspaceRepository) CreateApiKey(_ context.Context, key *models.ApiKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextId++
+	key.Id, key.CreatedAt = r.nextId, time.Now()
+	stored := *key
+	r.keys = append(r.keys, &stored)
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) ListApiKeys(_ context.Context, workspaceId string) ([]*models.ApiKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]*models.ApiKey, 0)
+	for _, stored := range r.keys {
+		if stored.WorkspaceId == workspaceId {
+			key := *stored
+			keys = append(keys, &key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *memoryWorkspaceRepository) DeleteApiKey(_ context.Context, workspaceId string, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, stored := range r.keys {
+		if stored.WorkspaceId == workspaceId && stored.Uuid == uuid {
+			r.keys = slices.Delete(r.keys, i, i+1)
+			return nil
+		}
+	}
+	return ErrRecordNotFound
+}
+
+func (r *memoryWorkspaceRepository) GetApiKeyByHash(_ context.Context, hash string) (*models.ApiKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, stored := range r.keys {
+		if stored.Hash == hash {
+			key := *stored
+			return &key, nil
+		}
+	}
+	return nil, ErrRecordNotFound
+}
+
+// deleteWorkspace 删除工作区及其成员和 API Key，用于文件存储写入失败时回滚
+func (r *memoryWorkspaceRepository) deleteWorkspace(uuid string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workspaces = slices.DeleteFunc(r.workspaces, func(w *models.Workspace) bool { return w.Uuid == uuid })
+	r.members = slices.DeleteFunc(r.members, func(m *models.WorkspaceMember) bool { return m.WorkspaceId == uuid })
+	r.keys = slices.DeleteFunc(r.keys, func(k *models.ApiKey) bool { return k.WorkspaceId == uuid })
+}
+
+// restore 保留记录原有的 id 与时间写入，用于从持久化的数据恢复
+func (r *memoryWorkspaceRepository) restore(workspace *models.Workspace, members []*models.WorkspaceMember, keys []*models.ApiKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *workspace
+	r.workspaces = append(r.workspaces, &stored)
+	r.nextId = max(r.nextId, workspace.Id)
+	for _, member := range members {
+		stored := *member
+		r.members = append(r.members, &stored)
+		r.nextId = max(r.nextId, member.Id)
+	}
+	for _, key := range keys {
+		stored := *key
+		r.keys = append(r.keys, &stored)
+		r.nextId = max(r.nextId, key.Id)
+	}
+}
@@ -0,0 +1,14 @@
+package repositories_test
+
+import (
+	"kite/internal/configs"
+	"kite/internal/database"
+	"testing"
+)
+
+func TestMemoryConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) (*configs.DatabaseConfig, *database.Connection) {
+		cfg := &configs.DatabaseConfig{Driver: database.DriverMemory}
+		return cfg, connect(t, cfg)
+	})
+}
@@ -0,0 +1,63 @@
+package repositories_test
+
+import (
+	"github.com/go-sql-driver/mysql"
+	"kite/internal/configs"
+	"kite/internal/database"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// mysqlDSNEnv 专用于测试的 MySQL 数据库，例如 root:secret@tcp(127.0.0.1:3306)/kite_test。
+// 测试会清空其中的数据，未设置时跳过
+const mysqlDSNEnv = "KITE_TEST_MYSQL_DSN"
+
+// mysqlTables 每次创建存储前清空的表
+var mysqlTables = []string{"api_keys", "workspace_members", "workspaces", "namespaces", "apis"}
+
+func TestMySQLConformance(t *testing.T) {
+	dsn := os.Getenv(mysqlDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", mysqlDSNEnv)
+	}
+	cfg := mysqlConfig(t, dsn)
+	migrate(t, connect(t, cfg))
+	runConformance(t, func(t *testing.T) (*configs.DatabaseConfig, *database.Connection) {
+		connection := connect(t, cfg)
+		for _, table := range mysqlTables {
+			if err := connection.GetDB().Exec("DELETE FROM " + table).Error; err != nil {
+				t.Fatalf("failed to clear %s: %v", table, err)
+			}
+		}
+		return cfg, connection
+	})
+}
+
+func mysqlConfig(t *testing.T, dsn string) *configs.DatabaseConfig {
+	t.Helper()
+	parsed, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", mysqlDSNEnv, err)
+	}
+	host, portText, err := net.SplitHostPort(parsed.Addr)
+	if err != nil {
+		t.Fatalf("invalid address in %s: %v", mysqlDSNEnv, err)
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		t.Fatalf("invalid port in %s: %v", mysqlDSNEnv, err)
+	}
+	return &configs.DatabaseConfig{
+		Driver:      database.DriverMySQL,
+		Host:        host,
+		Port:        port,
+		Name:        parsed.DBName,
+		User:        parsed.User,
+		Password:    parsed.Passwd,
+		Charset:     "utf8mb4",
+		MaxOpenConn: 5,
+		MaxIdleConn: 5,
+	}
+}
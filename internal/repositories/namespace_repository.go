@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gorm.io/gorm"
+	"kite/internal/configs"
+	"kite/internal/database"
+	"kite/internal/models"
+	"kite/internal/tenant"
+)
+
+type NamespaceRepository interface {
+	GetNamespaceByUid(ctx context.Context, uid string) (*models.Namespace, error)
+	SaveNamespace(ctx context.Context, namespace *models.Namespace) error
+	// Fingerprint 返回反映全部命名空间配置当前状态的摘要，任何新增、修改都会使摘要变化，用于感知其他实例的修改
+	Fingerprint(ctx context.Context) (string, error)
+}
+
+type namespaceRepository struct {
+	db *gorm.DB
+}
+
+// NewNamespaceRepository 根据配置的驱动创建命名空间配置的存储
+func NewNamespaceRepository(cfg *configs.DatabaseConfig, connection *database.Connection) (NamespaceRepository, error) {
+	switch connection.Driver() {
+	case database.DriverMemory:
+		return NewMemoryNamespaceRepository(), nil
+	case database.DriverFile:
+		return NewFileNamespaceRepository(cfg.Path, cfg.Format)
+	default:
+		return &namespaceRepository{db: connection.GetDB()}, nil
+	}
+}
+
+func (r *namespaceRepository) GetNamespaceByUid(ctx context.Context, uid string) (*models.Namespace, error) {
+	var namespace *models.Namespace
+	result := scope(ctx, r.db).Where("uid = ?", uid).First(&namespace)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+	return namespace, nil
+}
+
+// SaveNamespace 限定工作区时只能修改该工作区的配置，新建的配置归属到该工作区
+func (r *namespaceRepository) SaveNamespace(ctx context.Context, namespace *models.Namespace) error {
+	if workspace, ok := tenant.Workspace(ctx); ok {
+		if namespace.Id != 0 {
+			var count int64
+			if err := scope(ctx, r.db).Model(&models.Namespace{}).Where("id = ?", namespace.Id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return ErrRecordNotFound
+			}
+		}
+		namespace.WorkspaceId = workspace
+	}
+	result := r.db.WithContext(ctx).Save(namespace)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// Fingerprint 由记录数、最大 id 和精确到微秒的最近修改时间组成，不限定工作区
+func (r *namespaceRepository) Fingerprint(ctx context.Context) (string, error) {
+	var row struct {
+		Total       int64
+		MaxId       uint64
+		LastUpdated sql.NullString
+	}
+	err := r.db.WithContext(ctx).Model(&models.Namespace{}).
+		Select("COUNT(*) AS total, COALESCE(MAX(id), 0) AS max_id, MAX(updated_at) AS last_updated").
+		Scan(&row).Error
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d:%d:%s", row.Total, row.MaxId, row.LastUpdated.String), nil
+}
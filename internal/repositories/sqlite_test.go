@@ -0,0 +1,32 @@
+package repositories_test
+
+import (
+	"kite/internal/configs"
+	"kite/internal/database"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteConformance(t *testing.T) {
+	tests := []struct {
+		name string
+		path func(t *testing.T) string
+		// migrate 数据库文件需要显式迁移，内存数据库在打开时自动迁移
+		migrate bool
+	}{
+		{name: "file", path: func(t *testing.T) string { return filepath.Join(t.TempDir(), "data", "mock.db") }, migrate: true},
+		{name: "memory", path: func(*testing.T) string { return ":memory:" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runConformance(t, func(t *testing.T) (*configs.DatabaseConfig, *database.Connection) {
+				cfg := &configs.DatabaseConfig{Driver: database.DriverSQLite, Path: tt.path(t)}
+				connection := connect(t, cfg)
+				if tt.migrate {
+					migrate(t, connection)
+				}
+				return cfg, connection
+			})
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"kite/internal/configs"
+	"kite/internal/database"
+	"kite/internal/models"
+)
+
+// WorkspaceRepository 工作区、成员与 API Key 的存储。成员与 API Key 的操作都显式指定所属的工作区
+type WorkspaceRepository interface {
+	CreateWorkspace(ctx context.Context, workspace *models.Workspace) error
+	GetWorkspaceByUuid(ctx context.Context, uuid string) (*models.Workspace, error)
+	// CountWorkspaces 返回已创建的工作区数量
+	CountWorkspaces(ctx context.Context) (int64, error)
+	ListMembers(ctx context.Context, workspaceId string) ([]*models.WorkspaceMember, error)
+	AddMember(ctx context.Context, member *models.WorkspaceMember) error
+	RemoveMember(ctx context.Context, workspaceId string, userId string) error
+	CreateApiKey(ctx context.Context, key *models.ApiKey) error
+	ListApiKeys(ctx context.Context, workspaceId string) ([]*models.ApiKey, error)
+	DeleteApiKey(ctx context.Context, workspaceId string, uuid string) error
+	GetApiKeyByHash(ctx context.Context, hash string) (*models.ApiKey, error)
+}
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkspaceRepository 根据配置的驱动创建工作区的存储
+func NewWorkspaceRepository(cfg *configs.DatabaseConfig, connection *database.Connection) (WorkspaceRepository, error) {
+	switch connection.Driver() {
+	case database.DriverMemory:
+		return NewMemoryWorkspaceRepository(), nil
+	case database.DriverFile:
+		return NewFileWorkspaceRepository(cfg.Path, cfg.Format)
+	default:
+		return &workspaceRepository{db: connection.GetDB()}, nil
+	}
+}
+
+func (r *workspaceRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	return r.db.WithContext(ctx).Create(workspace).Error
+}
+
+func (r *workspaceRepository) GetWorkspaceByUuid(ctx context.Context, uuid string) (*models.Workspace, error) {
+	var workspace *models.Workspace
+	result := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&workspace)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+	return workspace, nil
+}
+
+func (r *workspaceRepository) CountWorkspaces(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Workspace{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *workspaceRepository) ListMembers(ctx context.Context, workspaceId string) ([]*models.WorkspaceMember, error) {
+	var members []*models.WorkspaceMember
+	result := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceId).Order("id ASC").Find(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
+
+func (r *workspaceRepository) AddMember(ctx context.Context, member *models.WorkspaceMember) error {
+	return r.db.WithContext(ctx).Create(member).Error
+}
+
+func (r *workspaceRepository) RemoveMember(ctx context.Context, workspaceId string, userId string) error {
+	result := r.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceId, userId).Delete(&models.WorkspaceMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *workspaceRepository) CreateApiKey(ctx context.Context, key *models.ApiKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *workspaceRepository) ListApiKeys(ctx context.Context, workspaceId string) ([]*models.ApiKey, error) {
+	var keys []*models.ApiKey
+	result := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceId).Order("id ASC").Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+func (r *workspaceRepository) DeleteApiKey(ctx context.Context, workspaceId string, uuid string) error {
+	result := r.db.WithContext(ctx).Where("workspace_id = ? AND uuid = ?", workspaceId, uuid).Delete(&models.ApiKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *workspaceRepository) GetApiKeyByHash(ctx context.Context, hash string) (*models.ApiKey, error) {
+	var key *models.ApiKey
+	result := r.db.WithContext(ctx).Where("hash = ?", hash).First(&key)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+	return key, nil
+}
@@ -0,0 +1,120 @@
+package routing
+
+import (
+	"context"
+	"kite/internal/configs"
+	"kite/internal/database"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"kite/internal/tenant"
+	"sync"
+)
+
+// NamespaceCache 缓存命名空间配置，mock 请求的热路径不再查询存储。
+// 经过它的写操作使相应命名空间失效；其他实例的修改随路由表的 Refresh 一起通过 Fingerprint 感知。
+// 只缓存存储中存在的配置，未配置过的命名空间每次都查询存储，缓存的大小不超过配置的数量
+type NamespaceCache struct {
+	repositories.NamespaceRepository
+	cfg *configs.RoutingConfig
+
+	mu      sync.RWMutex
+	entries map[string]*models.Namespace
+	// generation 每次失效时递增，加载期间发生过失效的结果不写入缓存
+	generation  uint64
+	fingerprint string
+}
+
+// NewNamespaceCache 在命名空间存储上建立缓存，并随路由表一起刷新
+func NewNamespaceCache(repo repositories.NamespaceRepository, table *Table) *NamespaceCache {
+	c := &NamespaceCache{
+		NamespaceRepository: repo,
+		cfg:                 table.cfg,
+		entries:             make(map[string]*models.Namespace),
+	}
+	table.attach(c)
+	return c
+}
+
+// NewNamespaceRepository 根据配置创建命名空间存储，并在其上建立缓存
+func NewNamespaceRepository(dbCfg *configs.DatabaseConfig, connection *database.Connection, table *Table) (repositories.NamespaceRepository, error) {
+	repo, err := repositories.NewNamespaceRepository(dbCfg, connection)
+	if err != nil {
+		return nil, err
+	}
+	return NewNamespaceCache(repo, table), nil
+}
+
+// GetNamespaceByUid 缓存中保存不限定工作区读取的配置，返回前按 context 中的工作区过滤
+func (c *NamespaceCache) GetNamespaceByUid(ctx context.Context, uid string) (*models.Namespace, error) {
+	if !c.cfg.Enabled {
+		return c.NamespaceRepository.GetNamespaceByUid(ctx, uid)
+	}
+	c.mu.RLock()
+	cached, ok := c.entries[uid]
+	generation := c.generation
+	c.mu.RUnlock()
+	if !ok {
+		namespace, err := c.NamespaceRepository.GetNamespaceByUid(tenant.Unscoped(ctx), uid)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		if c.generation == generation {
+			c.entries[uid] = namespace
+		}
+		c.mu.Unlock()
+		cached = namespace
+	}
+	if !tenant.Contains(ctx, cached.WorkspaceId) {
+		return nil, repositories.ErrRecordNotFound
+	}
+	// 调用方会修改返回的配置后保存，不能直接返回缓存的对象
+	namespace := *cached
+	return &namespace, nil
+}
+
+func (c *NamespaceCache) SaveNamespace(ctx context.Context, namespace *models.Namespace) error {
+	defer c.Invalidate(namespace.Uid)
+	return c.NamespaceRepository.SaveNamespace(ctx, namespace)
+}
+
+// Invalidate 使指定命名空间的配置失效，下一次请求时重新加载
+func (c *NamespaceCache) Invalidate(uids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for _, uid := range uids {
+		delete(c.entries, uid)
+	}
+}
+
+// InvalidateAll 使全部命名空间的配置失效
+func (c *NamespaceCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[string]*models.Namespace)
+}
+
+// Refresh 比较存储的 Fingerprint，存储被修改过时使全部配置失效，返回是否失效
+func (c *NamespaceCache) Refresh(ctx context.Context) (bool, error) {
+	fingerprint, err := c.NamespaceRepository.Fingerprint(ctx)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	changed := fingerprint != c.fingerprint
+	c.fingerprint = fingerprint
+	c.mu.Unlock()
+	if changed {
+		c.InvalidateAll()
+	}
+	return changed, nil
+}
+
+// size 缓存的配置数
+func (c *NamespaceCache) size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
@@ -0,0 +1,336 @@
+package routing
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"kite/internal/api/payloads"
+	"kite/internal/configs"
+	"kite/internal/database"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"kite/internal/tenant"
+	KiteLogger "kite/pkg/logger"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics 路由表的统计数据
+type Metrics struct {
+	Enabled bool  `json:"enabled"`
+	Lookups int64 `json:"lookups"`
+	// CacheHits 命名空间的路由已编译，CacheMisses 需要从存储加载后编译
+	CacheHits   int64 `json:"cache_hits"`
+	CacheMisses int64 `json:"cache_misses"`
+	// RouteHits 路径找到了候选 mock，RouteMisses 没有任何候选
+	RouteHits     int64 `json:"route_hits"`
+	RouteMisses   int64 `json:"route_misses"`
+	Invalidations int64 `json:"invalidations"`
+	// Refreshes 检测到存储被其他实例修改或手动刷新，整个路由表失效的次数
+	Refreshes  int64 `json:"refreshes"`
+	Namespaces int   `json:"namespaces"`
+	Routes     int   `json:"routes"`
+	// NamespaceConfigs 缓存的命名空间配置数
+	NamespaceConfigs int `json:"namespace_configs"`
+}
+
+// namespaceRoutes 一个命名空间下按方法划分的路由树
+type namespaceRoutes struct {
+	methods map[string]*trie
+	uuids   []string
+	size    int
+}
+
+// Table 按命名空间惰性编译的路由表，mock 请求的热路径不再查询存储。
+// Table 同时包装了 mock 存储，经过它的写操作会使相应命名空间的路由失效；
+// 其他实例的修改通过定期比较存储的 Fingerprint 感知。没有任何 mock 的命名空间不缓存，
+// 请求任意 uid 不会让路由表无限增长
+type Table struct {
+	repositories.ApiRepository
+	cfg *configs.RoutingConfig
+
+	mu         sync.RWMutex
+	namespaces map[string]*namespaceRoutes
+	// owners 已编译的 mock 所属的命名空间，用于删除或移动 mock 时找到需要失效的命名空间
+	owners map[string]string
+	// generation 每次失效时递增，编译期间发生过失效的结果不写入缓存
+	generation  uint64
+	fingerprint string
+	// configs 随路由表一起刷新的命名空间配置缓存
+	configs *NamespaceCache
+
+	lookups       atomic.Int64
+	cacheHits     atomic.Int64
+	cacheMisses   atomic.Int64
+	routeHits     atomic.Int64
+	routeMisses   atomic.Int64
+	invalidations atomic.Int64
+	refreshes     atomic.Int64
+
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+func NewTable(repo repositories.ApiRepository, cfg *configs.RoutingConfig) *Table {
+	return &Table{
+		ApiRepository: repo,
+		cfg:           cfg,
+		namespaces:    make(map[string]*namespaceRoutes),
+		owners:        make(map[string]string),
+		stop:          make(chan struct{}),
+	}
+}
+
+// NewRepository 根据配置创建 mock 存储，并在其上建立路由表
+func NewRepository(dbCfg *configs.DatabaseConfig, connection *database.Connection, cfg *configs.RoutingConfig) (*Table, error) {
+	repo, err := repositories.NewApiRepository(dbCfg, connection)
+	if err != nil {
+		return nil, err
+	}
+	return NewTable(repo, cfg), nil
+}
+
+// Lookup 返回命名空间下指定方法、路径能匹配上的 mock，按 id 排序。
+// 路由表关闭时返回该方法的全部 mock，由调用方匹配路径
+func (t *Table) Lookup(ctx context.Context, uid string, method string, path string) ([]*models.Api, error) {
+	if !t.cfg.Enabled {
+		return t.ApiRepository.QueryApisWithUidAndMethod(ctx, uid, method)
+	}
+	t.lookups.Add(1)
+	routes, err := t.namespace(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	var apis []*models.Api
+	if tree, ok := routes.methods[method]; ok {
+		apis = tree.lookup(path)
+	}
+	if len(apis) == 0 {
+		t.routeMisses.Add(1)
+	} else {
+		t.routeHits.Add(1)
+	}
+	return apis, nil
+}
+
+// namespace 返回命名空间编译后的路由，未编译时从存储加载
+func (t *Table) namespace(ctx context.Context, uid string) (*namespaceRoutes, error) {
+	t.mu.RLock()
+	routes, ok := t.namespaces[uid]
+	generation := t.generation
+	t.mu.RUnlock()
+	if ok {
+		t.cacheHits.Add(1)
+		return routes, nil
+	}
+	t.cacheMisses.Add(1)
+
+	// 路由表在工作区之间共享，编译时读取命名空间下的全部 mock
+	apis, _, err := t.ApiRepository.ListApis(tenant.Unscoped(ctx), repositories.ApiFilter{UserId: uid})
+	if err != nil {
+		return nil, err
+	}
+	routes = compile(apis)
+	if len(apis) == 0 {
+		return routes, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.generation == generation {
+		t.namespaces[uid] = routes
+		for _, uuid := range routes.uuids {
+			t.owners[uuid] = uid
+		}
+	}
+	return routes, nil
+}
+
+// compile 为每个方法建立路由树，路径模式无效的 mock 永远不会被匹配，直接忽略
+func compile(apis []*models.Api) *namespaceRoutes {
+	routes := &namespaceRoutes{methods: make(map[string]*trie), uuids: make([]string, 0, len(apis))}
+	for _, api := range apis {
+		routes.uuids = append(routes.uuids, api.Uuid)
+		pattern, err := matching.CompilePath(api.Path)
+		if err != nil {
+			continue
+		}
+		tree, ok := routes.methods[api.Method]
+		if !ok {
+			tree = newTrie()
+			routes.methods[api.Method] = tree
+		}
+		tree.insert(api, pattern)
+		routes.size++
+	}
+	return routes
+}
+
+// Invalidate 使指定命名空间的路由失效，下一次请求时重新编译
+func (t *Table) Invalidate(uids ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.generation++
+	for _, uid := range uids {
+		routes, ok := t.namespaces[uid]
+		if !ok {
+			continue
+		}
+		for _, uuid := range routes.uuids {
+			delete(t.owners, uuid)
+		}
+		delete(t.namespaces, uid)
+		t.invalidations.Add(1)
+	}
+}
+
+// InvalidateAll 使全部命名空间的路由以及缓存的命名空间配置失效
+func (t *Table) InvalidateAll() {
+	t.mu.Lock()
+	t.generation++
+	t.namespaces = make(map[string]*namespaceRoutes)
+	t.owners = make(map[string]string)
+	t.refreshes.Add(1)
+	configs := t.configs
+	t.mu.Unlock()
+	if configs != nil {
+		configs.InvalidateAll()
+	}
+}
+
+// Refresh 比较 mock 与命名空间配置存储的 Fingerprint，mock 被修改过时使整个路由表失效，
+// 命名空间配置被修改过时使缓存的配置失效，返回是否有任何失效
+func (t *Table) Refresh(ctx context.Context) (bool, error) {
+	fingerprint, err := t.ApiRepository.Fingerprint(ctx)
+	if err != nil {
+		return false, err
+	}
+	t.mu.Lock()
+	changed := fingerprint != t.fingerprint
+	t.fingerprint = fingerprint
+	configs := t.configs
+	t.mu.Unlock()
+	if changed {
+		t.InvalidateAll()
+	}
+	if configs == nil {
+		return changed, nil
+	}
+	configsChanged, err := configs.Refresh(ctx)
+	return changed || configsChanged, err
+}
+
+// attach 关联命名空间配置缓存，使其随路由表一起刷新和失效
+func (t *Table) attach(configs *NamespaceCache) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.configs = configs
+}
+
+// Start 按配置的间隔在后台检查存储是否被修改
+func (t *Table) Start() {
+	if !t.cfg.Enabled || t.cfg.RefreshInterval <= 0 {
+		return
+	}
+	t.startOnce.Do(func() {
+		t.done = make(chan struct{})
+		go t.refreshLoop(time.Duration(t.cfg.RefreshInterval) * time.Second)
+	})
+}
+
+func (t *Table) refreshLoop(interval time.Duration) {
+	defer close(t.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if _, err := t.Refresh(ctx); err != nil {
+				KiteLogger.Warn("Failed to refresh routing table", zap.Error(err))
+			}
+			cancel()
+		}
+	}
+}
+
+// Close 停止后台检查并等待其退出
+func (t *Table) Close() {
+	t.closeOnce.Do(func() {
+		close(t.stop)
+		if t.done != nil {
+			<-t.done
+		}
+	})
+}
+
+// Metrics 返回当前的统计数据
+func (t *Table) Metrics() Metrics {
+	t.mu.RLock()
+	namespaces := len(t.namespaces)
+	routes := 0
+	for _, namespace := range t.namespaces {
+		routes += namespace.size
+	}
+	configs := t.configs
+	t.mu.RUnlock()
+	namespaceConfigs := 0
+	if configs != nil {
+		namespaceConfigs = configs.size()
+	}
+	return Metrics{
+		Enabled:          t.cfg.Enabled,
+		Lookups:          t.lookups.Load(),
+		CacheHits:        t.cacheHits.Load(),
+		CacheMisses:      t.cacheMisses.Load(),
+		RouteHits:        t.routeHits.Load(),
+		RouteMisses:      t.routeMisses.Load(),
+		Invalidations:    t.invalidations.Load(),
+		Refreshes:        t.refreshes.Load(),
+		Namespaces:       namespaces,
+		Routes:           routes,
+		NamespaceConfigs: namespaceConfigs,
+	}
+}
+
+func (t *Table) owner(uuid string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.owners[uuid]
+}
+
+func (t *Table) CreateApi(ctx context.Context, payload payloads.MockApiPayload, uuid string) error {
+	defer t.Invalidate(payload.UserId)
+	return t.ApiRepository.CreateApi(ctx, payload, uuid)
+}
+
+func (t *Table) InsertApi(ctx context.Context, api *models.Api) error {
+	defer t.Invalidate(api.UserId)
+	return t.ApiRepository.InsertApi(ctx, api)
+}
+
+func (t *Table) InsertApis(ctx context.Context, apis []*models.Api) error {
+	uids := make([]string, 0, len(apis))
+	for _, api := range apis {
+		uids = append(uids, api.UserId)
+	}
+	defer t.Invalidate(uids...)
+	return t.ApiRepository.InsertApis(ctx, apis)
+}
+
+// UpdateApi mock 可能被移动到其他命名空间，原命名空间也需要失效
+func (t *Table) UpdateApi(ctx context.Context, api *models.Api) error {
+	defer t.Invalidate(api.UserId, t.owner(api.Uuid))
+	return t.ApiRepository.UpdateApi(ctx, api)
+}
+
+// DeleteApiByUuid 未编译的命名空间无需失效，但仍然递增 generation，避免正在进行的编译缓存旧数据
+func (t *Table) DeleteApiByUuid(ctx context.Context, uuid string) error {
+	defer t.Invalidate(t.owner(uuid))
+	return t.ApiRepository.DeleteApiByUuid(ctx, uuid)
+}
@@ -0,0 +1,183 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"kite/internal/api/payloads"
+	"kite/internal/configs"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"kite/internal/repositories/conformance"
+	"kite/internal/tenant"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTableConformance(t *testing.T) {
+	conformance.RunApiRepository(t, func(t *testing.T) repositories.ApiRepository {
+		return NewTable(repositories.NewMemoryApiRepository(), &configs.RoutingConfig{Enabled: true})
+	})
+}
+
+func TestTableSkipsEmptyNamespaces(t *testing.T) {
+	ctx := context.Background()
+	table := NewTable(repositories.NewMemoryApiRepository(), &configs.RoutingConfig{Enabled: true})
+	payload := payloads.MockApiPayload{UserId: "ns", Method: http.MethodGet, Path: "/items", StatusCode: 200, ResponseBody: "ok"}
+	if err := table.CreateApi(ctx, payload, "uuid-1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, uid := range []string{"unknown-1", "unknown-2", "ns", "ns"} {
+		if _, err := table.Lookup(ctx, uid, http.MethodGet, "/items"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	metrics := table.Metrics()
+	if metrics.Namespaces != 1 || metrics.CacheHits != 1 || metrics.CacheMisses != 3 {
+		t.Fatalf("metrics = %+v, want only the namespace with mocks cached", metrics)
+	}
+}
+
+// countingNamespaces 统计读取存储的次数
+type countingNamespaces struct {
+	repositories.NamespaceRepository
+	reads atomic.Int64
+}
+
+func (c *countingNamespaces) GetNamespaceByUid(ctx context.Context, uid string) (*models.Namespace, error) {
+	c.reads.Add(1)
+	return c.NamespaceRepository.GetNamespaceByUid(ctx, uid)
+}
+
+func TestNamespaceCache(t *testing.T) {
+	ctx := context.Background()
+	newCache := func(enabled bool) (*NamespaceCache, *countingNamespaces, *Table) {
+		store := &countingNamespaces{NamespaceRepository: repositories.NewMemoryNamespaceRepository()}
+		table := NewTable(repositories.NewMemoryApiRepository(), &configs.RoutingConfig{Enabled: enabled})
+		if err := store.SaveNamespace(ctx, &models.Namespace{Uid: "ns", WorkspaceId: "w1", NotFoundStatus: 404}); err != nil {
+			t.Fatal(err)
+		}
+		return NewNamespaceCache(store, table), store, table
+	}
+	get := func(t *testing.T, cache *NamespaceCache, ctx context.Context, uid string) *models.Namespace {
+		t.Helper()
+		namespace, err := cache.GetNamespaceByUid(ctx, uid)
+		if err != nil {
+			t.Fatalf("GetNamespaceByUid(%q): %v", uid, err)
+		}
+		return namespace
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, cache *NamespaceCache, store *countingNamespaces, table *Table)
+	}{
+		{
+			name: "repeated reads hit the store once",
+			run: func(t *testing.T, cache *NamespaceCache, store *countingNamespaces, table *Table) {
+				for i := 0; i < 3; i++ {
+					get(t, cache, ctx, "ns")
+				}
+				if reads := store.reads.Load(); reads != 1 {
+					t.Fatalf("store read %d times, want 1", reads)
+				}
+				if configs := table.Metrics().NamespaceConfigs; configs != 1 {
+					t.Fatalf("NamespaceConfigs = %d, want 1", configs)
+				}
+			},
+		},
+		{
+			name: "missing namespaces are not cached",
+			run: func(t *testing.T, cache *NamespaceCache, store *countingNamespaces, table *Table) {
+				for i := 0; i < 2; i++ {
+					if _, err := cache.GetNamespaceByUid(ctx, "missing"); !errors.Is(err, repositories.ErrRecordNotFound) {
+						t.Fatalf("GetNamespaceByUid = %v, want ErrRecordNotFound", err)
+					}
+				}
+				if reads, size := store.reads.Load(), cache.size(); reads != 2 || size != 0 {
+					t.Fatalf("reads = %d, cached = %d, want 2 reads and nothing cached", reads, size)
+				}
+			},
+		},
+		{
+			name: "returned namespaces are copies",
+			run: func(t *testing.T, cache *NamespaceCache, store *countingNamespaces, table *Table) {
+				get(t, cache, ctx, "ns").NotFoundStatus = 500
+				if status := get(t, cache, ctx, "ns").NotFoundStatus; status != 404 {
+					t.Fatalf("modifying a returned namespace changed the cache: %d", status)
+				}
+			},
+		},
+		{
+			name: "cached namespaces are filtered by workspace",
+			run: func(t *testing.T, cache *NamespaceCache, store *countingNamespaces, table *Table) {
+				get(t, cache, tenant.WithWorkspace(ctx, "w1"), "ns")
+				if _, err := cache.GetNamespaceByUid(tenant.WithWorkspace(ctx, "w2"), "ns"); !errors.Is(err, repositories.ErrRecordNotFound) {
+					t.Fatalf("another workspace read the namespace, err = %v", err)
+				}
+			},
+		},
+		{
+			name: "saving through the cache invalidates it",
+			run: func(t *testing.T, cache *NamespaceCache, store *countingNamespaces, table *Table) {
+				namespace := get(t, cache, ctx, "ns")
+				namespace.NotFoundStatus = 501
+				if err := cache.SaveNamespace(ctx, namespace); err != nil {
+					t.Fatal(err)
+				}
+				if status := get(t, cache, ctx, "ns").NotFoundStatus; status != 501 {
+					t.Fatalf("NotFoundStatus = %d, want 501", status)
+				}
+			},
+		},
+		{
+			name: "refresh picks up changes made by other instances",
+			run: func(t *testing.T, cache *NamespaceCache, store *countingNamespaces, table *Table) {
+				if _, err := table.Refresh(ctx); err != nil {
+					t.Fatal(err)
+				}
+				namespace := get(t, cache, ctx, "ns")
+				namespace.NotFoundStatus = 502
+				if err := store.SaveNamespace(ctx, namespace); err != nil {
+					t.Fatal(err)
+				}
+				if status := get(t, cache, ctx, "ns").NotFoundStatus; status != 404 {
+					t.Fatalf("NotFoundStatus = %d before refresh, want the cached 404", status)
+				}
+				changed, err := table.Refresh(ctx)
+				if err != nil || !changed {
+					t.Fatalf("Refresh = %v, %v, want a change", changed, err)
+				}
+				if status := get(t, cache, ctx, "ns").NotFoundStatus; status != 502 {
+					t.Fatalf("NotFoundStatus = %d after refresh, want 502", status)
+				}
+			},
+		},
+		{
+			name: "manual refresh drops cached namespaces",
+			run: func(t *testing.T, cache *NamespaceCache, store *countingNamespaces, table *Table) {
+				get(t, cache, ctx, "ns")
+				table.InvalidateAll()
+				get(t, cache, ctx, "ns")
+				if reads := store.reads.Load(); reads != 2 {
+					t.Fatalf("store read %d times, want 2", reads)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, store, table := newCache(true)
+			tt.run(t, cache, store, table)
+		})
+	}
+
+	t.Run("disabled routing reads the store every time", func(t *testing.T) {
+		cache, store, _ := newCache(false)
+		get(t, cache, ctx, "ns")
+		get(t, cache, ctx, "ns")
+		if reads := store.reads.Load(); reads != 2 {
+			t.Fatalf("store read %d times, want 2", reads)
+		}
+	})
+}
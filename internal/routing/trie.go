@@ -0,0 +1,105 @@
+package routing
+
+import (
+	"kite/internal/matching"
+	"kite/internal/models"
+	"slices"
+)
+
+// route 路由树中的一个 mock 及其编译后的路径模式
+type route struct {
+	api     *models.Api
+	pattern *matching.PathPattern
+}
+
+// node 路由树的节点，按路径段逐层索引。静态段按值查找，参数与 * 共用一个子节点，
+// 以 ** 结尾的模式挂在 ** 之前的节点上，可以匹配该节点之后的任意路径
+type node struct {
+	static  map[string]*node
+	dynamic *node
+	// routes 恰好在该节点结束的模式，rest 以 ** 结尾的模式
+	routes []route
+	rest   []route
+}
+
+func newNode() *node {
+	return &node{static: make(map[string]*node)}
+}
+
+// trie 一个命名空间下某个方法的全部 mock
+type trie struct {
+	root *node
+	size int
+}
+
+func newTrie() *trie {
+	return &trie{root: newNode()}
+}
+
+func (t *trie) insert(api *models.Api, pattern *matching.PathPattern) {
+	segments, rest := pattern.IndexSegments()
+	current := t.root
+	for _, segment := range segments {
+		if segment.Dynamic {
+			if current.dynamic == nil {
+				current.dynamic = newNode()
+			}
+			current = current.dynamic
+			continue
+		}
+		next, ok := current.static[segment.Literal]
+		if !ok {
+			next = newNode()
+			current.static[segment.Literal] = next
+		}
+		current = next
+	}
+	if rest {
+		current.rest = append(current.rest, route{api, pattern})
+	} else {
+		current.routes = append(current.routes, route{api, pattern})
+	}
+	t.size++
+}
+
+// lookup 返回路径可能匹配的候选 mock，按 id 排序。
+// 路由树只按路径段的结构筛选，正则约束等细节仍由调用方用 PathPattern.Match 判断
+func (t *trie) lookup(path string) []*models.Api {
+	parts := matching.SplitPath(path)
+	var candidates []route
+	var walk func(current *node, depth int)
+	walk = func(current *node, depth int) {
+		candidates = append(candidates, current.rest...)
+		if depth == len(parts) {
+			candidates = append(candidates, current.routes...)
+			return
+		}
+		if next, ok := current.static[parts[depth]]; ok {
+			walk(next, depth+1)
+		}
+		if current.dynamic != nil {
+			walk(current.dynamic, depth+1)
+		}
+	}
+	walk(t.root, 0)
+
+	apis := make([]*models.Api, 0, len(candidates))
+	for _, candidate := range candidates {
+		if _, ok := candidate.pattern.Match(path); ok {
+			api := *candidate.api
+			apis = append(apis, &api)
+		}
+	}
+	// 匹配优先级相同时取最早创建的 mock，候选需要保持 id 顺序
+	slices.SortFunc(apis, func(a, b *models.Api) int {
+		switch {
+		case a.Id < b.Id:
+			return -1
+		case a.Id > b.Id:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return apis
+}
@@ -0,0 +1,68 @@
+package routing
+
+import (
+	"kite/internal/matching"
+	"kite/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestTrieLookup(t *testing.T) {
+	patterns := []string{
+		"/users/{id}",
+		"/users/me",
+		"/users/{id:[0-9]+}",
+		"/users/*/avatar",
+		"/files/**",
+		"/",
+		"/**",
+	}
+	tree := newTrie()
+	for i, path := range patterns {
+		pattern, err := matching.CompilePath(path)
+		if err != nil {
+			t.Fatalf("CompilePath(%q): %v", path, err)
+		}
+		tree.insert(&models.Api{Id: uint64(i + 1), Path: path}, pattern)
+	}
+	if tree.size != len(patterns) {
+		t.Fatalf("size = %d, want %d", tree.size, len(patterns))
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/users/me", []string{"/users/{id}", "/users/me", "/**"}},
+		{"/users/42", []string{"/users/{id}", "/users/{id:[0-9]+}", "/**"}},
+		{"/users/x/avatar", []string{"/users/*/avatar", "/**"}},
+		{"/users/x/banner", []string{"/**"}},
+		{"/users", []string{"/**"}},
+		{"/files", []string{"/files/**", "/**"}},
+		{"/files/a/b.txt", []string{"/files/**", "/**"}},
+		{"/", []string{"/", "/**"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, api := range tree.lookup(tt.path) {
+				got = append(got, api.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("lookup(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrieLookupReturnsCopies(t *testing.T) {
+	pattern, err := matching.CompilePath("/items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := newTrie()
+	tree.insert(&models.Api{Id: 1, Path: "/items", ResponseBody: "stored"}, pattern)
+	tree.lookup("/items")[0].ResponseBody = "changed"
+	if body := tree.lookup("/items")[0].ResponseBody; body != "stored" {
+		t.Fatalf("modifying a lookup result changed the trie: %q", body)
+	}
+}
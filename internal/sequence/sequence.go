@@ -0,0 +1,54 @@
+package sequence
+
+import (
+	"encoding/json"
+)
+
+// 响应序列走完之后的行为
+const (
+	// ModeCycle 回到第一个响应继续循环
+	ModeCycle = "cycle"
+	// ModeStick 停留在最后一个响应
+	ModeStick = "stick"
+	// ModeFail 不再返回响应，调用方应返回错误
+	ModeFail = "fail"
+)
+
+// Step 序列中的一个响应，未设置的字段沿用 mock 自身的配置，Headers 会合并到 mock 的响应头上
+type Step struct {
+	StatusCode  int               `json:"status_code,omitempty" validate:"omitempty,gte=100,lte=599"`
+	ContentType string            `json:"content_type,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        *string           `json:"body,omitempty"`
+}
+
+// Select 根据调用序号（从 0 开始）选出本次要返回的响应，ModeFail 下序列走完时返回 false
+func Select(steps []Step, mode string, index int64) (*Step, bool) {
+	if len(steps) == 0 || index < 0 {
+		return nil, false
+	}
+	count := int64(len(steps))
+	if index < count {
+		return &steps[index], true
+	}
+	switch mode {
+	case ModeStick:
+		return &steps[count-1], true
+	case ModeFail:
+		return nil, false
+	default:
+		return &steps[index%count], true
+	}
+}
+
+// DecodeSteps 解析存储的响应序列
+func DecodeSteps(raw json.RawMessage) ([]Step, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var steps []Step
+	if err := json.Unmarshal(raw, &steps); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
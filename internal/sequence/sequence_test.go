@@ -0,0 +1,79 @@
+package sequence
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	steps := []Step{{StatusCode: 200}, {StatusCode: 201}, {StatusCode: 202}}
+	tests := []struct {
+		mode string
+		// want 第 i 次调用选出的状态码，0 表示没有响应
+		want []int
+	}{
+		{mode: ModeCycle, want: []int{200, 201, 202, 200, 201, 202, 200}},
+		{mode: "", want: []int{200, 201, 202, 200, 201, 202, 200}},
+		{mode: ModeStick, want: []int{200, 201, 202, 202, 202, 202, 202}},
+		{mode: ModeFail, want: []int{200, 201, 202, 0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			for index, want := range tt.want {
+				step, ok := Select(steps, tt.mode, int64(index))
+				got := 0
+				if ok {
+					got = step.StatusCode
+				}
+				if got != want {
+					t.Fatalf("Select(call %d) = %d, want %d", index, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectWithoutSteps(t *testing.T) {
+	for _, mode := range []string{ModeCycle, ModeStick, ModeFail} {
+		if _, ok := Select(nil, mode, 0); ok {
+			t.Fatalf("Select on an empty sequence in mode %s must return false", mode)
+		}
+		if _, ok := Select([]Step{{}}, mode, -1); ok {
+			t.Fatalf("Select with a negative index in mode %s must return false", mode)
+		}
+	}
+}
+
+func TestDecodeSteps(t *testing.T) {
+	body := "second"
+	tests := []struct {
+		name    string
+		raw     string
+		want    []Step
+		wantErr bool
+	}{
+		{name: "empty", raw: ""},
+		{name: "null", raw: "null"},
+		{name: "empty list", raw: "[]", want: []Step{}},
+		{
+			name: "steps",
+			raw:  `[{"status_code":500},{"body":"second","headers":{"X-Step":"2"},"content_type":"text/plain"}]`,
+			want: []Step{{StatusCode: 500}, {Body: &body, Headers: map[string]string{"X-Step": "2"}, ContentType: "text/plain"}},
+		},
+		{name: "not a list", raw: `{"status_code":500}`, wantErr: true},
+		{name: "invalid json", raw: `[{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := DecodeSteps(json.RawMessage(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeSteps = %v, want error=%v", err, tt.wantErr)
+			}
+			got, _ := json.Marshal(steps)
+			want, _ := json.Marshal(tt.want)
+			if string(got) != string(want) {
+				t.Fatalf("DecodeSteps = %s, want %s", got, want)
+			}
+		})
+	}
+}
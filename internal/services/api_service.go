@@ -1,39 +1,409 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	uuid2 "github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"kite/internal/api/payloads"
 	KiteError "kite/internal/errors"
+	"kite/internal/matching"
+	"kite/internal/models"
 	"kite/internal/repositories"
+	"kite/internal/routing"
+	"kite/internal/sequence"
+	"kite/internal/simulation"
+	"kite/internal/state"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 )
 
 type ApiService interface {
-	Create(ctx echo.Context, payload payloads.MockApiPayload) error
-	Request(ctx echo.Context, uid string, path string, method string) (contextType *string, responseBody *string, err error)
+	Create(ctx echo.Context, payload payloads.MockApiPayload) (string, error)
+	Get(ctx echo.Context, uuid string) (*models.Api, error)
+	List(ctx echo.Context, query payloads.MockApiQuery) ([]*models.Api, int64, error)
+	Update(ctx echo.Context, uuid string, payload payloads.MockApiPayload) (*models.Api, error)
+	Patch(ctx echo.Context, uuid string, payload payloads.MockApiPatchPayload) (*models.Api, error)
+	Delete(ctx echo.Context, uuid string) error
+	Clone(ctx echo.Context, uuid string, payload payloads.MockApiClonePayload) (*models.Api, error)
+	Request(ctx echo.Context, uid string, path string, method string) (*MockMatch, error)
+	Render(ctx echo.Context, match *MockMatch) (*MockResponse, error)
+	AllowedMethods(ctx echo.Context, uid string, path string) ([]string, error)
+	Calls(ctx echo.Context, uuid string) (int64, error)
+	ResetCalls(ctx echo.Context, uuid string) error
+}
+
+// MockMatch 请求命中的 mock、从路径中捕获的参数以及请求快照
+type MockMatch struct {
+	Api        *models.Api
+	Path       string
+	PathParams map[string]string
+	Request    *matching.Request
+	// CallIndex 本次是该 mock 的第几次调用（从 0 开始），用于响应序列
+	CallIndex int64
 }
 
 type apiService struct {
-	repo repositories.ApiRepository
+	repo       repositories.ApiRepository
+	routes     *routing.Table
+	namespaces NamespaceService
+	workspaces WorkspaceService
+	state      state.Store
 }
 
-func NewApiService(repo repositories.ApiRepository) ApiService {
-	return &apiService{repo}
+func NewApiService(repo repositories.ApiRepository, routes *routing.Table, namespaces NamespaceService, workspaces WorkspaceService, state state.Store) ApiService {
+	return &apiService{repo, routes, namespaces, workspaces, state}
 }
 
-func (s *apiService) Create(ctx echo.Context, payload payloads.MockApiPayload) error {
+func (s *apiService) Create(ctx echo.Context, payload payloads.MockApiPayload) (string, error) {
+	if err := validatePayload(payload); err != nil {
+		return "", err
+	}
+	if err := s.workspaces.AuthorizeNamespace(ctx, payload.UserId); err != nil {
+		return "", err
+	}
 	uuid := uuid2.NewString()
 	err := s.repo.CreateApi(ctx.Request().Context(), payload, uuid)
 	if err != nil {
-		return KiteError.New(KiteError.ApiCreateError, err)
+		return "", KiteError.New(KiteError.ApiCreateError, err)
+	}
+	return uuid, nil
+}
+
+func (s *apiService) Get(ctx echo.Context, uuid string) (*models.Api, error) {
+	api, err := s.repo.GetApiByUuid(ctx.Request().Context(), uuid)
+	if err != nil {
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, KiteError.New(KiteError.ApiNotFoundError, err).WithDetail(uuid)
+		}
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return api, nil
+}
+
+func (s *apiService) List(ctx echo.Context, query payloads.MockApiQuery) ([]*models.Api, int64, error) {
+	query.Normalize()
+	apis, total, err := s.repo.ListApis(ctx.Request().Context(), repositories.ApiFilter{
+		UserId:     query.UserId,
+		Method:     query.Method,
+		PathPrefix: query.PathPrefix,
+		Tag:        query.Tag,
+		Offset:     query.Offset(),
+		Limit:      query.PageSize,
+	})
+	if err != nil {
+		return nil, 0, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return apis, total, nil
+}
+
+func (s *apiService) Update(ctx echo.Context, uuid string, payload payloads.MockApiPayload) (*models.Api, error) {
+	api, err := s.Get(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if err := payload.ApplyTo(api); err != nil {
+		return nil, KiteError.New(KiteError.MarshalError, err)
+	}
+	if err := validateApi(api); err != nil {
+		return nil, err
+	}
+	if err := s.workspaces.AuthorizeNamespace(ctx, api.UserId); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateApi(ctx.Request().Context(), api); err != nil {
+		return nil, KiteError.New(KiteError.ApiUpdateError, err)
+	}
+	return api, nil
+}
+
+func (s *apiService) Patch(ctx echo.Context, uuid string, payload payloads.MockApiPatchPayload) (*models.Api, error) {
+	api, err := s.Get(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if err := payload.ApplyTo(api); err != nil {
+		return nil, KiteError.New(KiteError.MarshalError, err)
+	}
+	if err := validateApi(api); err != nil {
+		return nil, err
+	}
+	if err := s.workspaces.AuthorizeNamespace(ctx, api.UserId); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateApi(ctx.Request().Context(), api); err != nil {
+		return nil, KiteError.New(KiteError.ApiUpdateError, err)
+	}
+	return api, nil
+}
+
+func (s *apiService) Delete(ctx echo.Context, uuid string) error {
+	err := s.repo.DeleteApiByUuid(ctx.Request().Context(), uuid)
+	if err != nil {
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return KiteError.New(KiteError.ApiNotFoundError, err).WithDetail(uuid)
+		}
+		return KiteError.New(KiteError.ApiDeleteError, err)
+	}
+	s.state.ResetCalls(uuid)
+	return nil
+}
+
+func (s *apiService) Clone(ctx echo.Context, uuid string, payload payloads.MockApiClonePayload) (*models.Api, error) {
+	source, err := s.Get(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	// 复制除主键、uuid 和时间戳以外的全部字段
+	clone := *source
+	clone.Id = 0
+	clone.Uuid = uuid2.NewString()
+	clone.CreatedAt = time.Time{}
+	clone.UpdatedAt = time.Time{}
+	if payload.UserId != nil {
+		clone.UserId = *payload.UserId
+	}
+	if payload.Path != nil {
+		clone.Path = *payload.Path
+	}
+	if payload.Method != nil {
+		clone.Method = strings.ToUpper(*payload.Method)
+	}
+	if err := validateApi(&clone); err != nil {
+		return nil, err
+	}
+	if err := s.workspaces.AuthorizeNamespace(ctx, clone.UserId); err != nil {
+		return nil, err
+	}
+	if err := s.repo.InsertApi(ctx.Request().Context(), &clone); err != nil {
+		return nil, KiteError.New(KiteError.ApiCreateError, err)
+	}
+	return &clone, nil
+}
+
+func (s *apiService) Request(ctx echo.Context, uid string, path string, method string) (*MockMatch, error) {
+	req, err := matching.NewRequest(ctx.Request())
+	if err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.BadRequestError, "Failed to read request body", err)
+	}
+	if err := s.namespaces.ValidateRequest(ctx, uid, method, path, req); err != nil {
+		return nil, err
+	}
+	match, err := s.findMatch(ctx, uid, path, method, req)
+	if err != nil {
+		return nil, err
+	}
+	// 没有单独配置 HEAD 时使用 GET 的 mock
+	if match == nil && method == http.MethodHead {
+		match, err = s.findMatch(ctx, uid, path, http.MethodGet, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if match == nil {
+		return nil, s.notMatched(ctx, uid, path, method, req, repositories.ErrRecordNotFound)
 	}
+	match.Path = path
+	match.Request = req
+	match.CallIndex = s.state.IncrementCalls(match.Api.Uuid) - 1
+	// 命中后推进场景状态，下一次请求按新状态匹配
+	if match.Api.ScenarioName != "" && match.Api.NewState != "" {
+		s.state.SetScenarioState(uid, match.Api.ScenarioName, match.Api.NewState)
+	}
+	return match, nil
+}
+
+// Calls 查询 mock 被调用的次数
+func (s *apiService) Calls(ctx echo.Context, uuid string) (int64, error) {
+	if _, err := s.Get(ctx, uuid); err != nil {
+		return 0, err
+	}
+	return s.state.Calls(uuid), nil
+}
+
+// ResetCalls 清零调用次数，响应序列会从第一个响应重新开始
+func (s *apiService) ResetCalls(ctx echo.Context, uuid string) error {
+	if _, err := s.Get(ctx, uuid); err != nil {
+		return err
+	}
+	s.state.ResetCalls(uuid)
 	return nil
 }
 
-func (s *apiService) Request(ctx echo.Context, uid string, path string, method string) (contextType *string, responseBody *string, err error) {
-	api, err := s.repo.QueryApiWithUidAndPathAndMethod(ctx.Request().Context(), uid, path, method)
+func (s *apiService) findMatch(ctx echo.Context, uid string, path string, method string, req *matching.Request) (*MockMatch, error) {
+	apis, err := s.routes.Lookup(ctx.Request().Context(), uid, method, path)
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return selectMock(apis, path, req, s.scenarioLookup(uid)), nil
+}
+
+// scenarioLookup 返回查询命名空间下场景当前状态的函数
+func (s *apiService) scenarioLookup(uid string) scenarioLookup {
+	return func(name string) string {
+		return s.state.ScenarioState(uid, name)
+	}
+}
+
+// AllowedMethods 返回命名空间下路径能匹配上的 mock 所使用的方法，用于生成 OPTIONS 响应
+func (s *apiService) AllowedMethods(ctx echo.Context, uid string, path string) ([]string, error) {
+	apis, _, err := s.repo.ListApis(ctx.Request().Context(), repositories.ApiFilter{UserId: uid})
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	seen := make(map[string]bool)
+	methods := make([]string, 0)
+	add := func(method string) {
+		if !seen[method] {
+			seen[method] = true
+			methods = append(methods, method)
+		}
+	}
+	for _, api := range apis {
+		pattern, err := matching.CompilePath(api.Path)
+		if err != nil {
+			continue
+		}
+		if _, ok := pattern.Match(path); !ok {
+			continue
+		}
+		add(api.Method)
+		if api.Method == http.MethodGet {
+			add(http.MethodHead)
+		}
+	}
+	if len(methods) > 0 {
+		add(http.MethodOptions)
+	}
+	sort.Strings(methods)
+	return methods, nil
+}
+
+// scenarioLookup 根据场景名称返回场景的当前状态
+type scenarioLookup func(name string) string
+
+// inRequiredState 判断 mock 要求的场景状态是否满足，未要求状态的 mock 总是满足
+func inRequiredState(api *models.Api, lookup scenarioLookup) bool {
+	if api.ScenarioName == "" || api.RequiredState == "" {
+		return true
+	}
+	return lookup(api.ScenarioName) == api.RequiredState
+}
+
+// selectMock 在候选 mock 中选出路径、匹配条件和场景状态都满足的一个。
+// 依次比较：优先级、路径具体程度、匹配条件数量（要求场景状态算一个条件），仍相同时取最早创建的
+func selectMock(apis []*models.Api, path string, req *matching.Request, lookup scenarioLookup) *MockMatch {
+	var (
+		best         *MockMatch
+		bestPattern  *matching.PathPattern
+		bestMatchers int
+	)
+	for _, api := range apis {
+		pattern, err := matching.CompilePath(api.Path)
+		if err != nil {
+			continue
+		}
+		params, ok := pattern.Match(path)
+		if !ok {
+			continue
+		}
+		matchers, err := matching.DecodeRequestMatchers(api.RequestMatchers)
+		if err != nil {
+			continue
+		}
+		if ok, _ := matchers.Match(req); !ok {
+			continue
+		}
+		if !inRequiredState(api, lookup) {
+			continue
+		}
+		conditions := matchers.Count()
+		if api.RequiredState != "" {
+			conditions++
+		}
+		if best == nil || isMoreSpecific(api, pattern, conditions, best.Api, bestPattern, bestMatchers) {
+			best = &MockMatch{Api: api, PathParams: params}
+			bestPattern = pattern
+			bestMatchers = conditions
+		}
+	}
+	return best
+}
+
+func isMoreSpecific(api *models.Api, pattern *matching.PathPattern, matchers int, other *models.Api, otherPattern *matching.PathPattern, otherMatchers int) bool {
+	if api.Priority != other.Priority {
+		return api.Priority > other.Priority
+	}
+	if diff := matching.Compare(pattern, otherPattern); diff != 0 {
+		return diff > 0
+	}
+	return matchers > otherMatchers
+}
+
+// validatePayload 校验创建请求中的路径模式和匹配条件
+func validatePayload(payload payloads.MockApiPayload) error {
+	api := &models.Api{}
+	if err := payload.ApplyTo(api); err != nil {
+		return KiteError.New(KiteError.MarshalError, err)
+	}
+	return validateApi(api)
+}
+
+// validateApi 校验 mock 的路径模式、匹配条件、延迟和故障配置以及模板是否合法
+func validateApi(api *models.Api) error {
+	if _, err := matching.ParsePath(api.Path); err != nil {
+		return KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+	}
+	matchers, err := matching.DecodeRequestMatchers(api.RequestMatchers)
+	if err != nil {
+		return KiteError.New(KiteError.UnmarshalError, err)
+	}
+	if err := matchers.Validate(); err != nil {
+		return KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+	}
+	delay, err := simulation.DecodeDelay(api.Delay)
+	if err != nil {
+		return KiteError.New(KiteError.UnmarshalError, err)
+	}
+	if err := delay.Validate(); err != nil {
+		return KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+	}
+	fault, err := simulation.DecodeFault(api.Fault)
+	if err != nil {
+		return KiteError.New(KiteError.UnmarshalError, err)
+	}
+	if err := fault.Validate(); err != nil {
+		return KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+	}
+	if _, err := sequence.DecodeSteps(api.Responses); err != nil {
+		return KiteError.New(KiteError.UnmarshalError, err)
+	}
+	if api.ScenarioName == "" && (api.RequiredState != "" || api.NewState != "") {
+		return KiteError.NewWithMessage(KiteError.ValidationError, "scenario_name is required when required_state or new_state is set", nil)
+	}
+	return validateTemplates(api)
+}
+
+// notMatched 构造未命中错误，附带最接近的候选 mock，状态码取自命名空间配置
+func (s *apiService) notMatched(ctx echo.Context, uid string, path string, method string, req *matching.Request, cause error) error {
+	namespace, err := s.namespaces.Get(ctx, uid)
 	if err != nil {
-		return nil, nil, KiteError.New(KiteError.InternalServerError, err)
+		return err
 	}
-	return &api.ContentType, &api.ResponseBody, nil
+	apis, _, err := s.repo.ListApis(ctx.Request().Context(), repositories.ApiFilter{UserId: uid})
+	if err != nil {
+		return KiteError.New(KiteError.DatabaseError, err)
+	}
+	message := fmt.Sprintf("No mock matched %s %s in namespace %s", method, path, uid)
+
+	return KiteError.NewWithMessage(KiteError.MockNotMatchedError, message, cause).
+		WithHTTPStatus(namespace.NotFoundStatus).
+		WithData(payloads.MockMissDetails{
+			Uid:        uid,
+			Method:     method,
+			Path:       path,
+			Candidates: findCandidates(apis, path, method, req, s.scenarioLookup(uid)),
+		})
 }
@@ -0,0 +1,400 @@
+package services
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/configs"
+	KiteError "kite/internal/errors"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"kite/internal/routing"
+	"kite/internal/state"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// apiFixture 使用内存存储和开启的路由表创建的 ApiService，以及它依赖的命名空间配置
+type apiFixture struct {
+	ApiService
+	namespaces NamespaceService
+	scenarios  ScenarioService
+}
+
+func newApiFixture() *apiFixture {
+	apis := repositories.NewMemoryApiRepository()
+	namespaceRepo := repositories.NewMemoryNamespaceRepository()
+	routes := routing.NewTable(apis, &configs.RoutingConfig{Enabled: true})
+	namespaces := NewNamespaceService(namespaceRepo)
+	workspaces := NewWorkspaceService(repositories.NewMemoryWorkspaceRepository(), routes, namespaceRepo)
+	store := state.NewMemoryStore()
+	return &apiFixture{
+		ApiService: NewApiService(routes, routes, namespaces, workspaces, store),
+		namespaces: namespaces,
+		scenarios:  NewScenarioService(routes, store),
+	}
+}
+
+func apiContext(method string, target string, body string) echo.Context {
+	return echo.New().NewContext(httptest.NewRequest(method, target, strings.NewReader(body)), httptest.NewRecorder())
+}
+
+func mockPayload(uid string, method string, path string, body string) payloads.MockApiPayload {
+	return payloads.MockApiPayload{
+		UserId: uid, Path: path, Method: method, StatusCode: 200, ContentType: "text/plain", Charset: "utf-8",
+		ResponseHeaders: []payloads.Headers{}, ResponseBody: body,
+	}
+}
+
+// createMock 创建 mock 并返回 uuid
+func createMock(t *testing.T, service ApiService, payload payloads.MockApiPayload) string {
+	t.Helper()
+	uuid, err := service.Create(apiContext(http.MethodPost, "/", ""), payload)
+	if err != nil {
+		t.Fatalf("Create %s %s: %v", payload.Method, payload.Path, err)
+	}
+	return uuid
+}
+
+func assertErrorCode(t *testing.T, err error, code KiteError.ErrorCode) {
+	t.Helper()
+	appErr, ok := KiteError.IsAppError(err)
+	if !ok || appErr.Code != code {
+		t.Fatalf("err = %v, want code %v", err, code)
+	}
+}
+
+func testApi(uuid string, path string, priority int, matchers string) *models.Api {
+	api := &models.Api{Uuid: uuid, Method: http.MethodGet, Path: path, Priority: priority}
+	if matchers != "" {
+		api.RequestMatchers = json.RawMessage(matchers)
+	}
+	return api
+}
+
+func TestSelectMockPriority(t *testing.T) {
+	const vip = `{"headers":[{"name":"X-Tier","operator":"equals","value":"vip"}]}`
+	const vipAndQuery = `{"headers":[{"name":"X-Tier","operator":"equals","value":"vip"}],"query":[{"name":"debug","operator":"absent"}]}`
+	tests := []struct {
+		name   string
+		apis   []*models.Api
+		target string
+		header string
+		want   string
+	}{
+		{
+			name:   "higher priority wins over a more specific path",
+			apis:   []*models.Api{testApi("static", "/users/me", 0, ""), testApi("param", "/users/{id}", 10, "")},
+			target: "/users/me",
+			want:   "param",
+		},
+		{
+			name:   "static path beats parameter at equal priority",
+			apis:   []*models.Api{testApi("param", "/users/{id}", 0, ""), testApi("static", "/users/me", 0, "")},
+			target: "/users/me",
+			want:   "static",
+		},
+		{
+			name:   "parameter beats wildcard at equal priority",
+			apis:   []*models.Api{testApi("wild", "/users/**", 0, ""), testApi("param", "/users/{id}", 0, "")},
+			target: "/users/7",
+			want:   "param",
+		},
+		{
+			name:   "more matchers win when path and priority tie",
+			apis:   []*models.Api{testApi("plain", "/items", 0, ""), testApi("vip", "/items", 0, vip), testApi("vip-no-debug", "/items", 0, vipAndQuery)},
+			target: "/items",
+			header: "vip",
+			want:   "vip-no-debug",
+		},
+		{
+			name:   "unmet matchers fall back to a plain mock",
+			apis:   []*models.Api{testApi("vip", "/items", 5, vip), testApi("plain", "/items", 0, "")},
+			target: "/items",
+			header: "basic",
+			want:   "plain",
+		},
+		{
+			name:   "earliest mock wins a full tie",
+			apis:   []*models.Api{testApi("first", "/items", 0, ""), testApi("second", "/items", 0, "")},
+			target: "/items",
+			want:   "first",
+		},
+		{
+			name:   "no candidate matches",
+			apis:   []*models.Api{testApi("vip", "/items", 0, vip)},
+			target: "/items",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set("X-Tier", tt.header)
+			}
+			req, err := matching.NewRequest(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			match := selectMock(tt.apis, r.URL.Path, req, func(string) string { return "" })
+			got := ""
+			if match != nil {
+				got = match.Api.Uuid
+			}
+			if got != tt.want {
+				t.Fatalf("selectMock = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectMockScenarioState(t *testing.T) {
+	started := testApi("started", "/cart", 0, "")
+	started.ScenarioName, started.RequiredState = "checkout", "started"
+	fallback := testApi("fallback", "/cart", 0, "")
+	apis := []*models.Api{fallback, started}
+	req, _ := matching.NewRequest(httptest.NewRequest(http.MethodGet, "/cart", nil))
+
+	state := "Started"
+	lookup := func(string) string { return state }
+	if match := selectMock(apis, "/cart", req, lookup); match == nil || match.Api.Uuid != "fallback" {
+		t.Fatalf("mock with an unmet required state must not be selected, got %+v", match)
+	}
+	state = "started"
+	if match := selectMock(apis, "/cart", req, lookup); match == nil || match.Api.Uuid != "started" {
+		t.Fatalf("required state counts as an extra condition, got %+v", match)
+	}
+}
+
+func TestValidateApiSimulation(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay string
+		fault string
+		valid bool
+	}{
+		{name: "no simulation", valid: true},
+		{name: "valid delay and fault", delay: `{"distribution":"fixed","fixed_ms":5}`, fault: `{"type":"hang","percentage":10}`, valid: true},
+		{name: "delay without distribution", delay: `{"fixed_ms":5}`},
+		{name: "fault with unknown type", fault: `{"type":"explode"}`},
+		{name: "fault percentage out of range", fault: `{"type":"hang","percentage":200}`},
+		{name: "fault is not an object", fault: `"hang"`},
+		{name: "random data within the limit", fault: `{"type":"random_data","random_bytes":1048576}`, valid: true},
+		{name: "random data above the limit", fault: `{"type":"random_data","random_bytes":1073741824}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := testApi("a", "/items", 0, "")
+			if tt.delay != "" {
+				api.Delay = json.RawMessage(tt.delay)
+			}
+			if tt.fault != "" {
+				api.Fault = json.RawMessage(tt.fault)
+			}
+			if err := validateApi(api); (err == nil) != tt.valid {
+				t.Fatalf("validateApi = %v, want valid=%v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestListApis(t *testing.T) {
+	service := newApiFixture()
+	for _, seed := range []struct{ uid, method, path string }{
+		{"a", "GET", "/users"},
+		{"a", "GET", "/users/{id}"},
+		{"a", "POST", "/users"},
+		{"a", "GET", "/orders"},
+		{"b", "GET", "/users"},
+	} {
+		createMock(t, service, mockPayload(seed.uid, seed.method, seed.path, "ok"))
+	}
+	tests := []struct {
+		name      string
+		query     payloads.MockApiQuery
+		wantTotal int64
+		wantPaths []string
+	}{
+		{name: "everything", wantTotal: 5},
+		{name: "by namespace", query: payloads.MockApiQuery{UserId: "a"}, wantTotal: 4},
+		{name: "by method is case-insensitive", query: payloads.MockApiQuery{UserId: "a", Method: "post"}, wantTotal: 1, wantPaths: []string{"/users"}},
+		{name: "by path prefix", query: payloads.MockApiQuery{UserId: "a", Method: "GET", PathPrefix: "/users"}, wantTotal: 2, wantPaths: []string{"/users", "/users/{id}"}},
+		{name: "first page", query: payloads.MockApiQuery{UserId: "a", PageSize: 3}, wantTotal: 4, wantPaths: []string{"/users", "/users/{id}", "/users"}},
+		{name: "last page", query: payloads.MockApiQuery{UserId: "a", Page: 2, PageSize: 3}, wantTotal: 4, wantPaths: []string{"/orders"}},
+		{name: "page past the end", query: payloads.MockApiQuery{UserId: "a", Page: 3, PageSize: 3}, wantTotal: 4, wantPaths: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apis, total, err := service.List(apiContext(http.MethodGet, "/", ""), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.wantTotal {
+				t.Fatalf("total = %d, want %d", total, tt.wantTotal)
+			}
+			if tt.wantPaths == nil {
+				return
+			}
+			paths := make([]string, 0, len(apis))
+			for _, api := range apis {
+				paths = append(paths, api.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Fatalf("paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestManageApi(t *testing.T) {
+	service := newApiFixture()
+	ctx := apiContext(http.MethodGet, "/", "")
+	uuid := createMock(t, service, mockPayload("a", "get", "/users", "v1"))
+
+	api, err := service.Get(ctx, uuid)
+	if err != nil || api.Method != http.MethodGet || api.ResponseBody != "v1" {
+		t.Fatalf("Get = %+v, %v", api, err)
+	}
+
+	replaced := mockPayload("a", "PUT", "/users", "v2")
+	if api, err = service.Update(ctx, uuid, replaced); err != nil || api.Method != http.MethodPut || api.ResponseBody != "v2" {
+		t.Fatalf("Update = %+v, %v", api, err)
+	}
+	body := "v3"
+	if api, err = service.Patch(ctx, uuid, payloads.MockApiPatchPayload{ResponseBody: &body}); err != nil || api.Method != http.MethodPut || api.ResponseBody != "v3" {
+		t.Fatalf("Patch must change only the given fields, got %+v, %v", api, err)
+	}
+	invalid := "/users/**/posts"
+	_, err = service.Patch(ctx, uuid, payloads.MockApiPatchPayload{Path: &invalid})
+	assertErrorCode(t, err, KiteError.ValidationError)
+
+	target := "b"
+	clone, err := service.Clone(ctx, uuid, payloads.MockApiClonePayload{UserId: &target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clone.Uuid == uuid || clone.UserId != "b" || clone.Path != "/users" || clone.ResponseBody != "v3" {
+		t.Fatalf("Clone = %+v, want a copy in namespace b with a new uuid", clone)
+	}
+
+	if err := service.Delete(ctx, uuid); err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.Get(ctx, uuid)
+	assertErrorCode(t, err, KiteError.ApiNotFoundError)
+	assertErrorCode(t, service.Delete(ctx, uuid), KiteError.ApiNotFoundError)
+	_, err = service.Update(ctx, uuid, replaced)
+	assertErrorCode(t, err, KiteError.ApiNotFoundError)
+	_, err = service.Clone(ctx, uuid, payloads.MockApiClonePayload{})
+	assertErrorCode(t, err, KiteError.ApiNotFoundError)
+	if _, err := service.Get(ctx, clone.Uuid); err != nil {
+		t.Fatalf("deleting the source must keep the clone: %v", err)
+	}
+}
+
+func TestRequestNotMatched(t *testing.T) {
+	service := newApiFixture()
+	vip := mockPayload("a", "GET", "/users/{id}", "vip")
+	vip.RequestMatchers = &matching.RequestMatchers{Headers: []matching.FieldMatcher{{Name: "X-Tier", Operator: "equals", Value: "vip"}}}
+	uuids := map[string]string{
+		"vip":   createMock(t, service, vip),
+		"post":  createMock(t, service, mockPayload("a", "POST", "/users/{id}", "created")),
+		"typo":  createMock(t, service, mockPayload("a", "GET", "/usres/7", "typo")),
+		"other": createMock(t, service, mockPayload("a", "GET", "/orders", "orders")),
+		"b":     createMock(t, service, mockPayload("b", "GET", "/users/7", "other namespace")),
+	}
+	tests := []struct {
+		name           string
+		uid            string
+		method         string
+		path           string
+		notFoundStatus int
+		wantStatus     int
+		wantReasons    map[string]string
+	}{
+		{
+			name:        "closest candidates with reasons",
+			uid:         "a",
+			method:      http.MethodGet,
+			path:        "/users/7",
+			wantStatus:  http.StatusNotFound,
+			wantReasons: map[string]string{uuids["vip"]: ReasonMatcherMismatch, uuids["post"]: ReasonMethodMismatch, uuids["typo"]: ReasonPathMismatch},
+		},
+		{
+			name:           "status configured on the namespace",
+			uid:            "a",
+			method:         http.MethodDelete,
+			path:           "/orders",
+			notFoundStatus: http.StatusNotImplemented,
+			wantStatus:     http.StatusNotImplemented,
+			wantReasons:    map[string]string{uuids["other"]: ReasonMethodMismatch},
+		},
+		{
+			name:        "unknown namespace has no candidates",
+			uid:         "missing",
+			method:      http.MethodGet,
+			path:        "/users/7",
+			wantStatus:  http.StatusNotFound,
+			wantReasons: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.notFoundStatus != 0 {
+				if _, err := service.namespaces.Update(apiContext(http.MethodPut, "/", ""), tt.uid, payloads.NamespacePayload{NotFoundStatus: tt.notFoundStatus}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := service.Request(apiContext(tt.method, tt.path, ""), tt.uid, tt.path, tt.method)
+			assertErrorCode(t, err, KiteError.MockNotMatchedError)
+			appErr, _ := KiteError.IsAppError(err)
+			if appErr.HTTPStatus != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", appErr.HTTPStatus, tt.wantStatus)
+			}
+			details, ok := appErr.Data.(payloads.MockMissDetails)
+			if !ok || details.Uid != tt.uid || details.Method != tt.method || details.Path != tt.path {
+				t.Fatalf("details = %+v", appErr.Data)
+			}
+			reasons := make(map[string]string, len(details.Candidates))
+			for _, candidate := range details.Candidates {
+				reasons[candidate.Uuid] = candidate.Reason
+			}
+			if len(reasons) != len(tt.wantReasons) {
+				t.Fatalf("candidates = %+v, want %v", details.Candidates, tt.wantReasons)
+			}
+			for uuid, reason := range tt.wantReasons {
+				if reasons[uuid] != reason {
+					t.Fatalf("candidate %s reason = %q, want %q (all %+v)", uuid, reasons[uuid], reason, details.Candidates)
+				}
+			}
+		})
+	}
+
+	match, err := service.Request(apiContext(http.MethodGet, "/users/7", ""), "b", "/users/7", http.MethodGet)
+	if err != nil || match.Api.Uuid != uuids["b"] {
+		t.Fatalf("a matching request must not report a miss, got %+v, %v", match, err)
+	}
+}
+
+func TestFindCandidatesOrder(t *testing.T) {
+	apis := []*models.Api{
+		{Uuid: "far", Method: http.MethodGet, Path: "/completely/different"},
+		{Uuid: "typo", Method: http.MethodGet, Path: "/itemz"},
+		{Uuid: "typo-post", Method: http.MethodPost, Path: "/itemz"},
+		{Uuid: "method", Method: http.MethodPost, Path: "/items"},
+	}
+	req, _ := matching.NewRequest(httptest.NewRequest(http.MethodGet, "/items", nil))
+	candidates := findCandidates(apis, "/items", http.MethodGet, req, func(string) string { return "" })
+	got := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		got = append(got, candidate.Uuid+":"+candidate.Reason)
+	}
+	want := "method:method_mismatch,typo:path_mismatch,typo-post:path_and_method_mismatch"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("candidates = %v, want %s", got, want)
+	}
+}
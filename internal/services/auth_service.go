@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"io"
+	"kite/internal/auth"
+	"kite/internal/configs"
+	KiteError "kite/internal/errors"
+	"kite/pkg/utils/security"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultHMACMaxSkew 未配置时签名时间允许的最大偏差
+	defaultHMACMaxSkew = 300 * time.Second
+	// defaultHMACMaxBodyBytes 未配置时签名请求的请求体长度上限
+	defaultHMACMaxBodyBytes = 10 << 20
+)
+
+// AuthService 认证管理接口的调用方，凭证有效时返回调用方的身份与角色
+type AuthService interface {
+	// Authenticate 根据 Authorization 请求头认证，支持 Bearer（静态 Token、工作区 API Key 或 JWT）与 HMAC-SHA256 签名
+	Authenticate(ctx echo.Context) (*auth.Principal, error)
+	// AuthenticateApiKey 认证工作区的 API Key，调用方限定在 Key 所属的工作区内
+	AuthenticateApiKey(ctx echo.Context, key string) (*auth.Principal, error)
+}
+
+type authService struct {
+	cfg        *configs.AuthConfig
+	jwt        *auth.JWTVerifier
+	replays    *auth.ReplayGuard
+	workspaces WorkspaceService
+	now        func() time.Time
+}
+
+// NewAuthService 开启认证时加载 JWKS 文件，文件无效时返回错误
+func NewAuthService(cfg *configs.AuthConfig, workspaces WorkspaceService) (AuthService, error) {
+	s := &authService{cfg: cfg, replays: auth.NewReplayGuard(), workspaces: workspaces, now: time.Now}
+	if cfg.Enabled {
+		verifier, err := auth.NewJWTVerifier(&cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		s.jwt = verifier
+	}
+	return s, nil
+}
+
+func (s *authService) Authenticate(ctx echo.Context) (*auth.Principal, error) {
+	header := ctx.Request().Header.Get(echo.HeaderAuthorization)
+	if header == "" {
+		return nil, unauthorized("missing credentials")
+	}
+	scheme, credentials, _ := strings.Cut(header, " ")
+	credentials = strings.TrimSpace(credentials)
+	switch {
+	case strings.EqualFold(scheme, "Bearer"):
+		return s.authenticateBearer(ctx, credentials)
+	case strings.EqualFold(scheme, auth.HMACScheme):
+		return s.authenticateHMAC(ctx, credentials)
+	default:
+		return nil, unauthorized("unsupported authorization scheme " + scheme)
+	}
+}
+
+// authenticateBearer 依次尝试静态 Token、工作区 API Key 与 JWT
+func (s *authService) authenticateBearer(ctx echo.Context, token string) (*auth.Principal, error) {
+	if token == "" {
+		return nil, unauthorized("empty bearer token")
+	}
+	// 比较摘要使每次比较的耗时与 Token 内容无关
+	digest := sha256.Sum256([]byte(token))
+	for _, candidate := range s.cfg.Tokens {
+		expected := sha256.Sum256([]byte(candidate.Token))
+		if subtle.ConstantTimeCompare(digest[:], expected[:]) == 1 {
+			return &auth.Principal{Subject: candidate.Name, Role: auth.Role(candidate.Role), Method: auth.MethodToken, WorkspaceId: candidate.Workspace}, nil
+		}
+	}
+	if strings.HasPrefix(token, security.ApiKeyPrefix) {
+		return s.AuthenticateApiKey(ctx, token)
+	}
+	if strings.Count(token, ".") == 2 && s.jwt != nil && s.jwt.Configured() {
+		return s.authenticateJWT(token)
+	}
+	return nil, unauthorized("invalid bearer token")
+}
+
+func (s *authService) authenticateJWT(token string) (*auth.Principal, error) {
+	claims, err := s.jwt.Verify(token)
+	if err != nil {
+		return nil, KiteError.New(KiteError.UnauthorizedError, err).WithDetail(err.Error())
+	}
+	role, ok := s.jwt.Role(claims)
+	if !ok {
+		return nil, KiteError.New(KiteError.ForbiddenError, nil).WithDetail("jwt carries no known role")
+	}
+	return &auth.Principal{Subject: claims.Subject(), Role: role, Method: auth.MethodJWT, WorkspaceId: s.jwt.Workspace(claims)}, nil
+}
+
+// authenticateHMAC 校验签名时需要读取请求体，读取后放回请求供处理器绑定。
+// 签名在时间窗口内只能使用一次，签名时间不早于 now-maxSkew，记录保留 2*maxSkew 即可覆盖签名的整个有效期
+func (s *authService) authenticateHMAC(ctx echo.Context, credentials string) (*auth.Principal, error) {
+	keyId, signature, err := auth.ParseHMACCredentials(credentials)
+	if err != nil {
+		return nil, KiteError.New(KiteError.UnauthorizedError, err).WithDetail(err.Error())
+	}
+	var key *configs.HMACKeyConfig
+	for i := range s.cfg.HMACKeys {
+		if s.cfg.HMACKeys[i].KeyId == keyId {
+			key = &s.cfg.HMACKeys[i]
+			break
+		}
+	}
+	if key == nil {
+		return nil, unauthorized("unknown hmac key " + keyId)
+	}
+	req := ctx.Request()
+	timestamp := req.Header.Get(auth.TimestampHeader)
+	maxSkew := defaultHMACMaxSkew
+	if s.cfg.HMACMaxSkew > 0 {
+		maxSkew = time.Duration(s.cfg.HMACMaxSkew) * time.Second
+	}
+	if err := auth.CheckTimestamp(timestamp, s.now(), maxSkew); err != nil {
+		return nil, KiteError.New(KiteError.UnauthorizedError, err).WithDetail(err.Error())
+	}
+	// 认证之前读取请求体，限制长度避免未认证的调用方占用内存
+	maxBodyBytes := int64(defaultHMACMaxBodyBytes)
+	if s.cfg.HMACMaxBodyBytes > 0 {
+		maxBodyBytes = int64(s.cfg.HMACMaxBodyBytes)
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Response(), req.Body, maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, KiteError.New(KiteError.BadRequestError, err).
+				WithHTTPStatus(http.StatusRequestEntityTooLarge).
+				WithDetail(fmt.Sprintf("signed request body exceeds %d bytes", maxBodyBytes))
+		}
+		return nil, KiteError.New(KiteError.BadRequestError, err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if !auth.VerifySignature(key.Secret, signature, req.Method, req.URL.RequestURI(), timestamp, body) {
+		return nil, unauthorized("invalid request signature")
+	}
+	now := s.now()
+	if err := s.replays.Use(key.KeyId, signature, now.Add(2*maxSkew), now); err != nil {
+		return nil, KiteError.New(KiteError.UnauthorizedError, err).WithDetail(err.Error())
+	}
+	return &auth.Principal{Subject: key.KeyId, Role: auth.Role(key.Role), Method: auth.MethodHMAC, WorkspaceId: key.Workspace}, nil
+}
+
+func (s *authService) AuthenticateApiKey(ctx echo.Context, key string) (*auth.Principal, error) {
+	workspace, err := s.workspaces.Authenticate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	role := auth.RoleEditor
+	if s.cfg.ApiKeyRole != "" {
+		role = auth.Role(s.cfg.ApiKeyRole)
+	}
+	return &auth.Principal{Subject: workspace.Name, Role: role, Method: auth.MethodApiKey, WorkspaceId: workspace.Uuid}, nil
+}
+
+func unauthorized(detail string) error {
+	return KiteError.New(KiteError.UnauthorizedError, nil).WithDetail(detail)
+}
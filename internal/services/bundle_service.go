@@ -0,0 +1,215 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/bundle"
+	KiteError "kite/internal/errors"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"kite/internal/tenant"
+)
+
+// BundleService 以可移植的 bundle 导出和导入 mock
+type BundleService interface {
+	Export(ctx echo.Context, query payloads.BundleExportQuery) (*bundle.Bundle, error)
+	Import(ctx echo.Context, b *bundle.Bundle, query payloads.BundleImportQuery) ([]payloads.BundleImportItem, error)
+}
+
+type bundleService struct {
+	repo       repositories.ApiRepository
+	workspaces WorkspaceService
+}
+
+func NewBundleService(repo repositories.ApiRepository, workspaces WorkspaceService) BundleService {
+	return &bundleService{repo, workspaces}
+}
+
+// Export 导出命名空间下或带有指定标签的全部 mock
+func (s *bundleService) Export(ctx echo.Context, query payloads.BundleExportQuery) (*bundle.Bundle, error) {
+	if query.UserId == "" && query.Tag == "" {
+		return nil, KiteError.NewWithMessage(KiteError.ValidationError, "either user_id or tag is required", nil)
+	}
+	apis, _, err := s.repo.ListApis(ctx.Request().Context(), repositories.ApiFilter{UserId: query.UserId, Tag: query.Tag})
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	b, err := bundle.New(apis)
+	if err != nil {
+		return nil, KiteError.New(KiteError.UnmarshalError, err)
+	}
+	return b, nil
+}
+
+// Import 导入 bundle 中的 mock。全部条目校验、命名空间授权和冲突处理都完成后才会写入，
+// 任何一条不通过时不会写入任何 mock；
+// 与已有 mock 的 uuid 相同，或者方法、路径、匹配条件和场景状态都相同时视为冲突，按 strategy 处理。
+// bundle 中的 uuid 已被其他命名空间使用时改用新的 uuid
+func (s *bundleService) Import(ctx echo.Context, b *bundle.Bundle, query payloads.BundleImportQuery) ([]payloads.BundleImportItem, error) {
+	query.Normalize()
+	apis := make([]*models.Api, 0, len(b.Mocks))
+	for i, mock := range b.Mocks {
+		api, err := bundleApi(mock, query.UserId)
+		if err != nil {
+			return nil, KiteError.NewWithMessage(KiteError.ImportError, fmt.Sprintf("mocks[%d]: %s", i, err.Error()), err)
+		}
+		apis = append(apis, api)
+	}
+
+	existing := make(map[string][]*models.Api)
+	used := make(map[string]bool)
+	items := make([]payloads.BundleImportItem, 0, len(apis))
+	writes := make([]bundleWrite, 0, len(apis))
+	for _, api := range apis {
+		current, ok := existing[api.UserId]
+		if !ok {
+			if err := s.workspaces.AuthorizeNamespace(ctx, api.UserId); err != nil {
+				return nil, err
+			}
+			list, _, err := s.repo.ListApis(ctx.Request().Context(), repositories.ApiFilter{UserId: api.UserId})
+			if err != nil {
+				return nil, KiteError.New(KiteError.DatabaseError, err)
+			}
+			current = list
+		}
+		item := payloads.BundleImportItem{UserId: api.UserId, Method: api.Method, Path: api.Path}
+		conflict := findConflict(current, api)
+		switch {
+		case conflict != nil && query.Strategy == payloads.ConflictSkip:
+			item.Uuid, item.Action, item.ConflictUuid = conflict.Uuid, payloads.BundleActionSkipped, conflict.Uuid
+			item.Reason = "conflicts with an existing mock"
+		case conflict != nil && query.Strategy == payloads.ConflictOverwrite:
+			item.Uuid, item.Action, item.ConflictUuid = conflict.Uuid, payloads.BundleActionOverwritten, conflict.Uuid
+			overwriteApi(conflict, api)
+			writes = append(writes, bundleWrite{api: conflict, update: true})
+		default:
+			item.Action = payloads.BundleActionCreated
+			if conflict != nil {
+				item.Action, item.ConflictUuid = payloads.BundleActionRenamed, conflict.Uuid
+				api.Uuid = ""
+			}
+			if err := s.assignUuid(ctx, api, used); err != nil {
+				return nil, err
+			}
+			item.Uuid = api.Uuid
+			writes = append(writes, bundleWrite{api: api})
+			current = append(current, api)
+		}
+		existing[api.UserId] = current
+		used[item.Uuid] = true
+		items = append(items, item)
+	}
+	if query.DryRun {
+		return items, nil
+	}
+	for _, write := range writes {
+		if write.update {
+			if err := s.repo.UpdateApi(ctx.Request().Context(), write.api); err != nil {
+				return nil, KiteError.New(KiteError.ApiUpdateError, err)
+			}
+			continue
+		}
+		if err := s.repo.InsertApi(ctx.Request().Context(), write.api); err != nil {
+			return nil, KiteError.New(KiteError.ApiCreateError, err)
+		}
+	}
+	return items, nil
+}
+
+// bundleWrite 导入计划中的一次写入，update 为 true 时覆盖已有的 mock
+type bundleWrite struct {
+	api    *models.Api
+	update bool
+}
+
+// assignUuid 保留 bundle 中的 uuid，未提供或已被占用时生成新的 uuid。uuid 全局唯一，需要检查全部工作区
+func (s *bundleService) assignUuid(ctx echo.Context, api *models.Api, used map[string]bool) error {
+	if api.Uuid == "" || used[api.Uuid] {
+		api.Uuid = uuid2.NewString()
+		return nil
+	}
+	_, err := s.repo.GetApiByUuid(tenant.Unscoped(ctx.Request().Context()), api.Uuid)
+	if err == nil {
+		api.Uuid = uuid2.NewString()
+		return nil
+	}
+	if !errors.Is(err, repositories.ErrRecordNotFound) {
+		return KiteError.New(KiteError.DatabaseError, err)
+	}
+	return nil
+}
+
+// bundleApi 将 bundle 条目转换为 mock 并校验，userId 非空时覆盖条目中的命名空间
+func bundleApi(mock bundle.Mock, userId string) (*models.Api, error) {
+	api := &models.Api{Uuid: mock.Uuid}
+	if err := mock.ApplyTo(api); err != nil {
+		return nil, err
+	}
+	if userId != "" {
+		api.UserId = userId
+	}
+	switch {
+	case api.UserId == "":
+		return nil, errors.New("user_id is required")
+	case api.Path == "":
+		return nil, errors.New("path is required")
+	case api.Method == "":
+		return nil, errors.New("method is required")
+	case api.StatusCode < 100 || api.StatusCode > 599:
+		return nil, errors.New("status_code must be between 100 and 599")
+	}
+	if err := validateApi(api); err != nil {
+		return nil, err
+	}
+	return api, nil
+}
+
+// findConflict 查找与 api 冲突的已有 mock
+func findConflict(existing []*models.Api, api *models.Api) *models.Api {
+	for _, candidate := range existing {
+		if api.Uuid != "" && candidate.Uuid == api.Uuid {
+			return candidate
+		}
+	}
+	for _, candidate := range existing {
+		if candidate.Method == api.Method && candidate.Path == api.Path &&
+			candidate.ScenarioName == api.ScenarioName && candidate.RequiredState == api.RequiredState &&
+			sameMatchers(candidate, api) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// sameMatchers 比较解析后的匹配条件，数据库返回的 JSON 格式可能与写入时不同
+func sameMatchers(a *models.Api, b *models.Api) bool {
+	left, err := matching.DecodeRequestMatchers(a.RequestMatchers)
+	if err != nil {
+		return false
+	}
+	right, err := matching.DecodeRequestMatchers(b.RequestMatchers)
+	if err != nil {
+		return false
+	}
+	return matchersKey(left) == matchersKey(right)
+}
+
+func matchersKey(matchers *matching.RequestMatchers) string {
+	if matchers == nil || matchers.Count() == 0 {
+		return ""
+	}
+	encoded, _ := json.Marshal(matchers)
+	return string(encoded)
+}
+
+// overwriteApi 用导入的内容覆盖已有的 mock，保留主键、uuid、所属工作区和创建时间
+func overwriteApi(target *models.Api, source *models.Api) {
+	id, uuid, workspaceId, createdAt := target.Id, target.Uuid, target.WorkspaceId, target.CreatedAt
+	*target = *source
+	target.Id, target.Uuid, target.WorkspaceId, target.CreatedAt = id, uuid, workspaceId, createdAt
+}
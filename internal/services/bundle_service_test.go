@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/bundle"
+	KiteError "kite/internal/errors"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"kite/internal/tenant"
+	"net/http/httptest"
+	"testing"
+)
+
+func newBundleFixture(t *testing.T) (BundleService, repositories.ApiRepository) {
+	t.Helper()
+	apis := repositories.NewMemoryApiRepository()
+	workspaces := NewWorkspaceService(repositories.NewMemoryWorkspaceRepository(), apis, repositories.NewMemoryNamespaceRepository())
+	for _, seed := range []struct{ workspace, uid, path string }{{"w1", "mine", "/a"}, {"w2", "theirs", "/b"}} {
+		api := testApi(seed.uid+seed.path, seed.path, 0, "")
+		api.UserId, api.StatusCode, api.ResponseBody = seed.uid, 200, "old"
+		if err := apis.InsertApi(tenant.WithWorkspace(context.Background(), seed.workspace), api); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewBundleService(apis, workspaces), apis
+}
+
+func bundleContext(workspace string) echo.Context {
+	r := httptest.NewRequest("POST", "/", nil)
+	if workspace != "" {
+		r = r.WithContext(tenant.WithWorkspace(r.Context(), workspace))
+	}
+	return echo.New().NewContext(r, httptest.NewRecorder())
+}
+
+func bundleMock(uid string, path string, body string) bundle.Mock {
+	return bundle.Mock{MockApiPayload: payloads.MockApiPayload{
+		UserId: uid, Path: path, Method: "GET", StatusCode: 200, ContentType: "text/plain", Charset: "utf-8", ResponseBody: body,
+	}}
+}
+
+func TestBundleOverwriteKeepsWorkspace(t *testing.T) {
+	service, apis := newBundleFixture(t)
+	b := &bundle.Bundle{Mocks: []bundle.Mock{bundleMock("mine", "/a", "new")}}
+	items, err := service.Import(bundleContext(""), b, payloads.BundleImportQuery{Strategy: payloads.ConflictOverwrite})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(items) != 1 || items[0].Action != payloads.BundleActionOverwritten {
+		t.Fatalf("items = %+v", items)
+	}
+	stored, err := apis.GetApiByUuid(context.Background(), "mine/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ResponseBody != "new" || stored.WorkspaceId != "w1" {
+		t.Fatalf("overwritten mock = %q in workspace %q, want the new body in w1", stored.ResponseBody, stored.WorkspaceId)
+	}
+}
+
+func TestBundleImportWritesNothingOnFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		mocks []bundle.Mock
+		code  KiteError.ErrorCode
+	}{
+		{"later entry is invalid", []bundle.Mock{bundleMock("mine", "/new", "x"), bundleMock("mine", "/a/**/b", "x")}, KiteError.ImportError},
+		{"later namespace belongs to another workspace", []bundle.Mock{bundleMock("mine", "/new", "x"), bundleMock("theirs", "/new", "x")}, KiteError.ForbiddenError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, apis := newBundleFixture(t)
+			_, err := service.Import(bundleContext("w1"), &bundle.Bundle{Mocks: tt.mocks}, payloads.BundleImportQuery{})
+			appErr, ok := KiteError.IsAppError(err)
+			if !ok || appErr.Code != tt.code {
+				t.Fatalf("Import error = %v, want code %d", err, tt.code)
+			}
+			list, _, err := apis.ListApis(context.Background(), repositories.ApiFilter{UserId: "mine"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != 1 {
+				t.Fatalf("namespace has %d mocks after a failed import, want only the seeded one", len(list))
+			}
+		})
+	}
+}
+
+func TestOverwriteApi(t *testing.T) {
+	target := &models.Api{Id: 3, Uuid: "keep", WorkspaceId: "w1", ResponseBody: "old"}
+	overwriteApi(target, &models.Api{Id: 9, Uuid: "drop", ResponseBody: "new"})
+	if target.Id != 3 || target.Uuid != "keep" || target.WorkspaceId != "w1" || target.ResponseBody != "new" {
+		t.Fatalf("overwriteApi = %+v", target)
+	}
+}
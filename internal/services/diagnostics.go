@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+	"kite/internal/api/payloads"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"sort"
+)
+
+// maxMissCandidates 诊断信息中最多返回的候选 mock 数量
+const maxMissCandidates = 5
+
+const (
+	ReasonMethodMismatch        = "method_mismatch"
+	ReasonPathMismatch          = "path_mismatch"
+	ReasonPathAndMethodMismatch = "path_and_method_mismatch"
+	ReasonMatcherMismatch       = "matcher_mismatch"
+	ReasonScenarioStateMismatch = "scenario_state_mismatch"
+)
+
+// findCandidates 从命名空间下的全部 mock 中找出与请求最接近的几个：
+// 路径方法都相同但匹配条件或场景状态不满足的，路径相同但方法不同的，以及路径编辑距离足够小的
+func findCandidates(apis []*models.Api, path string, method string, req *matching.Request, lookup scenarioLookup) []payloads.MockCandidate {
+	threshold := len(path) / 3
+	if threshold < 2 {
+		threshold = 2
+	}
+	candidates := make([]payloads.MockCandidate, 0)
+	for _, api := range apis {
+		distance := levenshtein(api.Path, path)
+		// 模式能匹配上的路径视为距离为 0
+		if pattern, err := matching.CompilePath(api.Path); err == nil {
+			if _, ok := pattern.Match(path); ok {
+				distance = 0
+			}
+		}
+		var reason, detail string
+		switch {
+		case distance == 0 && api.Method == method:
+			matchers, err := matching.DecodeRequestMatchers(api.RequestMatchers)
+			if err != nil {
+				continue
+			}
+			if ok, failed := matchers.Match(req); !ok {
+				reason = ReasonMatcherMismatch
+				detail = failed
+			} else if !inRequiredState(api, lookup) {
+				reason = ReasonScenarioStateMismatch
+				detail = fmt.Sprintf("scenario %s is in state %s, requires %s",
+					api.ScenarioName, lookup(api.ScenarioName), api.RequiredState)
+			} else {
+				continue
+			}
+		case distance == 0 && api.Method != method:
+			reason = ReasonMethodMismatch
+		case distance > 0 && distance <= threshold && api.Method == method:
+			reason = ReasonPathMismatch
+		case distance > 0 && distance <= threshold:
+			reason = ReasonPathAndMethodMismatch
+		default:
+			continue
+		}
+		candidates = append(candidates, payloads.MockCandidate{
+			Uuid:     api.Uuid,
+			Method:   api.Method,
+			Path:     api.Path,
+			Reason:   reason,
+			Detail:   detail,
+			Distance: distance,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Distance != candidates[j].Distance {
+			return candidates[i].Distance < candidates[j].Distance
+		}
+		return candidateRank(candidates[i].Reason) < candidateRank(candidates[j].Reason)
+	})
+	if len(candidates) > maxMissCandidates {
+		candidates = candidates[:maxMissCandidates]
+	}
+	return candidates
+}
+
+func candidateRank(reason string) int {
+	switch reason {
+	case ReasonMatcherMismatch, ReasonScenarioStateMismatch:
+		return 0
+	case ReasonMethodMismatch:
+		return 1
+	case ReasonPathMismatch:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// levenshtein 计算两个字符串的编辑距离
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
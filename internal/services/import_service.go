@@ -0,0 +1,124 @@
+package services
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	KiteError "kite/internal/errors"
+	"kite/internal/importers"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/openapi"
+	"kite/internal/repositories"
+)
+
+// ImportService 从外部的接口描述批量生成 mock
+type ImportService interface {
+	ImportOpenAPI(ctx echo.Context, uid string, data []byte, dryRun bool) (*ImportResult, error)
+	ImportHAR(ctx echo.Context, uid string, data []byte, dryRun bool) (*ImportResult, error)
+	ImportPostman(ctx echo.Context, uid string, data []byte, dryRun bool) (*ImportResult, error)
+}
+
+// ImportResult 导入生成的 mock 和被跳过的条目，预览（dry run）时 Created 中的 mock 不会写入
+type ImportResult struct {
+	Created []*models.Api
+	Skipped []importers.Skipped
+}
+
+type importService struct {
+	repo repositories.ApiRepository
+}
+
+func NewImportService(repo repositories.ApiRepository) ImportService {
+	return &importService{repo}
+}
+
+// ImportOpenAPI 解析 OpenAPI 3 或 Swagger 2 规范并保存生成的 mock，
+// 生成的 mock 全部校验通过后才会写入
+func (s *importService) ImportOpenAPI(ctx echo.Context, uid string, data []byte, dryRun bool) (*ImportResult, error) {
+	spec, err := openapi.Parse(data)
+	if err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.ImportError, err.Error(), err)
+	}
+	apis, err := importers.FromOpenAPI(spec, uid)
+	if err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.ImportError, err.Error(), err)
+	}
+	for _, api := range apis {
+		if err := validateApi(api); err != nil {
+			return nil, KiteError.NewWithMessage(KiteError.ImportError, fmt.Sprintf("%s %s: %s", api.Method, api.Path, err.Error()), err)
+		}
+	}
+	result := &ImportResult{Created: apis, Skipped: make([]importers.Skipped, 0)}
+	return s.save(ctx, result, dryRun)
+}
+
+// ImportHAR 将 HAR 文件中录制的请求导入为 mock
+func (s *importService) ImportHAR(ctx echo.Context, uid string, data []byte, dryRun bool) (*ImportResult, error) {
+	apis, skipped, err := importers.FromHAR(data, uid)
+	if err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.ImportError, err.Error(), err)
+	}
+	return s.importDeduplicated(ctx, uid, apis, skipped, dryRun)
+}
+
+// ImportPostman 将 Postman 集合中的请求及其示例响应导入为 mock
+func (s *importService) ImportPostman(ctx echo.Context, uid string, data []byte, dryRun bool) (*ImportResult, error) {
+	apis, skipped, err := importers.FromPostman(data, uid)
+	if err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.ImportError, err.Error(), err)
+	}
+	return s.importDeduplicated(ctx, uid, apis, skipped, dryRun)
+}
+
+// importDeduplicated 按 method + path + 匹配条件去重：同一批次中只保留第一条，命名空间中已存在的不再导入；
+// 校验不通过的条目跳过而不是让整个导入失败
+func (s *importService) importDeduplicated(ctx echo.Context, uid string, apis []*models.Api, skipped []importers.Skipped, dryRun bool) (*ImportResult, error) {
+	existing, _, err := s.repo.ListApis(ctx.Request().Context(), repositories.ApiFilter{UserId: uid})
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	existed := make(map[string]bool, len(existing))
+	for _, api := range existing {
+		existed[importKey(api)] = true
+	}
+	seen := make(map[string]bool, len(apis))
+	result := &ImportResult{Created: make([]*models.Api, 0, len(apis)), Skipped: skipped}
+	for _, api := range apis {
+		key := importKey(api)
+		reason := ""
+		switch {
+		case existed[key]:
+			reason = "mock already exists"
+		case seen[key]:
+			reason = "duplicate of an earlier entry"
+		default:
+			if err := validateApi(api); err != nil {
+				reason = err.Error()
+			}
+		}
+		if reason != "" {
+			result.Skipped = append(result.Skipped, importers.Skipped{Method: api.Method, Path: api.Path, Reason: reason})
+			continue
+		}
+		seen[key] = true
+		result.Created = append(result.Created, api)
+	}
+	return s.save(ctx, result, dryRun)
+}
+
+func (s *importService) save(ctx echo.Context, result *ImportResult, dryRun bool) (*ImportResult, error) {
+	if dryRun {
+		return result, nil
+	}
+	// 全部写入或全部不写入，失败后可以直接重新导入，不会留下部分 mock
+	if err := s.repo.InsertApis(ctx.Request().Context(), result.Created); err != nil {
+		return nil, KiteError.New(KiteError.ApiCreateError, err)
+	}
+	return result, nil
+}
+
+// importKey 方法、路径和匹配条件都相同的 mock 视为重复，同一请求的多个示例通过匹配条件区分
+func importKey(api *models.Api) string {
+	matchers, _ := matching.DecodeRequestMatchers(api.RequestMatchers)
+	return api.Method + " " + api.Path + " " + matchersKey(matchers)
+}
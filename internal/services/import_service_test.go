@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/labstack/echo/v4"
+	KiteError "kite/internal/errors"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// failingApiRepository 批量写入总是失败，逐个写入不应该被调用
+type failingApiRepository struct {
+	repositories.ApiRepository
+}
+
+func (r *failingApiRepository) InsertApi(ctx context.Context, api *models.Api) error {
+	return errors.New("import must not insert mocks one by one")
+}
+
+func (r *failingApiRepository) InsertApis(ctx context.Context, apis []*models.Api) error {
+	return errors.New("disk full")
+}
+
+func TestImportPostmanKeepsEveryExample(t *testing.T) {
+	data, err := os.ReadFile("../importers/testdata/collection.postman.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewImportService(repositories.NewMemoryApiRepository())
+	ctx := echo.New().NewContext(httptest.NewRequest("POST", "/", nil), httptest.NewRecorder())
+
+	first, err := service.ImportPostman(ctx, "shop", data, false)
+	if err != nil {
+		t.Fatalf("ImportPostman: %v", err)
+	}
+	if len(first.Created) != 6 || len(first.Skipped) != 1 {
+		t.Fatalf("first import created %d and skipped %+v, want 6 created and the invalid url skipped", len(first.Created), first.Skipped)
+	}
+	again, err := service.ImportPostman(ctx, "shop", data, false)
+	if err != nil {
+		t.Fatalf("ImportPostman: %v", err)
+	}
+	if len(again.Created) != 0 || len(again.Skipped) != 7 {
+		t.Fatalf("second import created %d and skipped %d, want everything skipped as existing", len(again.Created), len(again.Skipped))
+	}
+}
+
+func TestImportIsAllOrNothing(t *testing.T) {
+	data, err := os.ReadFile("../importers/testdata/collection.postman.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		failing   bool
+		dryRun    bool
+		wantCode  KiteError.ErrorCode
+		wantTotal int64
+	}{
+		{name: "every mock is written", wantTotal: 6},
+		{name: "dry run writes nothing", dryRun: true},
+		{name: "failed write leaves nothing", failing: true, wantCode: KiteError.ApiCreateError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := repositories.NewMemoryApiRepository()
+			var repo repositories.ApiRepository = memory
+			if tt.failing {
+				repo = &failingApiRepository{memory}
+			}
+			ctx := echo.New().NewContext(httptest.NewRequest("POST", "/", nil), httptest.NewRecorder())
+			_, err := NewImportService(repo).ImportPostman(ctx, "shop", data, tt.dryRun)
+			if tt.wantCode != 0 {
+				appErr, ok := KiteError.IsAppError(err)
+				if !ok || appErr.Code != tt.wantCode {
+					t.Fatalf("err = %v, want code %v", err, tt.wantCode)
+				}
+			} else if err != nil {
+				t.Fatalf("ImportPostman: %v", err)
+			}
+			if _, total, err := memory.ListApis(context.Background(), repositories.ApiFilter{}); err != nil || total != tt.wantTotal {
+				t.Fatalf("stored %d mocks (%v), want %d", total, err, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"kite/internal/api/payloads"
+	"kite/internal/configs"
+	KiteError "kite/internal/errors"
+	"kite/internal/journal"
+	"kite/internal/matching"
+	KiteLogger "kite/pkg/logger"
+	"net/http"
+	"sort"
+)
+
+// defaultMaxBodyBytes 未配置时记录的请求体长度上限
+const defaultMaxBodyBytes = 64 << 10
+
+// JournalService 记录和查询 mock 请求日志
+type JournalService interface {
+	// Record 写入一条记录，请求体超过 MaxBodyBytes 时截断；写入失败只记录日志，不影响 mock 响应
+	Record(ctx echo.Context, entry *journal.Entry)
+	// MaxBodyBytes 记录的请求体长度上限
+	MaxBodyBytes() int
+	Search(ctx echo.Context, uid string, query payloads.JournalQuery) ([]*journal.Entry, int64, error)
+	Clear(ctx echo.Context, uid string) error
+	// Verify 统计请求日志中满足条件的请求次数并与期望比较，失败时附带最接近的未匹配请求
+	Verify(ctx echo.Context, uid string, payload payloads.VerificationPayload) (*payloads.VerificationResponse, error)
+}
+
+type journalService struct {
+	sink         journal.Sink
+	maxBodyBytes int
+}
+
+func NewJournalService(sink journal.Sink, cfg *configs.JournalConfig) JournalService {
+	maxBodyBytes := cfg.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+	return &journalService{sink, maxBodyBytes}
+}
+
+func (s *journalService) Record(ctx echo.Context, entry *journal.Entry) {
+	if len(entry.Body) > s.maxBodyBytes {
+		entry.Body = entry.Body[:s.maxBodyBytes]
+		entry.BodyTruncated = true
+	}
+	// 客户端断开不应导致记录丢失
+	if err := s.sink.Append(context.WithoutCancel(ctx.Request().Context()), entry); err != nil {
+		KiteLogger.ErrorC(ctx, "Failed to write journal entry", zap.Error(err))
+	}
+}
+
+func (s *journalService) MaxBodyBytes() int {
+	return s.maxBodyBytes
+}
+
+// Search 按时间倒序分页查询命名空间下的请求日志
+func (s *journalService) Search(ctx echo.Context, uid string, query payloads.JournalQuery) ([]*journal.Entry, int64, error) {
+	query.Normalize()
+	entries, total, err := s.sink.Search(ctx.Request().Context(), query.Filter(uid))
+	if err != nil {
+		return nil, 0, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return entries, total, nil
+}
+
+func (s *journalService) Clear(ctx echo.Context, uid string) error {
+	if err := s.sink.Clear(ctx.Request().Context(), uid); err != nil {
+		return KiteError.New(KiteError.DatabaseError, err)
+	}
+	return nil
+}
+
+func (s *journalService) Verify(ctx echo.Context, uid string, payload payloads.VerificationPayload) (*payloads.VerificationResponse, error) {
+	payload.Normalize()
+	pattern, err := matching.CompilePath(payload.Path)
+	if err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+	}
+	if err := payload.Matchers.Validate(); err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+	}
+	entries, _, err := s.sink.Search(ctx.Request().Context(), journal.Filter{Uid: uid, Since: payload.Since, Until: payload.Until})
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+
+	threshold := max(len(payload.Path)/3, 2)
+	matched := make([]*journal.Entry, 0)
+	misses := make([]payloads.NearMiss, 0)
+	for _, entry := range entries {
+		methodMatched := payload.Method == "" || payload.Method == entry.Method
+		distance := levenshtein(payload.Path, entry.Path)
+		if _, ok := pattern.Match(entry.Path); ok {
+			distance = 0
+		}
+		var reason, detail string
+		switch {
+		case distance == 0 && methodMatched:
+			header := make(http.Header, len(entry.Headers))
+			for key, value := range entry.Headers {
+				header.Set(key, value)
+			}
+			req := matching.NewRecordedRequest(entry.Query, header, []byte(entry.Body))
+			ok, failed := payload.Matchers.Match(req)
+			if ok {
+				matched = append(matched, entry)
+				continue
+			}
+			reason, detail = ReasonMatcherMismatch, failed
+		case distance == 0:
+			reason = ReasonMethodMismatch
+		case distance <= threshold && methodMatched:
+			reason = ReasonPathMismatch
+		case distance <= threshold:
+			reason = ReasonPathAndMethodMismatch
+		default:
+			continue
+		}
+		misses = append(misses, payloads.NearMiss{Request: entry, Reason: reason, Detail: detail, Distance: distance})
+	}
+
+	passed := payload.Count.Check(len(matched))
+	if passed {
+		misses = misses[:0]
+	}
+	sort.SliceStable(misses, func(i, j int) bool {
+		if misses[i].Distance != misses[j].Distance {
+			return misses[i].Distance < misses[j].Distance
+		}
+		return candidateRank(misses[i].Reason) < candidateRank(misses[j].Reason)
+	})
+	if len(misses) > maxMissCandidates {
+		misses = misses[:maxMissCandidates]
+	}
+	return &payloads.VerificationResponse{
+		Passed:     passed,
+		Expected:   payload.Count.String(),
+		Actual:     len(matched),
+		Matched:    matched,
+		NearMisses: misses,
+	}, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/configs"
+	KiteError "kite/internal/errors"
+	"kite/internal/journal"
+	"kite/internal/matching"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newVerifyFixture 在内存日志中写入命名空间 shop 的请求，其中 GET /orders/1 共有两次
+func newVerifyFixture(t *testing.T) (JournalService, time.Time) {
+	t.Helper()
+	sink := journal.NewMemorySink(0)
+	start := time.Now()
+	entries := []*journal.Entry{
+		{Id: "1", Uid: "shop", Method: "GET", Path: "/orders/1", Headers: map[string]string{"X-Tier": "vip"}},
+		{Id: "2", Uid: "shop", Method: "GET", Path: "/orders/1"},
+		{Id: "3", Uid: "shop", Method: "POST", Path: "/orders/1"},
+		{Id: "4", Uid: "shop", Method: "GET", Path: "/orders/12"},
+		{Id: "5", Uid: "shop", Method: "GET", Path: "/users/profile"},
+		{Id: "6", Uid: "other", Method: "GET", Path: "/orders/1"},
+	}
+	for i, entry := range entries {
+		entry.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		if err := sink.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewJournalService(sink, &configs.JournalConfig{}), start
+}
+
+func verifyContext() echo.Context {
+	return echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+}
+
+func TestVerifyCount(t *testing.T) {
+	service, start := newVerifyFixture(t)
+	tests := []struct {
+		name         string
+		payload      payloads.VerificationPayload
+		wantPassed   bool
+		wantExpected string
+		wantActual   int
+	}{
+		{name: "default is at least once", payload: payloads.VerificationPayload{Method: "get", Path: "/orders/1"}, wantPassed: true, wantExpected: "at least 1", wantActual: 2},
+		{name: "exactly", payload: verification("/orders/1", payloads.CountExactly, 2), wantPassed: true, wantExpected: "exactly 2", wantActual: 2},
+		{name: "exactly too few", payload: verification("/orders/1", payloads.CountExactly, 3), wantExpected: "exactly 3", wantActual: 2},
+		{name: "at least", payload: verification("/orders/1", payloads.CountAtLeast, 2), wantPassed: true, wantExpected: "at least 2", wantActual: 2},
+		{name: "at least too few", payload: verification("/orders/1", payloads.CountAtLeast, 3), wantExpected: "at least 3", wantActual: 2},
+		{name: "at most", payload: verification("/orders/1", payloads.CountAtMost, 2), wantPassed: true, wantExpected: "at most 2", wantActual: 2},
+		{name: "at most too many", payload: verification("/orders/1", payloads.CountAtMost, 1), wantExpected: "at most 1", wantActual: 2},
+		{name: "never", payload: verification("/orders/2", payloads.CountNever, 0), wantPassed: true, wantExpected: "never", wantActual: 0},
+		{name: "never but called", payload: verification("/orders/1", payloads.CountNever, 0), wantExpected: "never", wantActual: 2},
+		{name: "path pattern", payload: verification("/orders/{id}", payloads.CountExactly, 3), wantPassed: true, wantExpected: "exactly 3", wantActual: 3},
+		{
+			name: "any method",
+			payload: payloads.VerificationPayload{
+				Path:  "/orders/1",
+				Count: &payloads.CountConstraint{Type: payloads.CountExactly, Value: 3},
+			},
+			wantPassed: true, wantExpected: "exactly 3", wantActual: 3,
+		},
+		{
+			name: "matchers",
+			payload: payloads.VerificationPayload{
+				Method:   "GET",
+				Path:     "/orders/1",
+				Matchers: &matching.RequestMatchers{Headers: []matching.FieldMatcher{{Name: "X-Tier", Operator: matching.OperatorEquals, Value: "vip"}}},
+				Count:    &payloads.CountConstraint{Type: payloads.CountExactly, Value: 1},
+			},
+			wantPassed: true, wantExpected: "exactly 1", wantActual: 1,
+		},
+		{
+			name: "time window",
+			payload: payloads.VerificationPayload{
+				Method: "GET",
+				Path:   "/orders/1",
+				Count:  &payloads.CountConstraint{Type: payloads.CountExactly, Value: 1},
+				Since:  start.Add(time.Second),
+			},
+			wantPassed: true, wantExpected: "exactly 1", wantActual: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.Verify(verifyContext(), "shop", tt.payload)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if resp.Passed != tt.wantPassed || resp.Expected != tt.wantExpected || resp.Actual != tt.wantActual || len(resp.Matched) != tt.wantActual {
+				t.Fatalf("Verify = passed %v, expected %q, actual %d, want passed %v, expected %q, actual %d",
+					resp.Passed, resp.Expected, resp.Actual, tt.wantPassed, tt.wantExpected, tt.wantActual)
+			}
+			if resp.Passed && len(resp.NearMisses) != 0 {
+				t.Fatalf("a passed verification must not report near misses, got %+v", resp.NearMisses)
+			}
+		})
+	}
+}
+
+func TestVerifyFailureResponse(t *testing.T) {
+	service, _ := newVerifyFixture(t)
+	tests := []struct {
+		name        string
+		payload     payloads.VerificationPayload
+		wantMatched []string
+		wantMisses  []payloads.NearMiss
+	}{
+		{
+			name: "matcher mismatch ranks before method and path mismatches",
+			payload: payloads.VerificationPayload{
+				Method:   "GET",
+				Path:     "/orders/1",
+				Matchers: &matching.RequestMatchers{Headers: []matching.FieldMatcher{{Name: "X-Tier", Operator: matching.OperatorEquals, Value: "gold"}}},
+				Count:    &payloads.CountConstraint{Type: payloads.CountExactly, Value: 1},
+			},
+			wantMatched: []string{},
+			wantMisses: []payloads.NearMiss{
+				{Request: &journal.Entry{Id: "2"}, Reason: ReasonMatcherMismatch},
+				{Request: &journal.Entry{Id: "1"}, Reason: ReasonMatcherMismatch},
+				{Request: &journal.Entry{Id: "3"}, Reason: ReasonMethodMismatch},
+				{Request: &journal.Entry{Id: "4"}, Reason: ReasonPathMismatch, Distance: 1},
+			},
+		},
+		{
+			name:        "matched requests are listed newest first",
+			payload:     verification("/orders/1", payloads.CountAtMost, 1),
+			wantMatched: []string{"2", "1"},
+			wantMisses: []payloads.NearMiss{
+				{Request: &journal.Entry{Id: "3"}, Reason: ReasonMethodMismatch},
+				{Request: &journal.Entry{Id: "4"}, Reason: ReasonPathMismatch, Distance: 1},
+			},
+		},
+		{
+			name:        "other method and path",
+			payload:     payloads.VerificationPayload{Method: "DELETE", Path: "/orders/13"},
+			wantMatched: []string{},
+			wantMisses: []payloads.NearMiss{
+				{Request: &journal.Entry{Id: "4"}, Reason: ReasonPathAndMethodMismatch, Distance: 1},
+				{Request: &journal.Entry{Id: "3"}, Reason: ReasonPathAndMethodMismatch, Distance: 1},
+				{Request: &journal.Entry{Id: "2"}, Reason: ReasonPathAndMethodMismatch, Distance: 1},
+				{Request: &journal.Entry{Id: "1"}, Reason: ReasonPathAndMethodMismatch, Distance: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.Verify(verifyContext(), "shop", tt.payload)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if resp.Passed {
+				t.Fatalf("verification must fail, got %+v", resp)
+			}
+			matched := make([]string, 0, len(resp.Matched))
+			for _, entry := range resp.Matched {
+				matched = append(matched, entry.Id)
+			}
+			if !reflect.DeepEqual(matched, tt.wantMatched) {
+				t.Fatalf("matched = %v, want %v", matched, tt.wantMatched)
+			}
+			if len(resp.NearMisses) != len(tt.wantMisses) {
+				t.Fatalf("near misses = %+v, want %d", resp.NearMisses, len(tt.wantMisses))
+			}
+			for i, want := range tt.wantMisses {
+				got := resp.NearMisses[i]
+				if got.Request.Id != want.Request.Id || got.Reason != want.Reason || got.Distance != want.Distance {
+					t.Fatalf("near miss %d = %s %s (distance %d), want %s %s (distance %d)",
+						i, got.Request.Id, got.Reason, got.Distance, want.Request.Id, want.Reason, want.Distance)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsInvalidConditions(t *testing.T) {
+	service, _ := newVerifyFixture(t)
+	tests := []struct {
+		name    string
+		payload payloads.VerificationPayload
+	}{
+		{name: "invalid path pattern", payload: payloads.VerificationPayload{Path: "/orders/**/items"}},
+		{
+			name: "invalid matcher",
+			payload: payloads.VerificationPayload{
+				Path:     "/orders/1",
+				Matchers: &matching.RequestMatchers{Headers: []matching.FieldMatcher{{Name: "X-Tier", Operator: matching.OperatorRegex, Value: "("}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Verify(verifyContext(), "shop", tt.payload)
+			if appErr, ok := KiteError.IsAppError(err); !ok || appErr.Code != KiteError.ValidationError {
+				t.Fatalf("err = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func verification(path string, countType string, value int) payloads.VerificationPayload {
+	return payloads.VerificationPayload{Method: "GET", Path: path, Count: &payloads.CountConstraint{Type: countType, Value: value}}
+}
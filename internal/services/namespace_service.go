@@ -0,0 +1,184 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/api/validators"
+	KiteError "kite/internal/errors"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/openapi"
+	"kite/internal/proxy"
+	"kite/internal/repositories"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type NamespaceService interface {
+	Get(ctx echo.Context, uid string) (*models.Namespace, error)
+	Update(ctx echo.Context, uid string, payload payloads.NamespacePayload) (*models.Namespace, error)
+	BindOpenApi(ctx echo.Context, uid string, data []byte) (*openapi.Spec, error)
+	UnbindOpenApi(ctx echo.Context, uid string) error
+	ValidateRequest(ctx echo.Context, uid string, method string, path string, req *matching.Request) error
+}
+
+type namespaceService struct {
+	repo repositories.NamespaceRepository
+	// validators 按命名空间缓存编译好的 OpenAPI 校验器
+	validators sync.Map
+}
+
+// boundValidator 缓存的校验器及其对应文档的指纹，文档变化后重新编译
+type boundValidator struct {
+	fingerprint string
+	validator   *openapi.Validator
+}
+
+func NewNamespaceService(repo repositories.NamespaceRepository) NamespaceService {
+	return &namespaceService{repo: repo}
+}
+
+// Get 查询命名空间配置，未配置过的命名空间返回默认配置
+func (s *namespaceService) Get(ctx echo.Context, uid string) (*models.Namespace, error) {
+	namespace, err := s.repo.GetNamespaceByUid(ctx.Request().Context(), uid)
+	if err != nil {
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return defaultNamespace(uid), nil
+		}
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return namespace, nil
+}
+
+func (s *namespaceService) Update(ctx echo.Context, uid string, payload payloads.NamespacePayload) (*models.Namespace, error) {
+	namespace, err := s.Get(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := payload.ApplyTo(namespace); err != nil {
+		return nil, KiteError.New(KiteError.MarshalError, err)
+	}
+	if err := payload.Delay.Validate(); err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+	}
+	for _, rule := range payload.Rewrites {
+		if err := rule.Validate(); err != nil {
+			return nil, KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+		}
+	}
+	if err := s.repo.SaveNamespace(ctx.Request().Context(), namespace); err != nil {
+		return nil, KiteError.New(KiteError.NamespaceUpdateError, err)
+	}
+	return namespace, nil
+}
+
+// BindOpenApi 将 OpenAPI 文档绑定到命名空间，之后该命名空间下的请求都会按文档校验
+func (s *namespaceService) BindOpenApi(ctx echo.Context, uid string, data []byte) (*openapi.Spec, error) {
+	spec, err := openapi.Parse(data)
+	if err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+	}
+	validator, err := openapi.NewValidator(data)
+	if err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.ValidationError, err.Error(), err)
+	}
+	namespace, err := s.Get(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	namespace.OpenApiSpec = string(data)
+	namespace.OpenApiSpecHash = specHash(data)
+	if err := s.repo.SaveNamespace(ctx.Request().Context(), namespace); err != nil {
+		return nil, KiteError.New(KiteError.NamespaceUpdateError, err)
+	}
+	s.validators.Store(uid, &boundValidator{namespace.OpenApiSpecHash, validator})
+	return spec, nil
+}
+
+func (s *namespaceService) UnbindOpenApi(ctx echo.Context, uid string) error {
+	namespace, err := s.Get(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if namespace.OpenApiSpec == "" {
+		return KiteError.New(KiteError.OpenApiNotBoundError, nil).WithDetail(uid)
+	}
+	namespace.OpenApiSpec = ""
+	namespace.OpenApiSpecHash = ""
+	if err := s.repo.SaveNamespace(ctx.Request().Context(), namespace); err != nil {
+		return KiteError.New(KiteError.NamespaceUpdateError, err)
+	}
+	s.validators.Delete(uid)
+	return nil
+}
+
+// ValidateRequest 命名空间绑定了 OpenAPI 文档时校验请求，返回的错误在 details 中列出全部违规
+func (s *namespaceService) ValidateRequest(ctx echo.Context, uid string, method string, path string, req *matching.Request) error {
+	namespace, err := s.Get(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if namespace.OpenApiSpec == "" {
+		return nil
+	}
+	validator, err := s.validator(namespace)
+	if err != nil {
+		return KiteError.New(KiteError.DataError, err)
+	}
+	operation, violations := validator.Validate(method, path, req)
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(violations))
+	details := payloads.RequestValidationDetails{
+		Operation:  operation,
+		Violations: make([]payloads.RequestViolation, 0, len(violations)),
+	}
+	for _, violation := range violations {
+		message := validators.FieldErrorMessage(violation.Field, violation.Tag, violation.Param)
+		messages = append(messages, message)
+		details.Violations = append(details.Violations, payloads.RequestViolation{
+			Location: violation.Location,
+			Field:    violation.Field,
+			Message:  message,
+		})
+	}
+	return KiteError.NewWithMessage(KiteError.ValidationError, validators.JoinErrorMessages(messages), nil).
+		WithData(details)
+}
+
+// validator 返回命名空间的校验器，文档摘要没有变化时复用缓存
+func (s *namespaceService) validator(namespace *models.Namespace) (*openapi.Validator, error) {
+	fingerprint := namespace.OpenApiSpecHash
+	if fingerprint == "" {
+		// 摘要字段出现之前绑定的文档没有摘要，按更新时间判断文档是否变化
+		fingerprint = namespace.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	if cached, ok := s.validators.Load(namespace.Uid); ok && cached.(*boundValidator).fingerprint == fingerprint {
+		return cached.(*boundValidator).validator, nil
+	}
+	validator, err := openapi.NewValidator([]byte(namespace.OpenApiSpec))
+	if err != nil {
+		return nil, err
+	}
+	s.validators.Store(namespace.Uid, &boundValidator{fingerprint, validator})
+	return validator, nil
+}
+
+// specHash 计算文档的 SHA-256 摘要
+func specHash(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
+
+func defaultNamespace(uid string) *models.Namespace {
+	return &models.Namespace{
+		Uid:            uid,
+		NotFoundStatus: http.StatusNotFound,
+		Mode:           proxy.ModeMock,
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"github.com/labstack/echo/v4"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const cachedSpec = `{"openapi":"3.0.0","info":{"title":"t","version":"1"},"paths":{"/a":{"get":{"responses":{"200":{"description":"ok"}}}}}}`
+
+func TestNamespaceValidatorCache(t *testing.T) {
+	service := NewNamespaceService(repositories.NewMemoryNamespaceRepository()).(*namespaceService)
+	ctx := echo.New().NewContext(httptest.NewRequest("POST", "/", nil), httptest.NewRecorder())
+	if _, err := service.BindOpenApi(ctx, "ns", []byte(cachedSpec)); err != nil {
+		t.Fatalf("BindOpenApi: %v", err)
+	}
+	namespace, err := service.Get(ctx, "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespace.OpenApiSpecHash != specHash([]byte(cachedSpec)) {
+		t.Fatalf("spec hash = %q, want the SHA-256 of the document", namespace.OpenApiSpecHash)
+	}
+	bound, _ := service.validators.Load("ns")
+
+	tests := []struct {
+		name   string
+		mutate func(namespace *models.Namespace)
+		reuse  bool
+	}{
+		{"same hash reuses the validator compiled at bind time", func(namespace *models.Namespace) {}, true},
+		{"unchanged hash ignores updated_at", func(namespace *models.Namespace) { namespace.UpdatedAt = time.Now().Add(time.Hour) }, true},
+		{"changed hash recompiles", func(namespace *models.Namespace) { namespace.OpenApiSpecHash = "other" }, false},
+		{"legacy document without hash is keyed by updated_at", func(namespace *models.Namespace) { namespace.OpenApiSpecHash = "" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := *namespace
+			tt.mutate(&copied)
+			validator, err := service.validator(&copied)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reused := validator == bound.(*boundValidator).validator; reused != tt.reuse {
+				t.Fatalf("validator reused = %v, want %v", reused, tt.reuse)
+			}
+			// 恢复绑定时的缓存，各用例互不影响
+			service.validators.Store("ns", bound)
+		})
+	}
+
+	if err := service.UnbindOpenApi(ctx, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	if namespace, _ = service.Get(ctx, "ns"); namespace.OpenApiSpecHash != "" {
+		t.Fatalf("unbinding must clear the spec hash")
+	}
+}
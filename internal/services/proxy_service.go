@@ -0,0 +1,173 @@
+package services
+
+import (
+	"encoding/json"
+	uuid2 "github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	KiteError "kite/internal/errors"
+	"kite/internal/matching"
+	"kite/internal/models"
+	"kite/internal/proxy"
+	"kite/internal/repositories"
+	"mime"
+	"net/http"
+)
+
+// ProxyService 将命名空间下的请求转发到上游
+type ProxyService interface {
+	// Record 转发请求并把请求和响应录制为新的 mock，返回上游的响应
+	Record(ctx echo.Context, namespace *models.Namespace, path string) (*MockResponse, *models.Api, error)
+	// Passthrough 将没有命中 mock 的请求转发到命名空间的回退上游，并按改写规则修改响应
+	Passthrough(ctx echo.Context, namespace *models.Namespace, path string) (*MockResponse, error)
+}
+
+type proxyService struct {
+	repo      repositories.ApiRepository
+	forwarder *proxy.Forwarder
+}
+
+func NewProxyService(repo repositories.ApiRepository, forwarder *proxy.Forwarder) ProxyService {
+	return &proxyService{repo, forwarder}
+}
+
+func (s *proxyService) Record(ctx echo.Context, namespace *models.Namespace, path string) (*MockResponse, *models.Api, error) {
+	req, err := matching.NewRequest(ctx.Request())
+	if err != nil {
+		return nil, nil, KiteError.NewWithMessage(KiteError.BadRequestError, "Failed to read request body", err)
+	}
+	exchange, err := s.forwarder.Forward(ctx.Request().Context(), namespace.UpstreamUrl, ctx.Request(), path, req.Body)
+	if err != nil {
+		return nil, nil, KiteError.New(KiteError.UpstreamRequestError, err).WithDetail(namespace.UpstreamUrl)
+	}
+	resp := newProxyResponse(exchange)
+	// 路径中含有模式语法（如 {、*）时无法原样回放，只转发不录制
+	if _, err := matching.ParsePath(path); err != nil {
+		return resp, nil, nil
+	}
+
+	config, err := proxy.DecodeRecordConfig(namespace.RecordConfig)
+	if err != nil {
+		return nil, nil, KiteError.New(KiteError.UnmarshalError, err)
+	}
+	api, err := newRecordedApi(namespace.Uid, path, ctx.Request().Method, exchange, config.Matchers(req))
+	if err != nil {
+		return nil, nil, KiteError.New(KiteError.MarshalError, err)
+	}
+	// 同一方法、路径和匹配条件已经录制过时更新原有 mock 的响应，避免重复录制出多个相同条件的 mock
+	existing, err := s.findRecorded(ctx, api)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing != nil {
+		existing.StatusCode = api.StatusCode
+		existing.ContentType = api.ContentType
+		existing.Charset = api.Charset
+		existing.Headers = api.Headers
+		existing.ResponseBody = api.ResponseBody
+		if err := s.repo.UpdateApi(ctx.Request().Context(), existing); err != nil {
+			return nil, nil, KiteError.New(KiteError.ApiUpdateError, err)
+		}
+		return resp, existing, nil
+	}
+	// 录制发生在公开的 mock 入口，请求不属于任何工作区，录制的 mock 归属于命名空间所在的工作区
+	api.WorkspaceId = namespace.WorkspaceId
+	if err := s.repo.InsertApi(ctx.Request().Context(), api); err != nil {
+		return nil, nil, KiteError.New(KiteError.ApiCreateError, err)
+	}
+	return resp, api, nil
+}
+
+// findRecorded 查找命名空间下方法、路径和匹配条件都与 api 相同的 mock
+func (s *proxyService) findRecorded(ctx echo.Context, api *models.Api) (*models.Api, error) {
+	apis, _, err := s.repo.ListApis(ctx.Request().Context(), repositories.ApiFilter{UserId: api.UserId, Method: api.Method, PathPrefix: api.Path})
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	for _, candidate := range apis {
+		if candidate.Path == api.Path && sameMatchers(candidate, api) {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *proxyService) Passthrough(ctx echo.Context, namespace *models.Namespace, path string) (*MockResponse, error) {
+	req, err := matching.NewRequest(ctx.Request())
+	if err != nil {
+		return nil, KiteError.NewWithMessage(KiteError.BadRequestError, "Failed to read request body", err)
+	}
+	exchange, err := s.forwarder.Forward(ctx.Request().Context(), namespace.FallbackUrl, ctx.Request(), path, req.Body)
+	if err != nil {
+		return nil, KiteError.New(KiteError.UpstreamRequestError, err).WithDetail(namespace.FallbackUrl)
+	}
+	rules, err := proxy.DecodeRewriteRules(namespace.RewriteRules)
+	if err != nil {
+		return nil, KiteError.New(KiteError.UnmarshalError, err)
+	}
+	for _, rule := range rules {
+		if err := rule.Apply(exchange, path); err != nil {
+			return nil, KiteError.New(KiteError.DataError, err)
+		}
+	}
+	return newProxyResponse(exchange), nil
+}
+
+// newProxyResponse 将上游响应转换为待写出的响应，多值响应头（如多个 Set-Cookie）保留全部的值
+func newProxyResponse(exchange *proxy.Exchange) *MockResponse {
+	headers := exchange.Header.Clone()
+	contentType := headers.Get(echo.HeaderContentType)
+	headers.Del(echo.HeaderContentType)
+	return &MockResponse{
+		StatusCode:  exchange.StatusCode,
+		ContentType: contentType,
+		Headers:     headers,
+		Body:        exchange.Body,
+	}
+}
+
+// newRecordedApi 根据上游响应生成 mock，Content-Type 中的字符集单独存储。
+// mock 的每个响应头只能存储一个值，多值响应头只录制第一个值
+func newRecordedApi(uid string, path string, method string, exchange *proxy.Exchange, matchers *matching.RequestMatchers) (*models.Api, error) {
+	headers := flattenHeader(exchange.Header)
+	rawContentType := headers[echo.HeaderContentType]
+	delete(headers, echo.HeaderContentType)
+	if rawContentType == "" {
+		rawContentType = http.DetectContentType(exchange.Body)
+	}
+	contentType, charset := rawContentType, ""
+	if mediaType, params, err := mime.ParseMediaType(rawContentType); err == nil {
+		contentType, charset = mediaType, params["charset"]
+	}
+
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+	var matchersJSON json.RawMessage
+	if matchers.Count() > 0 {
+		if matchersJSON, err = json.Marshal(matchers); err != nil {
+			return nil, err
+		}
+	}
+	return &models.Api{
+		UserId:          uid,
+		Uuid:            uuid2.NewString(),
+		Path:            path,
+		Method:          method,
+		StatusCode:      int16(exchange.StatusCode),
+		ContentType:     contentType,
+		Charset:         charset,
+		Headers:         headersJSON,
+		ResponseBody:    string(exchange.Body),
+		RequestMatchers: matchersJSON,
+	}, nil
+}
+
+// flattenHeader 每个响应头只取第一个值
+func flattenHeader(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for key := range header {
+		headers[key] = header.Get(key)
+	}
+	return headers
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"kite/internal/configs"
+	"kite/internal/models"
+	"kite/internal/proxy"
+	"kite/internal/repositories"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordUpdatesExistingMock(t *testing.T) {
+	version := "v1"
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(version + " " + r.URL.Query().Get("q")))
+	}))
+	defer upstream.Close()
+
+	repo := repositories.NewMemoryApiRepository()
+	service := NewProxyService(repo, proxy.NewForwarder(&configs.ProxyConfig{}))
+	namespace := &models.Namespace{Uid: "rec", Mode: proxy.ModeRecord, UpstreamUrl: upstream.URL, RecordConfig: []byte(`{"query":true}`)}
+	record := func(method string, target string) *models.Api {
+		t.Helper()
+		ctx := echo.New().NewContext(httptest.NewRequest(method, target, strings.NewReader("")), httptest.NewRecorder())
+		_, api, err := service.Record(ctx, namespace, strings.SplitN(target, "?", 2)[0])
+		if err != nil {
+			t.Fatalf("Record %s %s: %v", method, target, err)
+		}
+		return api
+	}
+
+	first := record(http.MethodGet, "/search?q=go")
+	version = "v2"
+	again := record(http.MethodGet, "/search?q=go")
+	other := record(http.MethodGet, "/search?q=rust")
+	post := record(http.MethodPost, "/search?q=go")
+
+	if again.Uuid != first.Uuid {
+		t.Fatalf("recording the same request again must update mock %s, created %s", first.Uuid, again.Uuid)
+	}
+	if other.Uuid == first.Uuid || post.Uuid == first.Uuid {
+		t.Fatalf("different matchers or methods must be recorded separately")
+	}
+	stored, err := repo.GetApiByUuid(context.Background(), first.Uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ResponseBody != "v2 go" {
+		t.Fatalf("recorded response = %q, want the latest upstream response", stored.ResponseBody)
+	}
+	_, total, err := repo.ListApis(context.Background(), repositories.ApiFilter{UserId: "rec"})
+	if err != nil || total != 3 {
+		t.Fatalf("recorded %d mocks (%v), want 3", total, err)
+	}
+}
+
+func TestPassthroughForwardsUnchanged(t *testing.T) {
+	var calls int
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		received = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Internal", "secret")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`{"host":"internal.example"}`))
+	}))
+	defer upstream.Close()
+
+	namespace := &models.Namespace{
+		Uid:          "fb",
+		FallbackUrl:  upstream.URL,
+		RewriteRules: []byte(`[{"remove_headers":["X-Internal"],"replace":[{"pattern":"internal\\.example","replacement":"public.example"}]}]`),
+	}
+	r := httptest.NewRequest(http.MethodPut, "/orders/1", strings.NewReader(`{"state":"paid"}`))
+	r.Header["X-Trace"] = []string{"a", "b"}
+	ctx := echo.New().NewContext(r, httptest.NewRecorder())
+	resp, err := NewProxyService(repositories.NewMemoryApiRepository(), proxy.NewForwarder(&configs.ProxyConfig{})).Passthrough(ctx, namespace, "/orders/1")
+	if err != nil {
+		t.Fatalf("Passthrough: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("upstream called %d times, a failing status must not be retried", calls)
+	}
+	if got := received["X-Trace"]; len(got) != 2 || received.Get("Content-Type") != "" || received.Get("Accept") != "" {
+		t.Fatalf("upstream headers = %v, want the client headers only", received)
+	}
+	if resp.StatusCode != http.StatusBadGateway || string(resp.Body) != `{"host":"public.example"}` || resp.Headers.Get("X-Internal") != "" {
+		t.Fatalf("unexpected passthrough response %+v", resp)
+	}
+}
+
+func TestPassthroughKeepsMultiValuedHeaders(t *testing.T) {
+	tests := []struct {
+		name            string
+		header          http.Header
+		wantHeaders     http.Header
+		wantContentType string
+	}{
+		{
+			name:        "two set-cookie values",
+			header:      http.Header{"Set-Cookie": {"session=abc; Path=/", "theme=dark; Path=/"}},
+			wantHeaders: http.Header{"Set-Cookie": {"session=abc; Path=/", "theme=dark; Path=/"}},
+		},
+		{
+			name:            "content type is not repeated in the headers",
+			header:          http.Header{"Content-Type": {"text/csv"}, "Vary": {"Accept", "Origin"}},
+			wantHeaders:     http.Header{"Vary": {"Accept", "Origin"}},
+			wantContentType: "text/csv",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer upstream.Close()
+
+			ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/login", nil), httptest.NewRecorder())
+			namespace := &models.Namespace{Uid: "fb", FallbackUrl: upstream.URL}
+			resp, err := NewProxyService(repositories.NewMemoryApiRepository(), proxy.NewForwarder(&configs.ProxyConfig{})).Passthrough(ctx, namespace, "/login")
+			if err != nil {
+				t.Fatalf("Passthrough: %v", err)
+			}
+			if resp.ContentType != tt.wantContentType {
+				t.Fatalf("content type = %q, want %q", resp.ContentType, tt.wantContentType)
+			}
+			for key, want := range tt.wantHeaders {
+				if got := resp.Headers.Values(key); !reflect.DeepEqual(got, want) {
+					t.Fatalf("header %s = %q, want %q", key, got, want)
+				}
+			}
+			if got := resp.Headers.Get(echo.HeaderContentType); got != "" {
+				t.Fatalf("Content-Type must only be carried in ContentType, got header %q", got)
+			}
+		})
+	}
+}
+
+func TestPassthroughUsesRequestContext(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(reqCtx)
+	ctx := echo.New().NewContext(r, httptest.NewRecorder())
+	namespace := &models.Namespace{Uid: "fb", FallbackUrl: upstream.URL}
+	if _, err := NewProxyService(repositories.NewMemoryApiRepository(), proxy.NewForwarder(&configs.ProxyConfig{})).Passthrough(ctx, namespace, "/slow"); err == nil {
+		t.Fatalf("Passthrough must stop once the client request is cancelled")
+	}
+}
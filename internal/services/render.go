@@ -0,0 +1,217 @@
+package services
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	KiteError "kite/internal/errors"
+	"kite/internal/models"
+	"kite/internal/sequence"
+	"kite/internal/simulation"
+	"kite/internal/templating"
+	"net/http"
+	"time"
+)
+
+// MockResponse 渲染完成、待写出的响应
+type MockResponse struct {
+	StatusCode  int
+	ContentType string
+	// Headers 同名响应头可以有多个值，例如透传上游的多个 Set-Cookie
+	Headers http.Header
+	Body    []byte
+	// Delay 写出响应前等待的时间
+	Delay time.Duration
+	// ThrottleBps 写出响应体的限速（字节/秒），0 表示不限速
+	ThrottleBps int
+	// Fault 本次请求触发的故障，未触发时为 nil
+	Fault *simulation.Fault
+}
+
+// Render 根据命中的 mock 生成响应：配置了响应序列时按调用次数选出本次的响应，
+// 普通模式原样返回存储的响应体，模板模式下响应头和响应体都按 text/template 渲染，路径参数通过 .PathParams 访问
+func (s *apiService) Render(ctx echo.Context, match *MockMatch) (*MockResponse, error) {
+	api := match.Api
+	headers, err := api.GetHeaders()
+	if err != nil {
+		return nil, KiteError.New(KiteError.UnmarshalError, err)
+	}
+	api, err = applySequence(api, headers, match.CallIndex)
+	if err != nil {
+		return nil, err
+	}
+	statusCode := int(api.StatusCode)
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	resp := &MockResponse{
+		StatusCode:  statusCode,
+		ContentType: api.GetContentTypeWithCharset(),
+	}
+	if err := s.applyNetworkProfile(ctx, api, resp); err != nil {
+		return nil, err
+	}
+	fault, err := simulation.DecodeFault(api.Fault)
+	if err != nil {
+		return nil, KiteError.New(KiteError.UnmarshalError, err)
+	}
+	if fault.Triggered() {
+		resp.Fault = fault
+	}
+	if !api.Template {
+		resp.Headers = newHeader(headers)
+		resp.Body = []byte(api.ResponseBody)
+		return resp, nil
+	}
+
+	data := newTemplateData(ctx, match)
+	for key, value := range headers {
+		rendered, err := templating.Render(value, data)
+		if err != nil {
+			return nil, KiteError.New(KiteError.TemplateRenderError, err).WithDetail(key)
+		}
+		headers[key] = rendered
+	}
+	resp.Headers = newHeader(headers)
+	body, err := templating.Render(api.ResponseBody, data)
+	if err != nil {
+		return nil, KiteError.New(KiteError.TemplateRenderError, err).WithDetail("response_body")
+	}
+	resp.Body = []byte(body)
+	return resp, nil
+}
+
+// newHeader 将 mock 存储的响应头转换为 http.Header，每个响应头只有一个值
+func newHeader(headers map[string]string) http.Header {
+	header := make(http.Header, len(headers))
+	for key, value := range headers {
+		header.Set(key, value)
+	}
+	return header
+}
+
+// applySequence 选出本次调用对应的响应，并将其覆盖到 mock 的副本上；headers 会被就地合并
+func applySequence(api *models.Api, headers map[string]string, callIndex int64) (*models.Api, error) {
+	steps, err := sequence.DecodeSteps(api.Responses)
+	if err != nil {
+		return nil, KiteError.New(KiteError.UnmarshalError, err)
+	}
+	if len(steps) == 0 {
+		return api, nil
+	}
+	step, ok := sequence.Select(steps, api.SequenceMode, callIndex)
+	if !ok {
+		return nil, KiteError.New(KiteError.SequenceExhaustedError, nil).
+			WithDetail(fmt.Sprintf("all %d responses of mock %s have been served", len(steps), api.Uuid))
+	}
+	effective := *api
+	if step.StatusCode != 0 {
+		effective.StatusCode = int16(step.StatusCode)
+	}
+	if step.ContentType != "" {
+		effective.ContentType = step.ContentType
+	}
+	if step.Body != nil {
+		effective.ResponseBody = *step.Body
+	}
+	for key, value := range step.Headers {
+		headers[key] = value
+	}
+	return &effective, nil
+}
+
+// applyNetworkProfile 计算延迟和限速，命名空间上的配置优先于 mock 自身的配置
+func (s *apiService) applyNetworkProfile(ctx echo.Context, api *models.Api, resp *MockResponse) error {
+	namespace, err := s.namespaces.Get(ctx, api.UserId)
+	if err != nil {
+		return err
+	}
+	rawDelay := api.Delay
+	if len(namespace.Delay) > 0 && string(namespace.Delay) != "null" {
+		rawDelay = namespace.Delay
+	}
+	delay, err := simulation.DecodeDelay(rawDelay)
+	if err != nil {
+		return KiteError.New(KiteError.UnmarshalError, err)
+	}
+	resp.Delay = delay.Sample()
+	resp.ThrottleBps = api.ThrottleBps
+	if namespace.ThrottleBps > 0 {
+		resp.ThrottleBps = namespace.ThrottleBps
+	}
+	return nil
+}
+
+// newTemplateData 收集模板可以访问的请求数据，多值字段只取第一个值
+func newTemplateData(ctx echo.Context, match *MockMatch) *templating.Data {
+	req := match.Request
+	data := &templating.Data{
+		Uid:        ctx.Param("uid"),
+		Method:     ctx.Request().Method,
+		Path:       match.Path,
+		PathParams: match.PathParams,
+		Query:      firstValues(req.Query),
+		Headers:    firstValues(req.Header),
+		Cookies:    req.Cookies,
+		Form:       firstValues(req.Form()),
+		RawBody:    string(req.Body),
+		RequestId:  ctx.Response().Header().Get(echo.HeaderXRequestID),
+	}
+	if body, err := req.JSON(); err == nil {
+		data.Body = body
+	}
+	return data
+}
+
+func firstValues(values map[string][]string) map[string]string {
+	result := make(map[string]string, len(values))
+	for key, list := range values {
+		if len(list) > 0 {
+			result[key] = list[0]
+		}
+	}
+	return result
+}
+
+// validateTemplates 模板模式下提前解析响应头、响应体以及响应序列中每个响应的响应头和响应体，避免保存无法渲染的 mock
+func validateTemplates(api *models.Api) error {
+	if !api.Template {
+		return nil
+	}
+	headers, err := api.GetHeaders()
+	if err != nil {
+		return KiteError.New(KiteError.UnmarshalError, err)
+	}
+	if err := parseTemplates("", headers, &api.ResponseBody); err != nil {
+		return err
+	}
+	steps, err := sequence.DecodeSteps(api.Responses)
+	if err != nil {
+		return KiteError.New(KiteError.UnmarshalError, err)
+	}
+	for i, step := range steps {
+		if err := parseTemplates(fmt.Sprintf("responses[%d].", i), step.Headers, step.Body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTemplates 解析一组响应头和响应体，prefix 用于在错误信息中指出所在的位置
+func parseTemplates(prefix string, headers map[string]string, body *string) error {
+	for key, value := range headers {
+		if _, err := templating.Parse(value); err != nil {
+			return KiteError.NewWithMessage(KiteError.ValidationError, "invalid template in "+prefix+"header "+key+": "+err.Error(), err)
+		}
+	}
+	if body == nil {
+		return nil
+	}
+	name := "response_body"
+	if prefix != "" {
+		name = prefix + "body"
+	}
+	if _, err := templating.Parse(*body); err != nil {
+		return KiteError.NewWithMessage(KiteError.ValidationError, "invalid template in "+name+": "+err.Error(), err)
+	}
+	return nil
+}
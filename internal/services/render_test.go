@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	KiteError "kite/internal/errors"
+	"kite/internal/sequence"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRenderPathParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		template bool
+		body     string
+		want     string
+	}{
+		{name: "raw body is replayed byte for byte", body: `{"id":"{id}","owner":"{owner}"}`, want: `{"id":"{id}","owner":"{owner}"}`},
+		{name: "template body reads path params", template: true, body: `{"id":"{{ .PathParams.id }}"}`, want: `{"id":"7"}`},
+		{name: "template body keeps literal braces", template: true, body: `{"id":"{id}","value":"{{ .PathParams.id }}"}`, want: `{"id":"{id}","value":"7"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newApiFixture()
+			payload := mockPayload("a", http.MethodGet, "/users/{id}", tt.body)
+			payload.Template = tt.template
+			createMock(t, service, payload)
+			ctx := apiContext(http.MethodGet, "/users/7", "")
+			match, err := service.Request(ctx, "a", "/users/7", http.MethodGet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := service.Render(ctx, match)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(resp.Body) != tt.want {
+				t.Fatalf("body = %s, want %s", resp.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  bool
+		headers   string
+		body      string
+		responses string
+		wantErr   string
+	}{
+		{name: "raw mode is not parsed", body: "{{ .Broken", responses: `[{"body":"{{ .Broken"}]`},
+		{name: "valid templates", template: true, headers: `{"X-Id":"{{ uuid }}"}`, body: "{{ .Path }}", responses: `[{"headers":{"X-Step":"{{ .Method }}"},"body":"{{ now | date \"DateOnly\" }}"},{"status_code":500}]`},
+		{name: "broken header", template: true, headers: `{"X-Id":"{{ uuid"}`, body: "ok", wantErr: "invalid template in header X-Id"},
+		{name: "broken body", template: true, body: "{{ if }}", wantErr: "invalid template in response_body"},
+		{name: "unknown function", template: true, body: "{{ shout .Path }}", wantErr: "invalid template in response_body"},
+		{name: "broken step body", template: true, body: "ok", responses: `[{"body":"ok"},{"body":"{{ .Query.page"}]`, wantErr: "invalid template in responses[1].body"},
+		{name: "broken step header", template: true, body: "ok", responses: `[{"headers":{"X-Step":"{{ end }}"}}]`, wantErr: "invalid template in responses[0].header X-Step"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := testApi("a", "/items", 0, "")
+			api.Template, api.ResponseBody = tt.template, tt.body
+			if tt.headers != "" {
+				api.Headers = json.RawMessage(tt.headers)
+			}
+			if tt.responses != "" {
+				api.Responses = json.RawMessage(tt.responses)
+			}
+			err := validateTemplates(api)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateTemplates: %v", err)
+				}
+				return
+			}
+			assertErrorCode(t, err, KiteError.ValidationError)
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderSequence(t *testing.T) {
+	first, third := "first", "third"
+	steps := []sequence.Step{
+		{Body: &first},
+		{StatusCode: http.StatusAccepted, Headers: map[string]string{"X-Step": "2"}},
+		{Body: &third, ContentType: "application/json"},
+	}
+	tests := []struct {
+		mode string
+		// want 每次调用的响应，"exhausted" 表示返回 SequenceExhaustedError
+		want []string
+	}{
+		{mode: sequence.ModeCycle, want: []string{"200 first", "202 base", "200 third", "200 first", "202 base"}},
+		{mode: sequence.ModeStick, want: []string{"200 first", "202 base", "200 third", "200 third", "200 third"}},
+		{mode: sequence.ModeFail, want: []string{"200 first", "202 base", "200 third", "exhausted", "exhausted"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			service := newApiFixture()
+			payload := mockPayload("a", http.MethodGet, "/seq", "base")
+			payload.Responses, payload.SequenceMode = steps, tt.mode
+			uuid := createMock(t, service, payload)
+			call := func() string {
+				ctx := apiContext(http.MethodGet, "/seq", "")
+				match, err := service.Request(ctx, "a", "/seq", http.MethodGet)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp, err := service.Render(ctx, match)
+				if appErr, ok := KiteError.IsAppError(err); ok && appErr.Code == KiteError.SequenceExhaustedError {
+					return "exhausted"
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if match.CallIndex == 1 && resp.Headers.Get("X-Step") != "2" {
+					t.Fatalf("step headers must be merged, got %v", resp.Headers)
+				}
+				return fmt.Sprintf("%d %s", resp.StatusCode, resp.Body)
+			}
+			for i, want := range tt.want {
+				if got := call(); got != want {
+					t.Fatalf("call %d = %q, want %q", i, got, want)
+				}
+			}
+			if err := service.ResetCalls(apiContext(http.MethodDelete, "/", ""), uuid); err != nil {
+				t.Fatal(err)
+			}
+			if got := call(); got != tt.want[0] {
+				t.Fatalf("after reset = %q, want the first response %q", got, tt.want[0])
+			}
+		})
+	}
+}
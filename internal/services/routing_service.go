@@ -0,0 +1,29 @@
+package services
+
+import (
+	"github.com/labstack/echo/v4"
+	"kite/internal/routing"
+)
+
+// RoutingService 查看和刷新 mock 请求使用的路由表
+type RoutingService interface {
+	Metrics(ctx echo.Context) routing.Metrics
+	// Refresh 丢弃全部已编译的路由，下一次请求时从存储重新加载
+	Refresh(ctx echo.Context)
+}
+
+type routingService struct {
+	routes *routing.Table
+}
+
+func NewRoutingService(routes *routing.Table) RoutingService {
+	return &routingService{routes}
+}
+
+func (s *routingService) Metrics(ctx echo.Context) routing.Metrics {
+	return s.routes.Metrics()
+}
+
+func (s *routingService) Refresh(ctx echo.Context) {
+	s.routes.InvalidateAll()
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"github.com/labstack/echo/v4"
+	KiteError "kite/internal/errors"
+	"kite/internal/repositories"
+	"kite/internal/state"
+)
+
+// ScenarioService 查看和重置命名空间下的场景状态
+type ScenarioService interface {
+	List(ctx echo.Context, uid string) (map[string]string, error)
+	Get(ctx echo.Context, uid string, name string) (string, error)
+	Set(ctx echo.Context, uid string, name string, current string) error
+	Reset(ctx echo.Context, uid string, name string) error
+	ResetAll(ctx echo.Context, uid string) error
+}
+
+type scenarioService struct {
+	repo  repositories.ApiRepository
+	state state.Store
+}
+
+func NewScenarioService(repo repositories.ApiRepository, state state.Store) ScenarioService {
+	return &scenarioService{repo, state}
+}
+
+// List 返回命名空间下的全部场景：mock 引用到的场景和被设置过状态的场景
+func (s *scenarioService) List(ctx echo.Context, uid string) (map[string]string, error) {
+	names, err := s.referencedScenarios(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	scenarios := s.state.Scenarios(uid)
+	for name := range names {
+		if _, ok := scenarios[name]; !ok {
+			scenarios[name] = state.ScenarioStarted
+		}
+	}
+	return scenarios, nil
+}
+
+func (s *scenarioService) Get(ctx echo.Context, uid string, name string) (string, error) {
+	if err := s.ensureExists(ctx, uid, name); err != nil {
+		return "", err
+	}
+	return s.state.ScenarioState(uid, name), nil
+}
+
+// Set 手动将场景切换到指定状态，便于测试直接从中间状态开始
+func (s *scenarioService) Set(ctx echo.Context, uid string, name string, current string) error {
+	if err := s.ensureExists(ctx, uid, name); err != nil {
+		return err
+	}
+	s.state.SetScenarioState(uid, name, current)
+	return nil
+}
+
+// Reset 将场景恢复到初始状态
+func (s *scenarioService) Reset(ctx echo.Context, uid string, name string) error {
+	if err := s.ensureExists(ctx, uid, name); err != nil {
+		return err
+	}
+	s.state.ResetScenario(uid, name)
+	return nil
+}
+
+// ResetAll 将命名空间下的全部场景恢复到初始状态
+func (s *scenarioService) ResetAll(ctx echo.Context, uid string) error {
+	s.state.ResetScenarios(uid)
+	return nil
+}
+
+func (s *scenarioService) ensureExists(ctx echo.Context, uid string, name string) error {
+	scenarios, err := s.List(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if _, ok := scenarios[name]; !ok {
+		return KiteError.New(KiteError.ScenarioNotFoundError, nil).WithDetail(name)
+	}
+	return nil
+}
+
+func (s *scenarioService) referencedScenarios(ctx echo.Context, uid string) (map[string]bool, error) {
+	apis, _, err := s.repo.ListApis(ctx.Request().Context(), repositories.ApiFilter{UserId: uid})
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	names := make(map[string]bool)
+	for _, api := range apis {
+		if api.ScenarioName != "" {
+			names[api.ScenarioName] = true
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"kite/internal/api/payloads"
+	KiteError "kite/internal/errors"
+	"kite/internal/state"
+	"net/http"
+	"testing"
+)
+
+func TestScenarioTransitions(t *testing.T) {
+	service := newApiFixture()
+	scenarioMock := func(method string, path string, body string, required string, next string) {
+		payload := mockPayload("shop", method, path, body)
+		payload.ScenarioName, payload.RequiredState, payload.NewState = "checkout", required, next
+		createMock(t, service, payload)
+	}
+	scenarioMock(http.MethodGet, "/cart", "empty", state.ScenarioStarted, "")
+	scenarioMock(http.MethodPost, "/cart", "added", "", "has_item")
+	scenarioMock(http.MethodGet, "/cart", "one item", "has_item", "")
+	scenarioMock(http.MethodPost, "/pay", "paid", "has_item", "paid")
+	ctx := apiContext(http.MethodGet, "/", "")
+
+	steps := []struct {
+		name string
+		// action 在请求之前执行，例如手动设置或重置场景
+		action    func() error
+		method    string
+		path      string
+		wantBody  string
+		wantState string
+	}{
+		{name: "initial state", method: http.MethodGet, path: "/cart", wantBody: "empty", wantState: state.ScenarioStarted},
+		{name: "required state not met", method: http.MethodPost, path: "/pay", wantState: state.ScenarioStarted},
+		{name: "hit moves to the new state", method: http.MethodPost, path: "/cart", wantBody: "added", wantState: "has_item"},
+		{name: "matches on the new state", method: http.MethodGet, path: "/cart", wantBody: "one item", wantState: "has_item"},
+		{name: "mock without new state keeps the state", method: http.MethodGet, path: "/cart", wantBody: "one item", wantState: "has_item"},
+		{name: "chained transition", method: http.MethodPost, path: "/pay", wantBody: "paid", wantState: "paid"},
+		{name: "no mock for the final state", method: http.MethodGet, path: "/cart", wantState: "paid"},
+		{
+			name:   "reset returns to the initial state",
+			action: func() error { return service.scenarios.Reset(ctx, "shop", "checkout") },
+			method: http.MethodGet, path: "/cart", wantBody: "empty", wantState: state.ScenarioStarted,
+		},
+		{
+			name:   "set jumps to an intermediate state",
+			action: func() error { return service.scenarios.Set(ctx, "shop", "checkout", "has_item") },
+			method: http.MethodGet, path: "/cart", wantBody: "one item", wantState: "has_item",
+		},
+		{
+			name:   "reset all",
+			action: func() error { return service.scenarios.ResetAll(ctx, "shop") },
+			method: http.MethodGet, path: "/cart", wantBody: "empty", wantState: state.ScenarioStarted,
+		},
+	}
+	for _, step := range steps {
+		if step.action != nil {
+			if err := step.action(); err != nil {
+				t.Fatalf("%s: %v", step.name, err)
+			}
+		}
+		match, err := service.Request(apiContext(step.method, step.path, ""), "shop", step.path, step.method)
+		switch {
+		case step.wantBody == "":
+			assertErrorCode(t, err, KiteError.MockNotMatchedError)
+		case err != nil:
+			t.Fatalf("%s: %v", step.name, err)
+		case match.Api.ResponseBody != step.wantBody:
+			t.Fatalf("%s: matched %q, want %q", step.name, match.Api.ResponseBody, step.wantBody)
+		}
+		if current, err := service.scenarios.Get(ctx, "shop", "checkout"); err != nil || current != step.wantState {
+			t.Fatalf("%s: state = %q, %v, want %q", step.name, current, err, step.wantState)
+		}
+	}
+}
+
+func TestScenarioStateMissReason(t *testing.T) {
+	service := newApiFixture()
+	payload := mockPayload("shop", http.MethodGet, "/orders", "orders")
+	payload.ScenarioName, payload.RequiredState = "checkout", "paid"
+	uuid := createMock(t, service, payload)
+
+	_, err := service.Request(apiContext(http.MethodGet, "/orders", ""), "shop", "/orders", http.MethodGet)
+	assertErrorCode(t, err, KiteError.MockNotMatchedError)
+	appErr, _ := KiteError.IsAppError(err)
+	details := appErr.Data.(payloads.MockMissDetails)
+	if len(details.Candidates) != 1 || details.Candidates[0].Uuid != uuid || details.Candidates[0].Reason != ReasonScenarioStateMismatch {
+		t.Fatalf("candidates = %+v, want the mock waiting for state paid", details.Candidates)
+	}
+}
+
+func TestScenarioService(t *testing.T) {
+	service := newApiFixture()
+	payload := mockPayload("shop", http.MethodGet, "/cart", "empty")
+	payload.ScenarioName = "checkout"
+	createMock(t, service, payload)
+	ctx := apiContext(http.MethodGet, "/", "")
+
+	scenarios, err := service.scenarios.List(ctx, "shop")
+	if err != nil || len(scenarios) != 1 || scenarios["checkout"] != state.ScenarioStarted {
+		t.Fatalf("List = %v, %v, want the referenced scenario in its initial state", scenarios, err)
+	}
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"get unknown scenario", func() error { _, err := service.scenarios.Get(ctx, "shop", "missing"); return err }},
+		{"set unknown scenario", func() error { return service.scenarios.Set(ctx, "shop", "missing", "x") }},
+		{"reset unknown scenario", func() error { return service.scenarios.Reset(ctx, "shop", "missing") }},
+		{"scenario of another namespace", func() error { _, err := service.scenarios.Get(ctx, "other", "checkout"); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, tt.call(), KiteError.ScenarioNotFoundError)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"kite/internal/api/payloads"
+	"kite/internal/auth"
+	KiteError "kite/internal/errors"
+	"kite/internal/models"
+	"kite/internal/repositories"
+	"kite/internal/tenant"
+	"kite/pkg/utils/security"
+	"slices"
+)
+
+// apiKeyDisplayLength 列表中展示的 API Key 前缀长度
+const apiKeyDisplayLength = 12
+
+// WorkspaceService 管理工作区、成员与 API Key，并检查命名空间的归属。
+// 除 Create、Authenticate 与 Assign 外，其余操作都作用于请求所属的工作区
+type WorkspaceService interface {
+	Create(ctx echo.Context, payload payloads.WorkspacePayload) (*models.Workspace, error)
+	// Authenticate 校验 API Key，返回 Key 所属的工作区
+	Authenticate(ctx echo.Context, key string) (*models.Workspace, error)
+	// AuthorizeNamespace 检查请求所属的工作区能否使用命名空间：命名空间下已有的 mock 或配置属于其他工作区（或不属于任何工作区）时拒绝，
+	// 未被使用过的命名空间可以被任意工作区使用。请求不属于任何工作区时不做检查
+	AuthorizeNamespace(ctx echo.Context, uid string) error
+	// AuthorizeUnscoped 检查不属于任何工作区的请求能否访问全部工作区的数据：只有通过认证的 admin 可以；
+	// 尚未创建任何工作区时数据都不属于工作区，不做限制
+	AuthorizeUnscoped(ctx echo.Context) error
+	Current(ctx echo.Context) (*models.Workspace, []*models.WorkspaceMember, error)
+	AddMember(ctx echo.Context, payload payloads.WorkspaceMemberPayload) (*models.WorkspaceMember, error)
+	RemoveMember(ctx echo.Context, userId string) error
+	ListKeys(ctx echo.Context) ([]*models.ApiKey, error)
+	// CreateKey 创建 API Key，返回的明文只有这一次机会获取
+	CreateKey(ctx echo.Context, payload payloads.ApiKeyPayload) (*models.ApiKey, string, error)
+	DeleteKey(ctx echo.Context, uuid string) error
+	// Assign 将不属于任何工作区的命名空间分配给工作区，返回分配的 mock 数量，用于启用工作区之前创建的数据
+	Assign(ctx echo.Context, workspaceId string, uid string) (int, error)
+}
+
+type workspaceService struct {
+	repo       repositories.WorkspaceRepository
+	apis       repositories.ApiRepository
+	namespaces repositories.NamespaceRepository
+}
+
+func NewWorkspaceService(repo repositories.WorkspaceRepository, apis repositories.ApiRepository, namespaces repositories.NamespaceRepository) WorkspaceService {
+	return &workspaceService{repo, apis, namespaces}
+}
+
+func (s *workspaceService) Create(ctx echo.Context, payload payloads.WorkspacePayload) (*models.Workspace, error) {
+	workspace := &models.Workspace{Uuid: uuid2.NewString(), Name: payload.Name}
+	if err := s.repo.CreateWorkspace(ctx.Request().Context(), workspace); err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	for _, userId := range payload.Members {
+		member := &models.WorkspaceMember{WorkspaceId: workspace.Uuid, UserId: userId}
+		if err := s.repo.AddMember(ctx.Request().Context(), member); err != nil {
+			return nil, KiteError.New(KiteError.DatabaseError, err)
+		}
+	}
+	return workspace, nil
+}
+
+func (s *workspaceService) Authenticate(ctx echo.Context, key string) (*models.Workspace, error) {
+	apiKey, err := s.repo.GetApiKeyByHash(ctx.Request().Context(), security.HashApiKey(key))
+	if err != nil {
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, KiteError.New(KiteError.UnauthorizedError, err).WithDetail("invalid api key")
+		}
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	workspace, err := s.repo.GetWorkspaceByUuid(ctx.Request().Context(), apiKey.WorkspaceId)
+	if err != nil {
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, KiteError.New(KiteError.UnauthorizedError, err).WithDetail("invalid api key")
+		}
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return workspace, nil
+}
+
+func (s *workspaceService) AuthorizeNamespace(ctx echo.Context, uid string) error {
+	workspace, ok := tenant.Workspace(ctx.Request().Context())
+	if !ok {
+		return nil
+	}
+	owner, err := s.namespaceOwner(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if owner != nil && *owner != workspace {
+		return KiteError.New(KiteError.ForbiddenError, nil).WithDetail(fmt.Sprintf("namespace %s belongs to another workspace", uid))
+	}
+	return nil
+}
+
+func (s *workspaceService) AuthorizeUnscoped(ctx echo.Context) error {
+	if principal, ok := auth.FromContext(ctx.Request().Context()); ok && principal.Role.Allows(auth.RoleAdmin) {
+		return nil
+	}
+	count, err := s.repo.CountWorkspaces(ctx.Request().Context())
+	if err != nil {
+		return KiteError.New(KiteError.DatabaseError, err)
+	}
+	if count > 0 {
+		return KiteError.New(KiteError.ForbiddenError, nil).WithDetail("a workspace API key is required once workspaces exist")
+	}
+	return nil
+}
+
+// namespaceOwner 返回命名空间所属的工作区，空字符串表示不属于任何工作区，nil 表示命名空间未被使用过。
+// 同一个命名空间下的记录总是属于同一个工作区，只需要检查配置和第一个 mock
+func (s *workspaceService) namespaceOwner(ctx echo.Context, uid string) (*string, error) {
+	unscoped := tenant.Unscoped(ctx.Request().Context())
+	namespace, err := s.namespaces.GetNamespaceByUid(unscoped, uid)
+	if err == nil {
+		return &namespace.WorkspaceId, nil
+	}
+	if !errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	apis, _, err := s.apis.ListApis(unscoped, repositories.ApiFilter{UserId: uid, Limit: 1})
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	if len(apis) == 0 {
+		return nil, nil
+	}
+	return &apis[0].WorkspaceId, nil
+}
+
+func (s *workspaceService) Current(ctx echo.Context) (*models.Workspace, []*models.WorkspaceMember, error) {
+	workspaceId, err := currentWorkspace(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	workspace, err := s.repo.GetWorkspaceByUuid(ctx.Request().Context(), workspaceId)
+	if err != nil {
+		return nil, nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	members, err := s.repo.ListMembers(ctx.Request().Context(), workspaceId)
+	if err != nil {
+		return nil, nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return workspace, members, nil
+}
+
+func (s *workspaceService) AddMember(ctx echo.Context, payload payloads.WorkspaceMemberPayload) (*models.WorkspaceMember, error) {
+	workspaceId, err := currentWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx.Request().Context(), workspaceId)
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	if slices.ContainsFunc(members, func(member *models.WorkspaceMember) bool { return member.UserId == payload.UserId }) {
+		return nil, KiteError.NewWithMessage(KiteError.BadRequestError, "member already exists", nil).WithDetail(payload.UserId)
+	}
+	member := &models.WorkspaceMember{WorkspaceId: workspaceId, UserId: payload.UserId}
+	if err := s.repo.AddMember(ctx.Request().Context(), member); err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return member, nil
+}
+
+func (s *workspaceService) RemoveMember(ctx echo.Context, userId string) error {
+	workspaceId, err := currentWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RemoveMember(ctx.Request().Context(), workspaceId, userId); err != nil {
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return KiteError.New(KiteError.NotFoundError, err).WithDetail("member " + userId)
+		}
+		return KiteError.New(KiteError.DatabaseError, err)
+	}
+	return nil
+}
+
+func (s *workspaceService) ListKeys(ctx echo.Context) ([]*models.ApiKey, error) {
+	workspaceId, err := currentWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.repo.ListApiKeys(ctx.Request().Context(), workspaceId)
+	if err != nil {
+		return nil, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return keys, nil
+}
+
+func (s *workspaceService) CreateKey(ctx echo.Context, payload payloads.ApiKeyPayload) (*models.ApiKey, string, error) {
+	workspaceId, err := currentWorkspace(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := security.GenerateApiKey()
+	if err != nil {
+		return nil, "", KiteError.New(KiteError.InternalServerError, err)
+	}
+	key := &models.ApiKey{
+		Uuid:        uuid2.NewString(),
+		WorkspaceId: workspaceId,
+		Name:        payload.Name,
+		Prefix:      secret[:apiKeyDisplayLength],
+		Hash:        security.HashApiKey(secret),
+	}
+	if err := s.repo.CreateApiKey(ctx.Request().Context(), key); err != nil {
+		return nil, "", KiteError.New(KiteError.DatabaseError, err)
+	}
+	return key, secret, nil
+}
+
+func (s *workspaceService) DeleteKey(ctx echo.Context, uuid string) error {
+	workspaceId, err := currentWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteApiKey(ctx.Request().Context(), workspaceId, uuid); err != nil {
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return KiteError.New(KiteError.NotFoundError, err).WithDetail("api key " + uuid)
+		}
+		return KiteError.New(KiteError.DatabaseError, err)
+	}
+	return nil
+}
+
+func (s *workspaceService) Assign(ctx echo.Context, workspaceId string, uid string) (int, error) {
+	if _, err := s.repo.GetWorkspaceByUuid(ctx.Request().Context(), workspaceId); err != nil {
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return 0, KiteError.New(KiteError.NotFoundError, err).WithDetail("workspace " + workspaceId)
+		}
+		return 0, KiteError.New(KiteError.DatabaseError, err)
+	}
+	owner, err := s.namespaceOwner(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+	if owner != nil && *owner != "" && *owner != workspaceId {
+		return 0, KiteError.New(KiteError.ForbiddenError, nil).WithDetail(fmt.Sprintf("namespace %s belongs to another workspace", uid))
+	}
+	// 不限定工作区时仓储按记录中的 WorkspaceId 原样写入
+	unscoped := tenant.Unscoped(ctx.Request().Context())
+	apis, _, err := s.apis.ListApis(unscoped, repositories.ApiFilter{UserId: uid})
+	if err != nil {
+		return 0, KiteError.New(KiteError.DatabaseError, err)
+	}
+	assigned := 0
+	for _, api := range apis {
+		if api.WorkspaceId != "" {
+			continue
+		}
+		api.WorkspaceId = workspaceId
+		if err := s.apis.UpdateApi(unscoped, api); err != nil {
+			return assigned, KiteError.New(KiteError.ApiUpdateError, err)
+		}
+		assigned++
+	}
+	namespace, err := s.namespaces.GetNamespaceByUid(unscoped, uid)
+	switch {
+	case err == nil && namespace.WorkspaceId == "":
+		namespace.WorkspaceId = workspaceId
+		if err := s.namespaces.SaveNamespace(unscoped, namespace); err != nil {
+			return assigned, KiteError.New(KiteError.NamespaceUpdateError, err)
+		}
+	case err != nil && !errors.Is(err, repositories.ErrRecordNotFound):
+		return assigned, KiteError.New(KiteError.DatabaseError, err)
+	}
+	return assigned, nil
+}
+
+// currentWorkspace 返回请求所属的工作区，工作区相关的接口必须携带 API Key
+func currentWorkspace(ctx echo.Context) (string, error) {
+	workspaceId, ok := tenant.Workspace(ctx.Request().Context())
+	if !ok {
+		return "", KiteError.New(KiteError.UnauthorizedError, nil).WithDetail("an api key is required")
+	}
+	return workspaceId, nil
+}
@@ -0,0 +1,120 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// 延迟分布类型
+const (
+	DelayFixed     = "fixed"
+	DelayUniform   = "uniform"
+	DelayNormal    = "normal"
+	DelayLogNormal = "lognormal"
+)
+
+// Delay 响应延迟配置：
+//
+//	fixed      固定 fixed_ms
+//	uniform    [min_ms, max_ms] 均匀分布
+//	normal     均值 mean_ms、标准差 stddev_ms 的正态分布
+//	lognormal  中位数 median_ms、对数标准差 sigma 的对数正态分布，适合模拟长尾
+//
+// normal 和 lognormal 设置了 max_ms 时会截断到该上限
+type Delay struct {
+	Distribution string  `json:"distribution" validate:"omitempty,oneof=fixed uniform normal lognormal"`
+	FixedMs      int     `json:"fixed_ms,omitempty" validate:"gte=0"`
+	MinMs        int     `json:"min_ms,omitempty" validate:"gte=0"`
+	MaxMs        int     `json:"max_ms,omitempty" validate:"gte=0"`
+	MeanMs       float64 `json:"mean_ms,omitempty" validate:"gte=0"`
+	StdDevMs     float64 `json:"stddev_ms,omitempty" validate:"gte=0"`
+	MedianMs     float64 `json:"median_ms,omitempty" validate:"gte=0"`
+	Sigma        float64 `json:"sigma,omitempty" validate:"gte=0"`
+}
+
+// IsZero 未配置任何参数的延迟视为不延迟
+func (d *Delay) IsZero() bool {
+	return d == nil || *d == Delay{}
+}
+
+// Validate 校验不同分布所需的参数
+func (d *Delay) Validate() error {
+	if d.IsZero() {
+		return nil
+	}
+	switch d.Distribution {
+	case DelayFixed:
+		return nil
+	case DelayUniform:
+		if d.MaxMs < d.MinMs {
+			return errors.New("delay max_ms must be greater than or equal to min_ms")
+		}
+	case DelayNormal:
+		if d.MeanMs <= 0 {
+			return errors.New("normal delay requires mean_ms")
+		}
+	case DelayLogNormal:
+		if d.MedianMs <= 0 {
+			return errors.New("lognormal delay requires median_ms")
+		}
+	default:
+		return errors.New("delay distribution must be one of [fixed uniform normal lognormal]")
+	}
+	return nil
+}
+
+// Sample 按分布采样一次延迟时间
+func (d *Delay) Sample() time.Duration {
+	if d == nil {
+		return 0
+	}
+	var ms float64
+	switch d.Distribution {
+	case DelayFixed:
+		ms = float64(d.FixedMs)
+	case DelayUniform:
+		ms = float64(d.MinMs) + rand.Float64()*float64(d.MaxMs-d.MinMs)
+	case DelayNormal:
+		ms = d.MeanMs + rand.NormFloat64()*d.StdDevMs
+	case DelayLogNormal:
+		ms = d.MedianMs * math.Exp(rand.NormFloat64()*d.Sigma)
+	}
+	if d.MaxMs > 0 && (d.Distribution == DelayNormal || d.Distribution == DelayLogNormal) {
+		ms = math.Min(ms, float64(d.MaxMs))
+	}
+	if ms < 0 {
+		ms = 0
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// DecodeDelay 解析存储的延迟配置，未配置时返回 nil
+func DecodeDelay(raw json.RawMessage) (*Delay, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var delay Delay
+	if err := json.Unmarshal(raw, &delay); err != nil {
+		return nil, err
+	}
+	return &delay, nil
+}
+
+// Sleep 等待指定时间，客户端断开时提前返回
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
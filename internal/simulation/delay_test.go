@@ -0,0 +1,45 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDelayValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay *Delay
+		valid bool
+	}{
+		{"nil", nil, true},
+		{"empty clears the delay", &Delay{}, true},
+		{"fixed", &Delay{Distribution: DelayFixed, FixedMs: 10}, true},
+		{"parameters without distribution", &Delay{FixedMs: 10}, false},
+		{"uniform with inverted range", &Delay{Distribution: DelayUniform, MinMs: 5, MaxMs: 1}, false},
+		{"normal without mean", &Delay{Distribution: DelayNormal, StdDevMs: 3}, false},
+		{"lognormal without median", &Delay{Distribution: DelayLogNormal, Sigma: 0.5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.delay.Validate(); (err == nil) != tt.valid {
+				t.Fatalf("Validate = %v, want valid=%v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestDelaySample(t *testing.T) {
+	if got := (&Delay{Distribution: DelayFixed, FixedMs: 25}).Sample(); got != 25*time.Millisecond {
+		t.Fatalf("fixed Sample = %v", got)
+	}
+	uniform := &Delay{Distribution: DelayUniform, MinMs: 10, MaxMs: 20}
+	capped := &Delay{Distribution: DelayLogNormal, MedianMs: 100, Sigma: 3, MaxMs: 150}
+	for i := 0; i < 100; i++ {
+		if got := uniform.Sample(); got < 10*time.Millisecond || got > 20*time.Millisecond {
+			t.Fatalf("uniform Sample = %v out of range", got)
+		}
+		if got := capped.Sample(); got > 150*time.Millisecond {
+			t.Fatalf("lognormal Sample = %v exceeds max_ms", got)
+		}
+	}
+}
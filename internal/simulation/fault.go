@@ -0,0 +1,193 @@
+package simulation
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// 故障类型
+const (
+	// FaultConnectionReset 不返回任何数据，直接以 RST 重置 TCP 连接
+	FaultConnectionReset = "connection_reset"
+	// FaultCloseAfterHeaders 写出响应头后关闭连接
+	FaultCloseAfterHeaders = "close_after_headers"
+	// FaultTruncatedBody 声明完整的 Content-Length，只写出一半响应体后关闭连接
+	FaultTruncatedBody = "truncated_body"
+	// FaultMalformedBody 返回被破坏的响应体，例如无法解析的 JSON
+	FaultMalformedBody = "malformed_body"
+	// FaultRandomData 返回随机字节
+	FaultRandomData = "random_data"
+	// FaultHang 不返回任何数据，直到客户端断开
+	FaultHang = "hang"
+	// FaultErrorStatus 返回指定的错误状态码
+	FaultErrorStatus = "error_status"
+)
+
+// random_data 默认返回的字节数，以及允许配置的最大字节数
+const (
+	defaultRandomBytes = 1024
+	MaxRandomBytes     = 1 << 20
+)
+
+// ErrHijackNotSupported 当前连接不支持接管（例如 HTTP/2），无法模拟连接级故障
+var ErrHijackNotSupported = errors.New("connection does not support hijacking")
+
+// Fault 故障注入配置。Percentage 为触发概率（0-100），未设置时每次都触发
+type Fault struct {
+	Type        string  `json:"type" validate:"omitempty,oneof=connection_reset close_after_headers truncated_body malformed_body random_data hang error_status"`
+	Percentage  float64 `json:"percentage,omitempty" validate:"gte=0,lte=100"`
+	StatusCode  int     `json:"status_code,omitempty" validate:"omitempty,gte=100,lte=599"`
+	Body        string  `json:"body,omitempty"`
+	RandomBytes int     `json:"random_bytes,omitempty" validate:"gte=0,lte=1048576"`
+}
+
+// IsZero 未配置任何参数的故障视为不注入故障
+func (f *Fault) IsZero() bool {
+	return f == nil || *f == Fault{}
+}
+
+// Validate 校验故障类型和触发概率，导入等不经过请求校验的入口也依赖这里
+func (f *Fault) Validate() error {
+	if f.IsZero() {
+		return nil
+	}
+	switch f.Type {
+	case FaultConnectionReset, FaultCloseAfterHeaders, FaultTruncatedBody, FaultMalformedBody,
+		FaultRandomData, FaultHang, FaultErrorStatus:
+	default:
+		return errors.New("fault type must be one of [connection_reset close_after_headers truncated_body malformed_body random_data hang error_status]")
+	}
+	if f.Percentage < 0 || f.Percentage > 100 {
+		return errors.New("fault percentage must be between 0 and 100")
+	}
+	if f.StatusCode != 0 && (f.StatusCode < 100 || f.StatusCode > 599) {
+		return errors.New("fault status_code must be between 100 and 599")
+	}
+	if f.RandomBytes < 0 || f.RandomBytes > MaxRandomBytes {
+		return fmt.Errorf("fault random_bytes must be between 0 and %d", MaxRandomBytes)
+	}
+	return nil
+}
+
+// DecodeFault 解析存储的故障配置，未配置时返回 nil
+func DecodeFault(raw json.RawMessage) (*Fault, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var fault Fault
+	if err := json.Unmarshal(raw, &fault); err != nil {
+		return nil, err
+	}
+	return &fault, nil
+}
+
+// Triggered 按概率决定本次请求是否触发故障
+func (f *Fault) Triggered() bool {
+	if f == nil || f.Type == "" {
+		return false
+	}
+	if f.Percentage <= 0 || f.Percentage >= 100 {
+		return true
+	}
+	return rand.Float64()*100 < f.Percentage
+}
+
+// Inject 执行故障注入。调用前响应头应已设置好；statusCode、contentType、body 为正常情况下要写出的响应
+func (f *Fault) Inject(ctx context.Context, w http.ResponseWriter, statusCode int, contentType string, body []byte) error {
+	header := w.Header()
+	switch f.Type {
+	case FaultConnectionReset:
+		return resetConnection(w)
+	case FaultCloseAfterHeaders:
+		header.Set("Content-Type", contentType)
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(statusCode)
+		return closeConnection(w)
+	case FaultTruncatedBody:
+		header.Set("Content-Type", contentType)
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(statusCode)
+		if _, err := w.Write(body[:len(body)/2]); err != nil {
+			return err
+		}
+		return closeConnection(w)
+	case FaultMalformedBody:
+		return writeBody(w, statusCode, contentType, malform(body))
+	case FaultRandomData:
+		size := f.RandomBytes
+		if size <= 0 {
+			size = defaultRandomBytes
+		}
+		data := make([]byte, size)
+		_, _ = crand.Read(data)
+		return writeBody(w, statusCode, contentType, data)
+	case FaultHang:
+		<-ctx.Done()
+		return nil
+	case FaultErrorStatus:
+		status := f.StatusCode
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		return writeBody(w, status, contentType, []byte(f.Body))
+	}
+	return writeBody(w, statusCode, contentType, body)
+}
+
+func writeBody(w http.ResponseWriter, statusCode int, contentType string, body []byte) error {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(statusCode)
+	_, err := w.Write(body)
+	return err
+}
+
+// malform 截掉响应体的后三分之一并追加无法解析的字符
+func malform(body []byte) []byte {
+	malformed := make([]byte, 0, len(body)+4)
+	malformed = append(malformed, body[:len(body)-len(body)/3]...)
+	return append(malformed, []byte("\x00}{\"")...)
+}
+
+// closeConnection 将已写出的数据发送给客户端后关闭连接
+func closeConnection(w http.ResponseWriter) error {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	conn, err := hijack(w)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// resetConnection 设置 SO_LINGER 为 0 后关闭连接，使内核发送 RST 而不是 FIN
+func resetConnection(w http.ResponseWriter) error {
+	conn, err := hijack(w)
+	if err != nil {
+		return err
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		_ = tcpConn.SetLinger(0)
+	}
+	return conn.Close()
+}
+
+func hijack(w http.ResponseWriter) (net.Conn, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, ErrHijackNotSupported
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
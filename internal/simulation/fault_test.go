@@ -0,0 +1,41 @@
+package simulation
+
+import "testing"
+
+func TestFaultValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault *Fault
+		valid bool
+	}{
+		{"nil", nil, true},
+		{"empty clears the fault", &Fault{}, true},
+		{"hang", &Fault{Type: FaultHang}, true},
+		{"error status with percentage", &Fault{Type: FaultErrorStatus, Percentage: 25, StatusCode: 503}, true},
+		{"unknown type", &Fault{Type: "explode"}, false},
+		{"percentage without type", &Fault{Percentage: 50}, false},
+		{"negative percentage", &Fault{Type: FaultHang, Percentage: -1}, false},
+		{"percentage above 100", &Fault{Type: FaultHang, Percentage: 101}, false},
+		{"invalid status code", &Fault{Type: FaultErrorStatus, StatusCode: 42}, false},
+		{"negative random bytes", &Fault{Type: FaultRandomData, RandomBytes: -1}, false},
+		{"random bytes at the limit", &Fault{Type: FaultRandomData, RandomBytes: MaxRandomBytes}, true},
+		{"random bytes above the limit", &Fault{Type: FaultRandomData, RandomBytes: MaxRandomBytes + 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fault.Validate(); (err == nil) != tt.valid {
+				t.Fatalf("Validate = %v, want valid=%v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestFaultTriggered(t *testing.T) {
+	var none *Fault
+	if none.Triggered() || (&Fault{}).Triggered() {
+		t.Fatalf("an unset fault must never trigger")
+	}
+	if !(&Fault{Type: FaultHang}).Triggered() || !(&Fault{Type: FaultHang, Percentage: 100}).Triggered() {
+		t.Fatalf("a fault without percentage or at 100 must always trigger")
+	}
+}
@@ -0,0 +1,41 @@
+package simulation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// throttleTick 限速写出时每批数据的间隔
+const throttleTick = 100 * time.Millisecond
+
+// WriteThrottled 按每秒 bytesPerSecond 字节的速度分批写出数据，每批写完后立即 flush，
+// 客户端断开时提前返回
+func WriteThrottled(ctx context.Context, w io.Writer, data []byte, bytesPerSecond int) error {
+	if bytesPerSecond <= 0 {
+		_, err := w.Write(data)
+		return err
+	}
+	chunkSize := bytesPerSecond * int(throttleTick) / int(time.Second)
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	interval := time.Duration(chunkSize) * time.Second / time.Duration(bytesPerSecond)
+	flusher, _ := w.(http.Flusher)
+	for offset := 0; offset < len(data); offset += chunkSize {
+		end := min(offset+chunkSize, len(data))
+		if _, err := w.Write(data[offset:end]); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if end < len(data) {
+			if err := Sleep(ctx, interval); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
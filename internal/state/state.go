@@ -0,0 +1,97 @@
+package state
+
+import (
+	"sync"
+)
+
+// ScenarioStarted 场景的初始状态
+const ScenarioStarted = "Started"
+
+// Store 保存 mock 运行时的状态，例如调用次数和场景状态
+type Store interface {
+	// IncrementCalls 调用次数加一并返回加一之后的次数
+	IncrementCalls(uuid string) int64
+	Calls(uuid string) int64
+	ResetCalls(uuid string)
+
+	// ScenarioState 返回场景的当前状态，未设置过时为 ScenarioStarted
+	ScenarioState(uid string, name string) string
+	SetScenarioState(uid string, name string, state string)
+	// Scenarios 返回命名空间下所有被设置过状态的场景
+	Scenarios(uid string) map[string]string
+	ResetScenario(uid string, name string)
+	ResetScenarios(uid string)
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	calls     map[string]int64
+	scenarios map[string]map[string]string
+}
+
+// NewMemoryStore 创建进程内的状态存储，服务重启后状态清空
+func NewMemoryStore() Store {
+	return &memoryStore{
+		calls:     make(map[string]int64),
+		scenarios: make(map[string]map[string]string),
+	}
+}
+
+func (s *memoryStore) IncrementCalls(uuid string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[uuid]++
+	return s.calls[uuid]
+}
+
+func (s *memoryStore) Calls(uuid string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[uuid]
+}
+
+func (s *memoryStore) ResetCalls(uuid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.calls, uuid)
+}
+
+func (s *memoryStore) ScenarioState(uid string, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.scenarios[uid][name]; ok {
+		return current
+	}
+	return ScenarioStarted
+}
+
+func (s *memoryStore) SetScenarioState(uid string, name string, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scenarios[uid] == nil {
+		s.scenarios[uid] = make(map[string]string)
+	}
+	s.scenarios[uid][name] = state
+}
+
+func (s *memoryStore) Scenarios(uid string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]string, len(s.scenarios[uid]))
+	for name, current := range s.scenarios[uid] {
+		result[name] = current
+	}
+	return result
+}
+
+func (s *memoryStore) ResetScenario(uid string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.scenarios[uid], name)
+}
+
+func (s *memoryStore) ResetScenarios(uid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.scenarios, uid)
+}
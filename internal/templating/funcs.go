@@ -0,0 +1,196 @@
+package templating
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	uuid2 "github.com/google/uuid"
+)
+
+const randomAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// 可以在 date 中直接使用的时间格式名称
+var namedLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// funcMap 模板中可用的辅助函数
+func funcMap() template.FuncMap {
+	return template.FuncMap{
+		"uuid":         uuid2.NewString,
+		"now":          time.Now,
+		"date":         formatDate,
+		"unix":         func(t time.Time) int64 { return t.Unix() },
+		"unixMilli":    func(t time.Time) int64 { return t.UnixMilli() },
+		"addDuration":  addDuration,
+		"randomInt":    randomInt,
+		"randomFloat":  randomFloat,
+		"randomString": randomString,
+		"randomChoice": randomChoice,
+		"base64Encode": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"base64Decode": base64Decode,
+		"jsonEscape":   jsonEscape,
+		"toJson":       toJSON,
+		"upper":        strings.ToUpper,
+		"lower":        strings.ToLower,
+		"default":      defaultValue,
+		"add":          func(a, b interface{}) (interface{}, error) { return arithmetic(a, b, '+') },
+		"sub":          func(a, b interface{}) (interface{}, error) { return arithmetic(a, b, '-') },
+		"mul":          func(a, b interface{}) (interface{}, error) { return arithmetic(a, b, '*') },
+		"div":          func(a, b interface{}) (interface{}, error) { return arithmetic(a, b, '/') },
+		"mod":          func(a, b interface{}) (interface{}, error) { return arithmetic(a, b, '%') },
+	}
+}
+
+// formatDate 按 Go 时间格式或预定义名称格式化时间，例如 {{ now | date "2006-01-02" }}
+func formatDate(layout string, t time.Time) string {
+	if named, ok := namedLayouts[layout]; ok {
+		layout = named
+	}
+	return t.Format(layout)
+}
+
+// addDuration 时间偏移，例如 {{ now | addDuration "-24h" | date "DateOnly" }}
+func addDuration(duration string, t time.Time) (time.Time, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return t, err
+	}
+	return t.Add(d), nil
+}
+
+// randomInt 返回 [min, max] 之间的随机整数
+func randomInt(min, max int) (int, error) {
+	if max < min {
+		return 0, fmt.Errorf("randomInt: max %d is less than min %d", max, min)
+	}
+	return min + rand.IntN(max-min+1), nil
+}
+
+// randomFloat 返回 [min, max) 之间的随机小数
+func randomFloat(min, max float64) (float64, error) {
+	if max < min {
+		return 0, fmt.Errorf("randomFloat: max %v is less than min %v", max, min)
+	}
+	return min + rand.Float64()*(max-min), nil
+}
+
+func randomString(length int) string {
+	var builder strings.Builder
+	for i := 0; i < length; i++ {
+		builder.WriteByte(randomAlphabet[rand.IntN(len(randomAlphabet))])
+	}
+	return builder.String()
+}
+
+func randomChoice(choices ...interface{}) (interface{}, error) {
+	if len(choices) == 0 {
+		return nil, errors.New("randomChoice: no choices")
+	}
+	return choices[rand.IntN(len(choices))], nil
+}
+
+func base64Decode(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// jsonEscape 转义字符串，使其可以直接放在 JSON 字符串的引号中
+func jsonEscape(value interface{}) (string, error) {
+	data, err := json.Marshal(fmt.Sprint(value))
+	if err != nil {
+		return "", err
+	}
+	return string(data[1 : len(data)-1]), nil
+}
+
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func defaultValue(fallback interface{}, value interface{}) interface{} {
+	if value == nil {
+		return fallback
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return fallback
+	}
+	return value
+}
+
+// arithmetic 四则运算，参数可以是数字或数字字符串；结果为整数时返回 int64
+func arithmetic(a, b interface{}, op rune) (interface{}, error) {
+	x, err := toFloat(a)
+	if err != nil {
+		return nil, err
+	}
+	y, err := toFloat(b)
+	if err != nil {
+		return nil, err
+	}
+	var result float64
+	switch op {
+	case '+':
+		result = x + y
+	case '-':
+		result = x - y
+	case '*':
+		result = x * y
+	case '/':
+		if y == 0 {
+			return nil, errors.New("division by zero")
+		}
+		result = x / y
+	case '%':
+		if y == 0 {
+			return nil, errors.New("division by zero")
+		}
+		result = math.Mod(x, y)
+	}
+	if result == math.Trunc(result) && math.Abs(result) < math.MaxInt64 {
+		return int64(result), nil
+	}
+	return result, nil
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	default:
+		return 0, fmt.Errorf("cannot use %v (%T) as a number", value, value)
+	}
+}
@@ -0,0 +1,100 @@
+package templating
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestHelpers(t *testing.T) {
+	data := &Data{
+		Path:       "/users/7",
+		PathParams: map[string]string{"id": "7"},
+		Query:      map[string]string{"page": "2"},
+		Headers:    map[string]string{"X-Tier": "vip"},
+		Body:       map[string]interface{}{"name": `Ann "A"`, "count": 3.0},
+	}
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		pattern  string
+	}{
+		{name: "uuid", template: "{{ uuid }}", pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`},
+		{name: "date with a named layout", template: `{{ now | date "DateOnly" }}`, pattern: `^\d{4}-\d{2}-\d{2}$`},
+		{name: "date with a go layout", template: `{{ now | date "2006" }}`, pattern: `^\d{4}$`},
+		{name: "unix", template: "{{ now | unix }}", pattern: `^\d{10}$`},
+		{name: "unixMilli", template: "{{ now | unixMilli }}", pattern: `^\d{13}$`},
+		{name: "addDuration", template: `{{ $t := now }}{{ sub ($t | addDuration "1h" | unix) ($t | unix) }}`, want: "3600"},
+		{name: "randomInt within bounds", template: "{{ randomInt 5 5 }}", want: "5"},
+		{name: "randomFloat within bounds", template: "{{ randomFloat 1.5 1.5 }}", want: "1.5"},
+		{name: "randomString length", template: "{{ randomString 12 }}", pattern: `^[a-zA-Z0-9]{12}$`},
+		{name: "randomChoice", template: `{{ randomChoice "only" }}`, want: "only"},
+		{name: "base64Encode", template: `{{ base64Encode "kite" }}`, want: "a2l0ZQ=="},
+		{name: "base64Decode", template: `{{ base64Decode "a2l0ZQ==" }}`, want: "kite"},
+		{name: "jsonEscape", template: `{"name":"{{ jsonEscape .Body.name }}"}`, want: `{"name":"Ann \"A\""}`},
+		{name: "toJson", template: "{{ toJson .PathParams }}", want: `{"id":"7"}`},
+		{name: "upper", template: `{{ upper (index .Headers "X-Tier") }}`, want: "VIP"},
+		{name: "lower", template: `{{ lower "VIP" }}`, want: "vip"},
+		{name: "default for a missing value", template: `{{ default "1" .Query.missing }}`, want: "1"},
+		{name: "default keeps a present value", template: `{{ default "1" .Query.page }}`, want: "2"},
+		{name: "add numbers and numeric strings", template: "{{ add .PathParams.id 3 }}", want: "10"},
+		{name: "sub", template: "{{ sub .Body.count 5 }}", want: "-2"},
+		{name: "mul", template: `{{ mul "1.5" 2 }}`, want: "3"},
+		{name: "div keeps fractions", template: "{{ div 7 2 }}", want: "3.5"},
+		{name: "mod", template: "{{ mod 7 4 }}", want: "3"},
+		{name: "missing key renders empty", template: "[{{ .Query.nothing }}]", want: "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.template, data)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if tt.pattern != "" {
+				if !regexp.MustCompile(tt.pattern).MatchString(got) {
+					t.Fatalf("Render = %q, want it to match %s", got, tt.pattern)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Fatalf("Render = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelperErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{name: "randomInt with max below min", template: "{{ randomInt 5 1 }}"},
+		{name: "randomFloat with max below min", template: "{{ randomFloat 2.0 1.0 }}"},
+		{name: "randomChoice without choices", template: "{{ randomChoice }}"},
+		{name: "base64Decode of invalid input", template: `{{ base64Decode "%%%" }}`},
+		{name: "addDuration with an invalid duration", template: `{{ now | addDuration "soon" }}`},
+		{name: "division by zero", template: "{{ div 1 0 }}"},
+		{name: "modulo by zero", template: "{{ mod 1 0 }}"},
+		{name: "arithmetic on a non-number", template: `{{ add "seven" 1 }}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Render(tt.template, &Data{}); err == nil {
+				t.Fatalf("Render = %q, want an error", got)
+			}
+		})
+	}
+}
+
+func TestRandomIntRange(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		got, err := Render("{{ randomInt 1 3 }}", &Data{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := strconv.Atoi(got); n < 1 || n > 3 {
+			t.Fatalf("randomInt 1 3 = %s", got)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package templating
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// Data 渲染模板时可以访问的请求数据，例如：
+//
+//	{{ .PathParams.id }}  {{ .Query.page }}  {{ index .Headers "X-Tier" }}
+//	{{ .Body.user.name }}  {{ .RequestId }}
+type Data struct {
+	Uid        string
+	Method     string
+	Path       string
+	PathParams map[string]string
+	Query      map[string]string
+	Headers    map[string]string
+	Cookies    map[string]string
+	Form       map[string]string
+	Body       interface{}
+	RawBody    string
+	RequestId  string
+}
+
+// Parse 解析模板，用于在保存 mock 时提前发现语法错误
+func Parse(text string) (*template.Template, error) {
+	return template.New("mock").Funcs(funcMap()).Option("missingkey=zero").Parse(text)
+}
+
+// Render 使用请求数据渲染模板
+func Render(text string, data *Data) (string, error) {
+	tmpl, err := Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
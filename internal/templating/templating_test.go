@@ -0,0 +1,64 @@
+package templating
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    bool
+	}{
+		{template: "plain text", valid: true},
+		{template: "{{ .Method }} {{ .Path }}", valid: true},
+		{template: "{{ if .Query.page }}{{ .Query.page }}{{ else }}1{{ end }}", valid: true},
+		{template: "{{ .Method"},
+		{template: "{{ if .Query.page }}"},
+		{template: "{{ end }}"},
+		{template: "{{ unknownHelper }}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			if _, err := Parse(tt.template); (err == nil) != tt.valid {
+				t.Fatalf("Parse = %v, want valid=%v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestRenderRequestData(t *testing.T) {
+	data := &Data{
+		Uid:       "ns",
+		Method:    "POST",
+		Path:      "/orders",
+		Cookies:   map[string]string{"session": "abc"},
+		Form:      map[string]string{"qty": "2"},
+		Body:      map[string]interface{}{"user": map[string]interface{}{"name": "ann"}},
+		RawBody:   `{"user":{"name":"ann"}}`,
+		RequestId: "req-1",
+	}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{{ .Uid }} {{ .Method }} {{ .Path }}", "ns POST /orders"},
+		{"{{ .Cookies.session }}", "abc"},
+		{"{{ .Form.qty }}", "2"},
+		{"{{ .Body.user.name }}", "ann"},
+		{"{{ .RawBody }}", `{"user":{"name":"ann"}}`},
+		{"{{ .RequestId }}", "req-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := Render(tt.template, data)
+			if err != nil || got != tt.want {
+				t.Fatalf("Render = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderExecutionError(t *testing.T) {
+	// 解析成功但执行失败，例如对字符串取字段
+	if _, err := Render("{{ .Path.Name }}", &Data{Path: "/a"}); err == nil {
+		t.Fatalf("Render should fail when the template cannot be executed")
+	}
+}
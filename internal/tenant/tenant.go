@@ -0,0 +1,30 @@
+// Package tenant 在 context 中传递当前请求所属的工作区。
+// 仓储从 context 中读取工作区，读操作只返回该工作区的记录，写操作将记录归属到该工作区；
+// context 中没有工作区时（mock 请求入口、命令行、未启用工作区）不做限定
+package tenant
+
+import "context"
+
+type contextKey struct{}
+
+// WithWorkspace 返回限定在工作区内的 context
+func WithWorkspace(ctx context.Context, workspaceId string) context.Context {
+	return context.WithValue(ctx, contextKey{}, workspaceId)
+}
+
+// Workspace 返回 context 所限定的工作区
+func Workspace(ctx context.Context) (string, bool) {
+	workspaceId, ok := ctx.Value(contextKey{}).(string)
+	return workspaceId, ok && workspaceId != ""
+}
+
+// Unscoped 返回不限定工作区的 context，用于检查命名空间归属等需要跨工作区读取的场景
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, "")
+}
+
+// Contains 记录是否在 context 所限定的工作区内，未限定时全部可见
+func Contains(ctx context.Context, workspaceId string) bool {
+	current, ok := Workspace(ctx)
+	return !ok || current == workspaceId
+}
@@ -0,0 +1,78 @@
+package mockserver
+
+import (
+	"fmt"
+	"kite/internal/matching"
+)
+
+// Condition 请求需要满足的条件，注册 mock 和校验调用时使用，需要通过 Query、Header 等函数创建
+type Condition struct {
+	apply func(matchers *matching.RequestMatchers)
+}
+
+// Query 查询参数等于 value
+func Query(name string, value string) Condition {
+	return field(func(m *matching.RequestMatchers, f matching.FieldMatcher) { m.Query = append(m.Query, f) }, name, matching.OperatorEquals, value)
+}
+
+// Header 请求头等于 value
+func Header(name string, value string) Condition {
+	return field(func(m *matching.RequestMatchers, f matching.FieldMatcher) { m.Headers = append(m.Headers, f) }, name, matching.OperatorEquals, value)
+}
+
+// HeaderContains 请求头包含 value
+func HeaderContains(name string, value string) Condition {
+	return field(func(m *matching.RequestMatchers, f matching.FieldMatcher) { m.Headers = append(m.Headers, f) }, name, matching.OperatorContains, value)
+}
+
+// Cookie Cookie 等于 value
+func Cookie(name string, value string) Condition {
+	return field(func(m *matching.RequestMatchers, f matching.FieldMatcher) { m.Cookies = append(m.Cookies, f) }, name, matching.OperatorEquals, value)
+}
+
+// BodyContains 请求体包含 text
+func BodyContains(text string) Condition {
+	return body(matching.BodyMatcher{Type: matching.BodyTypeText, Operator: matching.OperatorContains, Value: text})
+}
+
+// BodyJSON 请求体与 document 是相同的 JSON，忽略格式和字段顺序
+func BodyJSON(document string) Condition {
+	return body(matching.BodyMatcher{Type: matching.BodyTypeJSONEquals, Value: document})
+}
+
+// BodyJSONPath JSON 请求体中 expression 选中的值等于 value，例如 BodyJSONPath("$.sku", "123")
+func BodyJSONPath(expression string, value string) Condition {
+	return body(matching.BodyMatcher{Type: matching.BodyTypeJSONPath, Expression: expression, Operator: matching.OperatorEquals, Value: value})
+}
+
+// FormField 表单字段等于 value
+func FormField(name string, value string) Condition {
+	return body(matching.BodyMatcher{Type: matching.BodyTypeForm, Expression: name, Operator: matching.OperatorEquals, Value: value})
+}
+
+func field(add func(*matching.RequestMatchers, matching.FieldMatcher), name string, operator string, value string) Condition {
+	return Condition{apply: func(m *matching.RequestMatchers) {
+		add(m, matching.FieldMatcher{Name: name, Operator: operator, Value: value})
+	}}
+}
+
+func body(matcher matching.BodyMatcher) Condition {
+	return Condition{apply: func(m *matching.RequestMatchers) {
+		m.Body = append(m.Body, matcher)
+	}}
+}
+
+// buildMatchers 没有条件时返回 nil，条件不是通过本包的函数创建时返回错误
+func buildMatchers(conditions []Condition) (*matching.RequestMatchers, error) {
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+	matchers := &matching.RequestMatchers{}
+	for i, condition := range conditions {
+		if condition.apply == nil {
+			return nil, fmt.Errorf("condition %d is empty, create conditions with functions such as mockserver.Query", i+1)
+		}
+		condition.apply(matchers)
+	}
+	return matchers, nil
+}
@@ -0,0 +1,121 @@
+package mockserver
+
+import (
+	"kite/internal/api/payloads"
+	"kite/internal/simulation"
+	"time"
+)
+
+// MockBuilder 以链式调用定义 mock，调用 Register 或 MustRegister 后生效。
+// 默认返回 200、text/plain、空响应体
+type MockBuilder struct {
+	namespace *Namespace
+	payload   payloads.MockApiPayload
+	// err 定义过程中的错误，在 Register 时返回
+	err error
+}
+
+func newMockBuilder(namespace *Namespace, method string, path string, conditions []Condition) *MockBuilder {
+	matchers, err := buildMatchers(conditions)
+	return &MockBuilder{
+		namespace: namespace,
+		err:       err,
+		payload: payloads.MockApiPayload{
+			UserId:          namespace.uid,
+			Path:            path,
+			Method:          method,
+			StatusCode:      200,
+			ContentType:     "text/plain",
+			Charset:         "utf-8",
+			ResponseHeaders: []payloads.Headers{},
+			RequestMatchers: matchers,
+		},
+	}
+}
+
+// Status 设置响应状态码
+func (b *MockBuilder) Status(code int) *MockBuilder {
+	b.payload.StatusCode = int16(code)
+	return b
+}
+
+// Header 添加响应头
+func (b *MockBuilder) Header(key string, value string) *MockBuilder {
+	b.payload.ResponseHeaders = append(b.payload.ResponseHeaders, payloads.Headers{Key: key, Value: value})
+	return b
+}
+
+// ContentType 设置响应的 Content-Type，不含字符集
+func (b *MockBuilder) ContentType(contentType string) *MockBuilder {
+	b.payload.ContentType = contentType
+	return b
+}
+
+// Body 设置响应体
+func (b *MockBuilder) Body(body string) *MockBuilder {
+	b.payload.ResponseBody = body
+	return b
+}
+
+// JSON 设置 JSON 响应体，Content-Type 设置为 application/json
+func (b *MockBuilder) JSON(document string) *MockBuilder {
+	b.payload.ContentType = "application/json"
+	b.payload.ResponseBody = document
+	return b
+}
+
+// Template 将响应体作为模板渲染，可以引用路径参数、查询参数和请求体
+func (b *MockBuilder) Template() *MockBuilder {
+	b.payload.Template = true
+	return b
+}
+
+// Priority 设置优先级，多个 mock 都能匹配时优先级高的生效
+func (b *MockBuilder) Priority(priority int) *MockBuilder {
+	b.payload.Priority = priority
+	return b
+}
+
+// Delay 固定延迟后再返回响应
+func (b *MockBuilder) Delay(delay time.Duration) *MockBuilder {
+	b.payload.Delay = &simulation.Delay{Distribution: simulation.DelayFixed, FixedMs: int(delay.Milliseconds())}
+	return b
+}
+
+// Scenario 设置场景，requiredState 为空时任何状态都能匹配，newState 为空时不改变状态
+func (b *MockBuilder) Scenario(name string, requiredState string, newState string) *MockBuilder {
+	b.payload.ScenarioName = name
+	b.payload.RequiredState = requiredState
+	b.payload.NewState = newState
+	return b
+}
+
+// Register 按管理接口的规则校验后保存 mock 并返回其 uuid
+func (b *MockBuilder) Register() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	// 管理接口要求 response_body 非空，构造器允许空响应体（例如 202、204），校验时用占位内容代替
+	payload := b.payload
+	if payload.ResponseBody == "" {
+		payload.ResponseBody = " "
+	}
+	server := b.namespace.server
+	if err := server.echo.Validator.Validate(&payload); err != nil {
+		return "", err
+	}
+	return server.apis.Create(server.newContext(), b.payload)
+}
+
+// MustRegister 保存 mock 并返回其 uuid，失败时终止测试（通过 New 创建的服务则 panic）
+func (b *MockBuilder) MustRegister() string {
+	uuid, err := b.Register()
+	if err != nil {
+		server := b.namespace.server
+		if server.tb != nil {
+			server.tb.Helper()
+		}
+		server.fail("mockserver: failed to register %s %s: %v", b.payload.Method, b.payload.Path, err)
+	}
+	return uuid
+}
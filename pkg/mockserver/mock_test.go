@@ -0,0 +1,62 @@
+package mockserver
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMockBuilderRegister(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+	tests := []struct {
+		name  string
+		mock  *MockBuilder
+		valid bool
+	}{
+		{name: "defaults with an empty body", mock: srv.Mock(http.MethodGet, "/empty"), valid: true},
+		{name: "conditions and body", mock: srv.Mock(http.MethodGet, "/items", Query("page", "1")).JSON(`[]`), valid: true},
+		{name: "status code out of range", mock: srv.Mock(http.MethodGet, "/items").Status(42)},
+		{name: "missing path", mock: srv.Mock(http.MethodGet, "")},
+		{name: "negative delay", mock: srv.Mock(http.MethodGet, "/slow").Delay(-time.Second)},
+		{name: "zero value condition", mock: srv.Mock(http.MethodGet, "/items", Header("X-Tier", "vip"), Condition{})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uuid, err := tt.mock.Register()
+			if (err == nil) != tt.valid {
+				t.Fatalf("Register = %q, %v, want valid=%v", uuid, err, tt.valid)
+			}
+		})
+	}
+
+	resp, err := http.Get(srv.MockURL() + "/empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || len(body) != 0 {
+		t.Fatalf("GET /empty = %d %q, want 200 with an empty body", resp.StatusCode, body)
+	}
+}
+
+func TestVerificationCheckRejectsInvalidDefinition(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+	tests := []struct {
+		name         string
+		verification *Verification
+	}{
+		{name: "zero value condition", verification: srv.Verify(http.MethodGet, "/items", Condition{})},
+		{name: "missing path", verification: srv.Verify(http.MethodGet, "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result, err := tt.verification.Check(); err == nil {
+				t.Fatalf("Check = %+v, want an error", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package mockserver 在进程内启动完整的 mock 服务，供 go test 使用。
+// 服务使用内存存储，不依赖 MySQL 和 Wire，每个 Server 的数据互相隔离，可以在并行测试中各自创建：
+//
+//	srv := mockserver.Start(t)
+//	srv.Mock(http.MethodGet, "/users/{id}").Status(200).JSON(`{"id":1}`).MustRegister()
+//	resp, _ := http.Get(srv.MockURL() + "/users/1")
+//	srv.Verify(http.MethodGet, "/users/{id}").Times(1).Assert(t)
+package mockserver
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"kite/internal/api/handlers"
+	authHandler "kite/internal/api/handlers/auth"
+	"kite/internal/api/handlers/bundle"
+	"kite/internal/api/handlers/importer"
+	journalHandler "kite/internal/api/handlers/journal"
+	"kite/internal/api/handlers/mock"
+	"kite/internal/api/handlers/namespace"
+	routingHandler "kite/internal/api/handlers/routing"
+	"kite/internal/api/handlers/scenario"
+	workspaceHandler "kite/internal/api/handlers/workspace"
+	"kite/internal/api/routes"
+	"kite/internal/api/validators"
+	"kite/internal/configs"
+	"kite/internal/journal"
+	"kite/internal/proxy"
+	"kite/internal/repositories"
+	"kite/internal/routing"
+	"kite/internal/services"
+	"kite/internal/state"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// DefaultNamespace Server 上直接注册的 mock 所在的命名空间
+const DefaultNamespace = "default"
+
+// journalCapacity 请求日志保留的记录数
+const journalCapacity = 10000
+
+// Server 运行在本地随机端口上的 mock 服务
+type Server struct {
+	// URL 服务的根地址，例如 http://127.0.0.1:54321，管理接口位于 URL + "/api/v1"
+	URL string
+
+	httpServer *httptest.Server
+	echo       *echo.Echo
+	apis       services.ApiService
+	journal    services.JournalService
+	// tb 通过 Start 创建时用于在注册或校验失败时终止测试
+	tb testing.TB
+}
+
+// New 创建并启动服务，使用完毕后需要调用 Close
+func New() *Server {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.HTTPErrorHandler = handlers.CustomHTTPErrorHandler
+	e.Validator = validators.NewCustomValidator()
+	e.Use(middleware.RequestID())
+	e.Use(middleware.Recover())
+
+	// 单个进程内只有这一个写入方，路由表不需要定期刷新
+	apiRepository := routing.NewTable(repositories.NewMemoryApiRepository(), &configs.RoutingConfig{Enabled: true})
+	store := state.NewMemoryStore()
+	namespaceRepository := routing.NewNamespaceCache(repositories.NewMemoryNamespaceRepository(), apiRepository)
+	namespaceService := services.NewNamespaceService(namespaceRepository)
+	// 不创建工作区，请求不携带 API Key 时不限定工作区
+	workspaceService := services.NewWorkspaceService(repositories.NewMemoryWorkspaceRepository(), apiRepository, namespaceRepository)
+	apiService := services.NewApiService(apiRepository, apiRepository, namespaceService, workspaceService, store)
+	journalService := services.NewJournalService(journal.NewMemorySink(journalCapacity), &configs.JournalConfig{})
+	// 测试进程内的服务不开启认证，未开启时不会加载任何密钥，不会返回错误
+	authConfig := &configs.AuthConfig{}
+	authService, _ := services.NewAuthService(authConfig, workspaceService)
+	routes.RegisterRoutes(
+		e,
+		mock.NewApiHandler(apiService, namespaceService, services.NewProxyService(apiRepository, proxy.NewForwarder(&configs.ProxyConfig{}))),
+		namespace.NewNamespaceHandler(namespaceService),
+		scenario.NewScenarioHandler(services.NewScenarioService(apiRepository, store)),
+		importer.NewImportHandler(services.NewImportService(apiRepository)),
+		bundle.NewBundleHandler(services.NewBundleService(apiRepository, workspaceService)),
+		journalHandler.NewJournalHandler(journalService),
+		routingHandler.NewRoutingHandler(services.NewRoutingService(apiRepository)),
+		workspaceHandler.NewWorkspaceHandler(workspaceService, &configs.WorkspaceConfig{}),
+		authHandler.NewAuthHandler(authService, authConfig),
+	)
+
+	httpServer := httptest.NewServer(e)
+	return &Server{
+		URL:        httpServer.URL,
+		httpServer: httpServer,
+		echo:       e,
+		apis:       apiService,
+		journal:    journalService,
+	}
+}
+
+// Start 创建并启动服务，测试结束时自动关闭；注册 mock 或校验失败时直接让测试失败
+func Start(tb testing.TB) *Server {
+	tb.Helper()
+	s := New()
+	s.tb = tb
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Close 关闭服务
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// Handler 返回服务的 http.Handler，可以不经过网络直接调用
+func (s *Server) Handler() http.Handler {
+	return s.echo
+}
+
+// Namespace 返回命名空间（即 mock 地址中的 uid）的操作入口
+func (s *Server) Namespace(uid string) *Namespace {
+	return &Namespace{server: s, uid: uid}
+}
+
+// MockURL 默认命名空间下 mock 的根地址
+func (s *Server) MockURL() string {
+	return s.Namespace(DefaultNamespace).URL()
+}
+
+// Mock 在默认命名空间下开始定义一个 mock
+func (s *Server) Mock(method string, path string, conditions ...Condition) *MockBuilder {
+	return s.Namespace(DefaultNamespace).Mock(method, path, conditions...)
+}
+
+// Verify 在默认命名空间下开始定义一个调用校验
+func (s *Server) Verify(method string, path string, conditions ...Condition) *Verification {
+	return s.Namespace(DefaultNamespace).Verify(method, path, conditions...)
+}
+
+// newContext 服务层以 echo.Context 作为参数，直接调用时构造一个不对应真实请求的上下文
+func (s *Server) newContext() echo.Context {
+	return s.echo.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+}
+
+// fail 通过 Start 创建时终止测试，否则 panic
+func (s *Server) fail(format string, args ...interface{}) {
+	if s.tb != nil {
+		s.tb.Helper()
+		s.tb.Fatalf(format, args...)
+		return
+	}
+	panic(fmt.Sprintf(format, args...))
+}
+
+// Namespace 某个命名空间下的 mock 和调用校验
+type Namespace struct {
+	server *Server
+	uid    string
+}
+
+// URL 命名空间下 mock 的根地址，mock 的路径拼接在其后
+func (n *Namespace) URL() string {
+	return n.server.URL + "/api/v1/mock/" + n.uid
+}
+
+// Mock 开始定义一个 mock，conditions 为请求需要额外满足的条件
+func (n *Namespace) Mock(method string, path string, conditions ...Condition) *MockBuilder {
+	return newMockBuilder(n, method, path, conditions)
+}
+
+// Verify 开始定义一个调用校验，conditions 为请求需要满足的条件
+func (n *Namespace) Verify(method string, path string, conditions ...Condition) *Verification {
+	return newVerification(n, method, path, conditions)
+}
+
+// Reset 清空命名空间下的请求日志
+func (n *Namespace) Reset() error {
+	return n.server.journal.Clear(n.server.newContext(), n.uid)
+}
@@ -0,0 +1,132 @@
+package mockserver
+
+import (
+	"fmt"
+	"kite/internal/api/payloads"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Verification 以链式调用定义调用校验，未指定次数时要求至少调用一次
+type Verification struct {
+	namespace *Namespace
+	payload   payloads.VerificationPayload
+	// err 定义过程中的错误，在 Check 时返回
+	err error
+}
+
+// Result 校验结果
+type Result struct {
+	Passed   bool
+	Expected string
+	Actual   int
+	// NearMisses 校验失败时与条件最接近但没有匹配上的请求
+	NearMisses []NearMiss
+}
+
+// NearMiss 没有匹配上的请求及原因
+type NearMiss struct {
+	Method string
+	Path   string
+	Reason string
+	Detail string
+}
+
+func newVerification(namespace *Namespace, method string, path string, conditions []Condition) *Verification {
+	matchers, err := buildMatchers(conditions)
+	return &Verification{
+		namespace: namespace,
+		payload: payloads.VerificationPayload{
+			Method:   method,
+			Path:     path,
+			Matchers: matchers,
+		},
+		err: err,
+	}
+}
+
+// Times 恰好调用 n 次
+func (v *Verification) Times(n int) *Verification {
+	return v.count(payloads.CountExactly, n)
+}
+
+// AtLeast 至少调用 n 次
+func (v *Verification) AtLeast(n int) *Verification {
+	return v.count(payloads.CountAtLeast, n)
+}
+
+// AtMost 至多调用 n 次
+func (v *Verification) AtMost(n int) *Verification {
+	return v.count(payloads.CountAtMost, n)
+}
+
+// Never 没有被调用
+func (v *Verification) Never() *Verification {
+	return v.count(payloads.CountNever, 0)
+}
+
+// Since 只统计 t 之后的请求
+func (v *Verification) Since(t time.Time) *Verification {
+	v.payload.Since = t
+	return v
+}
+
+func (v *Verification) count(kind string, n int) *Verification {
+	v.payload.Count = &payloads.CountConstraint{Type: kind, Value: n}
+	return v
+}
+
+// Check 执行校验并返回结果
+func (v *Verification) Check() (*Result, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	server := v.namespace.server
+	if err := server.echo.Validator.Validate(&v.payload); err != nil {
+		return nil, err
+	}
+	resp, err := server.journal.Verify(server.newContext(), v.namespace.uid, v.payload)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Passed: resp.Passed, Expected: resp.Expected, Actual: resp.Actual}
+	for _, miss := range resp.NearMisses {
+		result.NearMisses = append(result.NearMisses, NearMiss{
+			Method: miss.Request.Method,
+			Path:   miss.Request.Path,
+			Reason: miss.Reason,
+			Detail: miss.Detail,
+		})
+	}
+	return result, nil
+}
+
+// Assert 执行校验，失败时将期望、实际次数和最接近的请求报告为测试错误，返回是否通过
+func (v *Verification) Assert(tb testing.TB) bool {
+	tb.Helper()
+	result, err := v.Check()
+	if err != nil {
+		tb.Errorf("mockserver: failed to verify %s %s: %v", v.payload.Method, v.payload.Path, err)
+		return false
+	}
+	if !result.Passed {
+		tb.Error(result.describe(v.payload.Method, v.payload.Path))
+	}
+	return result.Passed
+}
+
+func (r *Result) describe(method string, path string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "mockserver: expected %s %s to be called %s, but it was called %d times", method, path, r.Expected, r.Actual)
+	if len(r.NearMisses) > 0 {
+		b.WriteString("\nclosest requests:")
+		for _, miss := range r.NearMisses {
+			fmt.Fprintf(&b, "\n  %s %s: %s", miss.Method, miss.Path, miss.Reason)
+			if miss.Detail != "" {
+				fmt.Fprintf(&b, " (%s)", miss.Detail)
+			}
+		}
+	}
+	return b.String()
+}
@@ -33,6 +33,11 @@ const (
 
 type RetryPolicy func(resp *http.Response, err error) bool
 
+// NeverRetry 从不重试，用于不能确定是否幂等的请求
+func NeverRetry(resp *http.Response, err error) bool {
+	return false
+}
+
 // DefaultRetryPolicy 默认重试策略
 func DefaultRetryPolicy(resp *http.Response, err error) bool {
 	if err != nil {
@@ -49,7 +54,7 @@ func DefaultRetryPolicy(resp *http.Response, err error) bool {
 }
 
 type RetryConfig struct {
-	MaxRetries      int             // 最大重试次数
+	MaxRetries      int             // 最大重试次数，0 使用默认值，小于 0 表示不重试
 	InitialDelay    time.Duration   // 初始延迟
 	MaxDelay        time.Duration   // 最大延迟
 	BackoffStrategy BackoffStrategy // 退避策略
@@ -63,6 +68,7 @@ type ClientConfig struct {
 	RetryConfig       RetryConfig   // 重试配置
 	EnableCompression bool          // 是否启用压缩
 	DisableKeepAlives bool          // 是否禁用长连接
+	DisableRedirects  bool          // 是否不跟随重定向，直接返回 3xx 响应
 }
 
 func DefaultConfig() ClientConfig {
@@ -98,6 +104,9 @@ func New(config ClientConfig) *Client {
 	if config.RetryConfig.MaxRetries == 0 {
 		config.RetryConfig.MaxRetries = defaultCfg.RetryConfig.MaxRetries
 	}
+	if config.RetryConfig.MaxRetries < 0 {
+		config.RetryConfig.MaxRetries = 0
+	}
 	if config.RetryConfig.InitialDelay == 0 {
 		config.RetryConfig.InitialDelay = defaultCfg.RetryConfig.InitialDelay
 	}
@@ -129,6 +138,11 @@ func New(config ClientConfig) *Client {
 		Transport: transport,
 		Timeout:   config.Timeout,
 	}
+	if config.DisableRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
 	// 初始化随机数生成器
 	source := rand.NewSource(time.Now().UnixNano())
 
@@ -224,6 +238,11 @@ func (c *Client) Request(method, url string, body interface{}, headers map[strin
 	return c.doWithRetry(req)
 }
 
+// Do 发送已经构建好的请求，不补充任何请求头，按重试配置重试
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.doWithRetry(req)
+}
+
 func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
 	var resp *http.Response
 	var err error
@@ -272,13 +291,7 @@ func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
 			// 请求成功不需要重试
 			break
 		}
-		if resp != nil {
-			err := resp.Body.Close()
-			if err != nil {
-				return nil, err
-			}
-		}
-		// 如果达到最大重试次数，则返回错误
+		// 如果达到最大重试次数，则返回错误，或者原样返回最后一次的响应
 		if attempt == maxRetries {
 			if err != nil {
 				logger.Error("HTTP request failed after retries",
@@ -287,7 +300,7 @@ func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
 					zap.Int("attempt", attempt+1),
 					zap.Error(err),
 				)
-				return nil, fmt.Errorf("%w: %v", ErrMaxRetriesReached, err)
+				return nil, fmt.Errorf("%w: %w", ErrMaxRetriesReached, err)
 			}
 			if resp != nil {
 				logger.Error("HTTP request failed after retries",
@@ -306,6 +319,12 @@ func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
 			}
 			return resp, nil
 		}
+		if resp != nil {
+			err := resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 	// 记录请求结果
 	if err != nil {
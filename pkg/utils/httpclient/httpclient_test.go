@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	retry := RetryConfig{MaxRetries: 2, InitialDelay: time.Millisecond, BackoffStrategy: BackoffConstant}
+	tests := []struct {
+		name       string
+		config     ClientConfig
+		path       string
+		wantStatus int
+		wantCalls  int32
+		wantBody   string
+	}{
+		{name: "follows redirects by default", config: ClientConfig{RetryConfig: retry}, path: "/redirect", wantStatus: http.StatusOK, wantCalls: 2, wantBody: "ok"},
+		{name: "redirects disabled", config: ClientConfig{RetryConfig: retry, DisableRedirects: true}, path: "/redirect", wantStatus: http.StatusFound, wantCalls: 1},
+		{name: "retries server errors", config: ClientConfig{RetryConfig: retry}, path: "/fail", wantStatus: http.StatusServiceUnavailable, wantCalls: 3, wantBody: "unavailable"},
+		{
+			name:       "negative max retries",
+			config:     ClientConfig{RetryConfig: RetryConfig{MaxRetries: -1}},
+			path:       "/fail",
+			wantStatus: http.StatusServiceUnavailable,
+			wantCalls:  1,
+			wantBody:   "unavailable",
+		},
+		{
+			name:       "never retry policy",
+			config:     ClientConfig{RetryConfig: RetryConfig{MaxRetries: 2, RetryPolicy: NeverRetry}},
+			path:       "/fail",
+			wantStatus: http.StatusServiceUnavailable,
+			wantCalls:  1,
+			wantBody:   "unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				switch r.URL.Path {
+				case "/redirect":
+					w.Header().Set("Location", "/ok")
+					w.WriteHeader(http.StatusFound)
+				case "/fail":
+					w.WriteHeader(http.StatusServiceUnavailable)
+					_, _ = w.Write([]byte("unavailable"))
+				default:
+					_, _ = w.Write([]byte("ok"))
+				}
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := New(tt.config).Do(req)
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("read body: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus || calls.Load() != tt.wantCalls || string(body) != tt.wantBody {
+				t.Fatalf("status %d, %d calls, body %q, want %d, %d calls, body %q",
+					resp.StatusCode, calls.Load(), body, tt.wantStatus, tt.wantCalls, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// ApiKeyPrefix 生成的 API Key 的固定前缀，便于在日志或代码仓库中识别泄露的 Key
+const ApiKeyPrefix = "kite_"
+
+// GenerateApiKey 生成随机的 API Key
+func GenerateApiKey() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return ApiKeyPrefix + hex.EncodeToString(secret), nil
+}
+
+// HashApiKey 返回 API Key 的 SHA-256 摘要，存储与查找都使用摘要
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}